@@ -0,0 +1,7 @@
+// Package inventoryv1 holds the protobuf messages and gRPC stubs generated
+// from inventory.proto. The generated files are committed, so building does
+// not need protoc. Regenerate them with go generate after editing the .proto
+// file; protoc, protoc-gen-go and protoc-gen-go-grpc must be on PATH.
+package inventoryv1
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative inventory.proto
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// Config holds the settings the server reads from the environment at start up.
+type Config struct {
+	Addr               string
+	GRPCAddr           string
+	ShutdownTimeout    time.Duration
+	MaxBodyBytes       int64
+	ValidateResponses  bool
+	Database           *mysql.Config
+	LogLevel           string
+	SlowQueryThreshold time.Duration
+	ServiceName        string
+	// TraceExporter is one of none, stdout or otlp.
+	TraceExporter string
+	OTLPEndpoint  string
+	// ReservationSweepInterval is how often expired reservations are
+	// marked as such.
+	ReservationSweepInterval time.Duration
+	// PriceScheduleInterval is how often scheduled price changes that came
+	// due are applied.
+	PriceScheduleInterval time.Duration
+	// ReorderNotifier is one of log, webhook or smtp.
+	ReorderNotifier   string
+	ReorderWebhookURL string
+	SMTPAddr          string
+	SMTPFrom          string
+	SMTPTo            []string
+	// BaseCurrency is the ISO-4217 currency exchange rates are quoted
+	// against and the default currency of prices.
+	BaseCurrency string
+	// ExchangeRatesFile, when set, is a CSV of currency,rate pairs loaded at
+	// start up and again every ExchangeRatesReloadInterval.
+	ExchangeRatesFile           string
+	ExchangeRatesReloadInterval time.Duration
+	// DefaultTenant is the tenant of requests that name none. When it is
+	// empty every request must name its tenant.
+	DefaultTenant string
+	// CacheTTL is how long product and warehouse reads are cached in
+	// memory; zero disables the cache. CacheSize bounds its entries.
+	CacheTTL  time.Duration
+	CacheSize int
+	// HTTPCacheMaxAge is how long clients may reuse a product or warehouse
+	// report before revalidating it with its ETag.
+	HTTPCacheMaxAge time.Duration
+	// RateLimit is the default limit of each client per route group of the
+	// API, such as 600/1m, or off. RateLimits overrides it per group with
+	// group=limit entries, such as products=100/1m.
+	RateLimit  string
+	RateLimits []string
+	// IdempotencyTTL is how long the response of a POST sent with an
+	// Idempotency-Key is replayed to its retries. Expired keys are dropped
+	// every IdempotencySweepInterval.
+	IdempotencyTTL           time.Duration
+	IdempotencySweepInterval time.Duration
+	// SearchIndex is one of bleve or mysql. A bleve index lives in memory
+	// and is rebuilt from the database at start up and every
+	// SearchReindexInterval, which bounds how long it misses the writes of
+	// other instances.
+	SearchIndex           string
+	SearchReindexInterval time.Duration
+	// LabelTemplatesFile, when set, is a JSON array of label templates
+	// added to the built in ones.
+	LabelTemplatesFile string
+}
+
+// Load builds the Config from environment variables, falling back to the
+// defaults used for local development.
+func Load() Config {
+	return Config{
+		Addr:              getEnv("SERVER_ADDR", ":8080"),
+		GRPCAddr:          getEnv("GRPC_ADDR", ":9090"),
+		ShutdownTimeout:   getDuration("SHUTDOWN_TIMEOUT", 15*time.Second),
+		MaxBodyBytes:      int64(getInt("MAX_BODY_BYTES", 1<<20)),
+		ValidateResponses: getBool("VALIDATE_RESPONSES", false),
+		Database: &mysql.Config{
+			User:                 getEnv("DB_USER", "root"),
+			Passwd:               getEnv("DB_PASSWORD", ""),
+			Net:                  "tcp",
+			Addr:                 getEnv("DB_ADDR", "localhost:3306"),
+			DBName:               getEnv("DB_NAME", "my_db"),
+			ParseTime:            true,
+			AllowNativePasswords: true,
+		},
+		LogLevel:           getEnv("LOG_LEVEL", "info"),
+		SlowQueryThreshold: getDuration("SLOW_QUERY_THRESHOLD", 200*time.Millisecond),
+		ServiceName:        getEnv("SERVICE_NAME", "inventory"),
+		TraceExporter:      getEnv("TRACE_EXPORTER", "none"),
+		OTLPEndpoint:       getEnv("OTLP_ENDPOINT", "localhost:4317"),
+
+		ReservationSweepInterval: getDuration("RESERVATION_SWEEP_INTERVAL", 30*time.Second),
+		PriceScheduleInterval:    getDuration("PRICE_SCHEDULE_INTERVAL", time.Minute),
+
+		ReorderNotifier:   getEnv("REORDER_NOTIFIER", "log"),
+		ReorderWebhookURL: getEnv("REORDER_WEBHOOK_URL", ""),
+		SMTPAddr:          getEnv("SMTP_ADDR", "localhost:1025"),
+		SMTPFrom:          getEnv("SMTP_FROM", "inventory@localhost"),
+		SMTPTo:            getList("SMTP_TO"),
+
+		BaseCurrency:                getEnv("BASE_CURRENCY", "USD"),
+		ExchangeRatesFile:           getEnv("EXCHANGE_RATES_FILE", ""),
+		ExchangeRatesReloadInterval: getDuration("EXCHANGE_RATES_RELOAD_INTERVAL", time.Hour),
+
+		DefaultTenant: getEnv("DEFAULT_TENANT", "default"),
+
+		CacheTTL:        getDuration("CACHE_TTL", 30*time.Second),
+		CacheSize:       getInt("CACHE_SIZE", 10000),
+		HTTPCacheMaxAge: getDuration("HTTP_CACHE_MAX_AGE", 0),
+
+		RateLimit:  getEnv("RATE_LIMIT", "600/1m"),
+		RateLimits: getList("RATE_LIMITS"),
+
+		IdempotencyTTL:           getDuration("IDEMPOTENCY_TTL", 24*time.Hour),
+		IdempotencySweepInterval: getDuration("IDEMPOTENCY_SWEEP_INTERVAL", 10*time.Minute),
+
+		SearchIndex:           getEnv("SEARCH_INDEX", "bleve"),
+		SearchReindexInterval: getDuration("SEARCH_REINDEX_INTERVAL", 10*time.Minute),
+
+		LabelTemplatesFile: getEnv("LABEL_TEMPLATES_FILE", ""),
+	}
+}
+
+func getEnv(key, fallback string) string {
+	if v, ok := os.LookupEnv(key); ok && v != "" {
+		return v
+	}
+	return fallback
+}
+
+func getDuration(key string, fallback time.Duration) time.Duration {
+	d, err := time.ParseDuration(getEnv(key, ""))
+	if err != nil {
+		return fallback
+	}
+	return d
+}
+
+func getInt(key string, fallback int) int {
+	n, err := strconv.Atoi(getEnv(key, ""))
+	if err != nil {
+		return fallback
+	}
+	return n
+}
+
+func getBool(key string, fallback bool) bool {
+	b, err := strconv.ParseBool(getEnv(key, ""))
+	if err != nil {
+		return fallback
+	}
+	return b
+}
+
+// getList splits a comma separated variable, dropping empty items.
+func getList(key string) []string {
+	var list []string
+	for _, v := range strings.Split(getEnv(key, ""), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
@@ -0,0 +1,574 @@
+package docs
+
+import (
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// schemas lists the component schemas by name. Every object schema rejects
+// unknown properties so the document describes the payloads exactly.
+func schemas() openapi3.Schemas {
+	return openapi3.Schemas{
+		"Error":                openapi3.NewSchemaRef("", errorSchema()),
+		"Product":              openapi3.NewSchemaRef("", productSchema()),
+		"ProductInput":         openapi3.NewSchemaRef("", productInputSchema()),
+		"ProductMatch":         openapi3.NewSchemaRef("", productMatchSchema()),
+		"ProductWithWarehouse": openapi3.NewSchemaRef("", productWithWarehouseSchema()),
+		"ProductBatch":         openapi3.NewSchemaRef("", productBatchSchema()),
+		"BatchResult":          openapi3.NewSchemaRef("", batchResultSchema()),
+		"Warehouse":            openapi3.NewSchemaRef("", warehouseSchema()),
+		"WarehouseInput":       openapi3.NewSchemaRef("", warehouseInputSchema()),
+		"WarehouseReport":      openapi3.NewSchemaRef("", warehouseReportSchema()),
+		"WarehouseProducts":    openapi3.NewSchemaRef("", warehouseProductsSchema()),
+		"Category":             openapi3.NewSchemaRef("", categorySchema()),
+		"CategoryInput":        openapi3.NewSchemaRef("", categoryInputSchema()),
+		"CategoryNode":         openapi3.NewSchemaRef("", categoryNodeSchema()),
+		"CategoryReport":       openapi3.NewSchemaRef("", categoryReportSchema()),
+		"Reservation":          openapi3.NewSchemaRef("", reservationSchema()),
+		"ReservationInput":     openapi3.NewSchemaRef("", reservationInputSchema()),
+		"StockAvailability":    openapi3.NewSchemaRef("", stockAvailabilitySchema()),
+		"ReorderPolicy":        openapi3.NewSchemaRef("", reorderPolicySchema()),
+		"LowStockItem":         openapi3.NewSchemaRef("", lowStockItemSchema()),
+		"Lot":                  openapi3.NewSchemaRef("", lotSchema()),
+		"LotInput":             openapi3.NewSchemaRef("", lotInputSchema()),
+		"LotIssue":             openapi3.NewSchemaRef("", lotIssueSchema()),
+		"LotAllocation":        openapi3.NewSchemaRef("", lotAllocationSchema()),
+		"ExpiringLot":          openapi3.NewSchemaRef("", expiringLotSchema()),
+		"LotMove":              openapi3.NewSchemaRef("", lotMoveSchema()),
+		"Location":             openapi3.NewSchemaRef("", locationSchema()),
+		"LocationInput":        openapi3.NewSchemaRef("", locationInputSchema()),
+		"LocationNode":         openapi3.NewSchemaRef("", locationNodeSchema()),
+		"PriceChange":          openapi3.NewSchemaRef("", priceChangeSchema()),
+		"PriceInput":           openapi3.NewSchemaRef("", priceInputSchema()),
+		"ProductValuation":     openapi3.NewSchemaRef("", productValuationSchema()),
+		"StockValuation":       openapi3.NewSchemaRef("", stockValuationSchema()),
+		"ExchangeRate":         openapi3.NewSchemaRef("", exchangeRateSchema()),
+		"ExchangeRateInput":    openapi3.NewSchemaRef("", exchangeRateInputSchema()),
+		"ProductCost":          openapi3.NewSchemaRef("", productCostSchema()),
+		"WarehouseCostReport":  openapi3.NewSchemaRef("", warehouseCostReportSchema()),
+		"LabelTemplate":        openapi3.NewSchemaRef("", labelTemplateSchema()),
+		"Health":               openapi3.NewSchemaRef("", healthSchema()),
+		"GraphQLRequest":       openapi3.NewSchemaRef("", graphQLRequestSchema()),
+		"GraphQLResult":        openapi3.NewSchemaRef("", graphQLResultSchema()),
+	}
+}
+
+// ref returns a reference to the named component schema that also carries
+// the resolved schema, so the document can validate without being reloaded.
+func ref(name string) *openapi3.SchemaRef {
+	return &openapi3.SchemaRef{Ref: "#/components/schemas/" + name, Value: schemas()[name].Value}
+}
+
+func closed(s *openapi3.Schema) *openapi3.Schema {
+	s.AdditionalProperties = openapi3.AdditionalProperties{Has: openapi3.BoolPtr(false)}
+	return s
+}
+
+// envelope wraps data in the {"data": ...} object written by web.Success.
+func envelope(data *openapi3.SchemaRef) *openapi3.SchemaRef {
+	s := openapi3.NewObjectSchema()
+	s.Properties = openapi3.Schemas{"data": data}
+	s.Required = []string{"data"}
+	return openapi3.NewSchemaRef("", closed(s))
+}
+
+func arrayOf(item *openapi3.SchemaRef) *openapi3.SchemaRef {
+	s := openapi3.NewArraySchema()
+	s.Items = item
+	return openapi3.NewSchemaRef("", s)
+}
+
+func errorSchema() *openapi3.Schema {
+	detail := openapi3.NewObjectSchema().
+		WithProperty("field", openapi3.NewStringSchema()).
+		WithProperty("message", openapi3.NewStringSchema())
+	detail.Required = []string{"message"}
+
+	s := openapi3.NewObjectSchema().
+		WithProperty("code", openapi3.NewStringSchema()).
+		WithProperty("message", openapi3.NewStringSchema()).
+		WithProperty("details", openapi3.NewArraySchema().WithItems(closed(detail)))
+	s.Required = []string{"code", "message"}
+	return closed(s)
+}
+
+// currencySchema is an ISO 4217 currency code.
+func currencySchema() *openapi3.Schema {
+	return openapi3.NewStringSchema().WithPattern("^[A-Z]{3}$")
+}
+
+func productProperties() openapi3.Schemas {
+	return openapi3.Schemas{
+		"name":         openapi3.NewStringSchema().NewRef(),
+		"quantity":     openapi3.NewIntegerSchema().NewRef(),
+		"code_value":   openapi3.NewStringSchema().NewRef(),
+		"is_published": openapi3.NewBoolSchema().NewRef(),
+		"expiration":   openapi3.NewDateTimeSchema().NewRef(),
+		"price":        openapi3.NewFloat64Schema().NewRef(),
+		"currency":     currencySchema().NewRef(),
+		"id_warehouse": openapi3.NewIntegerSchema().NewRef(),
+		"category_id":  openapi3.NewIntegerSchema().WithNullable().NewRef(),
+	}
+}
+
+func productSchema() *openapi3.Schema {
+	s := openapi3.NewObjectSchema()
+	s.Properties = productProperties()
+	s.Properties["id"] = openapi3.NewIntegerSchema().NewRef()
+	s.Required = []string{"id", "name", "quantity", "code_value", "is_published", "expiration", "price", "currency", "id_warehouse"}
+	return closed(s)
+}
+
+// productMatchSchema is a product found by a search, with its relevance.
+func productMatchSchema() *openapi3.Schema {
+	s := productSchema()
+	s.Properties["score"] = openapi3.NewFloat64Schema().NewRef()
+	s.Required = append(s.Required, "score")
+	return s
+}
+
+// productInputSchema is the body accepted on create and update. Fields left
+// out keep their zero value on create and their stored value on PATCH; a
+// missing currency defaults to the base currency.
+func productInputSchema() *openapi3.Schema {
+	s := openapi3.NewObjectSchema()
+	s.Properties = productProperties()
+	s.Properties["id"] = openapi3.NewIntegerSchema().NewRef()
+	quantity := openapi3.NewIntegerSchema().WithMin(0)
+	quantity.Description = "Only lowers the stock, writing units off; stock is received with its cost through POST /api/v1/products/{id}/lots"
+	s.Properties["quantity"] = quantity.NewRef()
+	return closed(s)
+}
+
+// productBatchSchema lists the operations of POST /products:batch. The body
+// of create and update is the one of the single request; delete takes none.
+func productBatchSchema() *openapi3.Schema {
+	op := openapi3.NewObjectSchema().
+		WithProperty("method", openapi3.NewStringSchema().WithEnum("create", "update", "delete")).
+		WithProperty("id", openapi3.NewIntegerSchema())
+	op.Properties["body"] = &openapi3.SchemaRef{Ref: "#/components/schemas/ProductInput", Value: productInputSchema()}
+	op.Required = []string{"method"}
+
+	ops := openapi3.NewArraySchema().WithItems(closed(op)).WithMinItems(1).WithMaxItems(100)
+	s := openapi3.NewObjectSchema().
+		WithProperty("atomic", openapi3.NewBoolSchema()).
+		WithProperty("operations", ops)
+	s.Required = []string{"operations"}
+	return closed(s)
+}
+
+// batchResultSchema is the outcome of one batch operation. Body is the body
+// the single request would have been answered with, a product envelope or an
+// Error, and is left out on 204.
+func batchResultSchema() *openapi3.Schema {
+	s := openapi3.NewObjectSchema().
+		WithProperty("status", openapi3.NewIntegerSchema()).
+		WithProperty("body", openapi3.NewObjectSchema())
+	s.Required = []string{"status"}
+	return closed(s)
+}
+
+func productWithWarehouseSchema() *openapi3.Schema {
+	s := openapi3.NewObjectSchema()
+	s.Properties = productProperties()
+	for name, p := range warehouseProperties() {
+		s.Properties[name] = p
+	}
+	// Product and Warehouse are embedded side by side and both declare id
+	// and name; encoding/json drops fields that conflict at the same depth.
+	delete(s.Properties, "name")
+	return closed(s)
+}
+
+func warehouseProperties() openapi3.Schemas {
+	return openapi3.Schemas{
+		"warehouse_code": openapi3.NewStringSchema().NewRef(),
+		"name":           openapi3.NewStringSchema().NewRef(),
+		"adress":         openapi3.NewStringSchema().NewRef(),
+		"telephone":      openapi3.NewStringSchema().NewRef(),
+		"capacity":       openapi3.NewIntegerSchema().NewRef(),
+	}
+}
+
+func warehouseSchema() *openapi3.Schema {
+	s := openapi3.NewObjectSchema()
+	s.Properties = warehouseProperties()
+	s.Properties["id"] = openapi3.NewIntegerSchema().NewRef()
+	s.Required = []string{"id", "warehouse_code", "name", "adress", "telephone", "capacity"}
+	return closed(s)
+}
+
+func warehouseInputSchema() *openapi3.Schema {
+	s := openapi3.NewObjectSchema()
+	s.Properties = warehouseProperties()
+	s.Properties["id"] = openapi3.NewIntegerSchema().NewRef()
+	return closed(s)
+}
+
+func warehouseReportSchema() *openapi3.Schema {
+	s := openapi3.NewObjectSchema().
+		WithProperty("warehouse_name", openapi3.NewStringSchema()).
+		WithProperty("product_count", openapi3.NewIntegerSchema())
+	s.Required = []string{"warehouse_name", "product_count"}
+	return closed(s)
+}
+
+func warehouseProductsSchema() *openapi3.Schema {
+	totals := openapi3.NewObjectSchema().
+		WithProperty("products", openapi3.NewIntegerSchema()).
+		WithProperty("units", openapi3.NewIntegerSchema()).
+		WithProperty("published", openapi3.NewIntegerSchema()).
+		WithProperty("expired", openapi3.NewIntegerSchema()).
+		WithProperty("expired_units", openapi3.NewIntegerSchema()).
+		WithProperty("stock_value", openapi3.NewFloat64Schema()).
+		WithProperty("currency", currencySchema())
+	totals.Required = []string{"products", "units", "published", "expired", "expired_units", "stock_value", "currency"}
+
+	s := openapi3.NewObjectSchema().
+		WithProperty("warehouse_id", openapi3.NewIntegerSchema()).
+		WithProperty("totals", closed(totals)).
+		WithProperty("limit", openapi3.NewIntegerSchema()).
+		WithProperty("offset", openapi3.NewIntegerSchema())
+	// Built directly rather than with ref, which would call back into
+	// schemas while it is still being built.
+	s.Properties["products"] = arrayOf(&openapi3.SchemaRef{Ref: "#/components/schemas/Product", Value: productSchema()})
+	s.Required = []string{"warehouse_id", "products", "totals", "limit", "offset"}
+	return closed(s)
+}
+
+func categoryProperties() openapi3.Schemas {
+	return openapi3.Schemas{
+		"name":      openapi3.NewStringSchema().NewRef(),
+		"parent_id": openapi3.NewIntegerSchema().WithNullable().NewRef(),
+	}
+}
+
+func categorySchema() *openapi3.Schema {
+	s := openapi3.NewObjectSchema()
+	s.Properties = categoryProperties()
+	s.Properties["id"] = openapi3.NewIntegerSchema().NewRef()
+	s.Required = []string{"id", "name", "parent_id"}
+	return closed(s)
+}
+
+func categoryInputSchema() *openapi3.Schema {
+	s := openapi3.NewObjectSchema()
+	s.Properties = categoryProperties()
+	s.Properties["id"] = openapi3.NewIntegerSchema().NewRef()
+	return closed(s)
+}
+
+// categoryNodeSchema is recursive: children refer back to CategoryNode. The
+// reference is resolved to the schema itself rather than through ref, which
+// would never return.
+func categoryNodeSchema() *openapi3.Schema {
+	s := categorySchema()
+	s.Properties["children"] = arrayOf(&openapi3.SchemaRef{Ref: "#/components/schemas/CategoryNode", Value: s})
+	s.Required = append(s.Required, "children")
+	return s
+}
+
+func categoryReportSchema() *openapi3.Schema {
+	s := openapi3.NewObjectSchema().
+		WithProperty("category_id", openapi3.NewIntegerSchema().WithNullable()).
+		WithProperty("category_name", openapi3.NewStringSchema()).
+		WithProperty("product_count", openapi3.NewIntegerSchema()).
+		WithProperty("units", openapi3.NewIntegerSchema())
+	s.Required = []string{"category_id", "category_name", "product_count", "units"}
+	return closed(s)
+}
+
+func reservationSchema() *openapi3.Schema {
+	s := openapi3.NewObjectSchema().
+		WithProperty("id", openapi3.NewIntegerSchema()).
+		WithProperty("product_id", openapi3.NewIntegerSchema()).
+		WithProperty("quantity", openapi3.NewIntegerSchema()).
+		WithProperty("status", openapi3.NewStringSchema().WithEnum("active", "confirmed", "released", "expired")).
+		WithProperty("expires_at", openapi3.NewDateTimeSchema()).
+		WithProperty("created_at", openapi3.NewDateTimeSchema())
+	s.Required = []string{"id", "product_id", "quantity", "status", "expires_at", "created_at"}
+	return closed(s)
+}
+
+func reservationInputSchema() *openapi3.Schema {
+	s := openapi3.NewObjectSchema().
+		WithProperty("quantity", openapi3.NewIntegerSchema().WithMin(1)).
+		WithProperty("ttl_seconds", openapi3.NewIntegerSchema().WithMin(0).WithMax(86400))
+	s.Required = []string{"quantity"}
+	return closed(s)
+}
+
+func stockAvailabilitySchema() *openapi3.Schema {
+	s := openapi3.NewObjectSchema().
+		WithProperty("product_id", openapi3.NewIntegerSchema()).
+		WithProperty("quantity", openapi3.NewIntegerSchema()).
+		WithProperty("reserved", openapi3.NewIntegerSchema()).
+		WithProperty("available", openapi3.NewIntegerSchema())
+	s.Required = []string{"product_id", "quantity", "reserved", "available"}
+	return closed(s)
+}
+
+func reorderPolicySchema() *openapi3.Schema {
+	s := openapi3.NewObjectSchema().
+		WithProperty("reorder_point", openapi3.NewIntegerSchema().WithMin(0).WithNullable()).
+		WithProperty("reorder_quantity", openapi3.NewIntegerSchema().WithMin(0).WithNullable())
+	return closed(s)
+}
+
+func lowStockItemSchema() *openapi3.Schema {
+	s := openapi3.NewObjectSchema().
+		WithProperty("product_id", openapi3.NewIntegerSchema()).
+		WithProperty("name", openapi3.NewStringSchema()).
+		WithProperty("code_value", openapi3.NewStringSchema()).
+		WithProperty("warehouse_id", openapi3.NewIntegerSchema()).
+		WithProperty("quantity", openapi3.NewIntegerSchema()).
+		WithProperty("reorder_point", openapi3.NewIntegerSchema()).
+		WithProperty("reorder_quantity", openapi3.NewIntegerSchema())
+	s.Required = []string{"product_id", "name", "code_value", "warehouse_id", "quantity", "reorder_point", "reorder_quantity"}
+	return closed(s)
+}
+
+func lotProperties() openapi3.Schemas {
+	return openapi3.Schemas{
+		"id":            openapi3.NewIntegerSchema().NewRef(),
+		"product_id":    openapi3.NewIntegerSchema().NewRef(),
+		"lot_code":      openapi3.NewStringSchema().NewRef(),
+		"quantity":      openapi3.NewIntegerSchema().NewRef(),
+		"unit_cost":     openapi3.NewFloat64Schema().NewRef(),
+		"cost_currency": currencySchema().NewRef(),
+		"expiration":    openapi3.NewDateTimeSchema().NewRef(),
+		"received_at":   openapi3.NewDateTimeSchema().NewRef(),
+		"location_id":   openapi3.NewIntegerSchema().WithNullable().NewRef(),
+	}
+}
+
+func lotSchema() *openapi3.Schema {
+	s := openapi3.NewObjectSchema()
+	s.Properties = lotProperties()
+	s.Required = []string{"id", "product_id", "lot_code", "quantity", "unit_cost", "cost_currency", "expiration", "received_at", "location_id"}
+	return closed(s)
+}
+
+// lotInputSchema is the body of a received lot; the product comes from the
+// path and a missing cost_currency is the currency of the product.
+func lotInputSchema() *openapi3.Schema {
+	s := openapi3.NewObjectSchema().
+		WithProperty("lot_code", openapi3.NewStringSchema().WithMaxLength(64)).
+		WithProperty("quantity", openapi3.NewIntegerSchema().WithMin(1)).
+		WithProperty("unit_cost", openapi3.NewFloat64Schema().WithMin(0)).
+		WithProperty("cost_currency", currencySchema()).
+		WithProperty("expiration", openapi3.NewDateTimeSchema()).
+		WithProperty("received_at", openapi3.NewDateTimeSchema()).
+		WithProperty("location_id", openapi3.NewIntegerSchema().WithNullable())
+	s.Required = []string{"quantity", "expiration"}
+	return closed(s)
+}
+
+func lotIssueSchema() *openapi3.Schema {
+	s := openapi3.NewObjectSchema().
+		WithProperty("quantity", openapi3.NewIntegerSchema().WithMin(1))
+	s.Required = []string{"quantity"}
+	return closed(s)
+}
+
+func lotAllocationSchema() *openapi3.Schema {
+	s := openapi3.NewObjectSchema().
+		WithProperty("lot_id", openapi3.NewIntegerSchema()).
+		WithProperty("lot_code", openapi3.NewStringSchema()).
+		WithProperty("quantity", openapi3.NewIntegerSchema()).
+		WithProperty("expiration", openapi3.NewDateTimeSchema())
+	s.Required = []string{"lot_id", "lot_code", "quantity", "expiration"}
+	return closed(s)
+}
+
+func expiringLotSchema() *openapi3.Schema {
+	s := openapi3.NewObjectSchema()
+	s.Properties = lotProperties()
+	s.Properties["product_name"] = openapi3.NewStringSchema().NewRef()
+	s.Properties["code_value"] = openapi3.NewStringSchema().NewRef()
+	s.Properties["warehouse_id"] = openapi3.NewIntegerSchema().NewRef()
+	s.Required = []string{"id", "product_id", "lot_code", "quantity", "unit_cost", "cost_currency", "expiration", "received_at", "location_id", "product_name", "code_value", "warehouse_id"}
+	return closed(s)
+}
+
+func lotMoveSchema() *openapi3.Schema {
+	s := openapi3.NewObjectSchema().
+		WithProperty("location_id", openapi3.NewIntegerSchema())
+	s.Required = []string{"location_id"}
+	return closed(s)
+}
+
+func locationSchema() *openapi3.Schema {
+	s := openapi3.NewObjectSchema().
+		WithProperty("id", openapi3.NewIntegerSchema()).
+		WithProperty("warehouse_id", openapi3.NewIntegerSchema()).
+		WithProperty("parent_id", openapi3.NewIntegerSchema().WithNullable()).
+		WithProperty("kind", openapi3.NewStringSchema().WithEnum("zone", "aisle", "shelf", "bin")).
+		WithProperty("code", openapi3.NewStringSchema()).
+		WithProperty("capacity", openapi3.NewIntegerSchema().WithNullable())
+	s.Required = []string{"id", "warehouse_id", "parent_id", "kind", "code", "capacity"}
+	return closed(s)
+}
+
+// locationInputSchema is the body accepted on create and update; the
+// warehouse comes from the path, and only code and capacity can change.
+func locationInputSchema() *openapi3.Schema {
+	s := openapi3.NewObjectSchema().
+		WithProperty("parent_id", openapi3.NewIntegerSchema().WithNullable()).
+		WithProperty("kind", openapi3.NewStringSchema().WithEnum("zone", "aisle", "shelf", "bin")).
+		WithProperty("code", openapi3.NewStringSchema().WithMaxLength(32)).
+		WithProperty("capacity", openapi3.NewIntegerSchema().WithMin(0).WithNullable())
+	return closed(s)
+}
+
+// locationNodeSchema is recursive like categoryNodeSchema.
+func locationNodeSchema() *openapi3.Schema {
+	s := locationSchema()
+	s.Properties["units"] = openapi3.NewIntegerSchema().NewRef()
+	s.Properties["children"] = arrayOf(&openapi3.SchemaRef{Ref: "#/components/schemas/LocationNode", Value: s})
+	s.Required = append(s.Required, "units", "children")
+	return s
+}
+
+func priceChangeSchema() *openapi3.Schema {
+	s := openapi3.NewObjectSchema().
+		WithProperty("id", openapi3.NewIntegerSchema()).
+		WithProperty("product_id", openapi3.NewIntegerSchema()).
+		WithProperty("price", openapi3.NewFloat64Schema()).
+		WithProperty("currency", currencySchema()).
+		WithProperty("effective_at", openapi3.NewDateTimeSchema()).
+		WithProperty("applied", openapi3.NewBoolSchema()).
+		WithProperty("created_at", openapi3.NewDateTimeSchema())
+	s.Required = []string{"id", "product_id", "price", "currency", "effective_at", "applied", "created_at"}
+	return closed(s)
+}
+
+// priceInputSchema is the body of a price change; without effective_at the
+// price applies right away and without currency the product keeps its own.
+func priceInputSchema() *openapi3.Schema {
+	s := openapi3.NewObjectSchema().
+		WithProperty("price", openapi3.NewFloat64Schema().WithMin(0)).
+		WithProperty("currency", currencySchema()).
+		WithProperty("effective_at", openapi3.NewDateTimeSchema())
+	s.Required = []string{"price"}
+	return closed(s)
+}
+
+func productValuationSchema() *openapi3.Schema {
+	s := openapi3.NewObjectSchema().
+		WithProperty("product_id", openapi3.NewIntegerSchema()).
+		WithProperty("name", openapi3.NewStringSchema()).
+		WithProperty("code_value", openapi3.NewStringSchema()).
+		WithProperty("quantity", openapi3.NewIntegerSchema()).
+		WithProperty("price", openapi3.NewFloat64Schema()).
+		WithProperty("currency", currencySchema()).
+		WithProperty("value", openapi3.NewFloat64Schema())
+	s.Required = []string{"product_id", "name", "code_value", "quantity", "price", "currency", "value"}
+	return closed(s)
+}
+
+func stockValuationSchema() *openapi3.Schema {
+	s := openapi3.NewObjectSchema().
+		WithProperty("warehouse_id", openapi3.NewIntegerSchema()).
+		WithProperty("at", openapi3.NewDateTimeSchema()).
+		WithProperty("units", openapi3.NewIntegerSchema()).
+		WithProperty("value", openapi3.NewFloat64Schema()).
+		WithProperty("currency", currencySchema()).
+		WithPropertyRef("products", arrayOf(&openapi3.SchemaRef{Ref: "#/components/schemas/ProductValuation", Value: productValuationSchema()}))
+	s.Required = []string{"warehouse_id", "at", "units", "value", "currency", "products"}
+	return closed(s)
+}
+
+func exchangeRateSchema() *openapi3.Schema {
+	s := openapi3.NewObjectSchema().
+		WithProperty("currency", currencySchema()).
+		WithProperty("rate", openapi3.NewFloat64Schema()).
+		WithProperty("source", openapi3.NewStringSchema().WithEnum("manual", "file")).
+		WithProperty("updated_at", openapi3.NewDateTimeSchema())
+	s.Required = []string{"currency", "rate", "source", "updated_at"}
+	return closed(s)
+}
+
+func productCostSchema() *openapi3.Schema {
+	s := openapi3.NewObjectSchema().
+		WithProperty("product_id", openapi3.NewIntegerSchema()).
+		WithProperty("name", openapi3.NewStringSchema()).
+		WithProperty("code_value", openapi3.NewStringSchema()).
+		WithProperty("quantity", openapi3.NewIntegerSchema()).
+		WithProperty("unit_cost", openapi3.NewFloat64Schema()).
+		WithProperty("value", openapi3.NewFloat64Schema()).
+		WithProperty("currency", currencySchema())
+	s.Required = []string{"product_id", "name", "code_value", "quantity", "unit_cost", "value", "currency"}
+	return closed(s)
+}
+
+// warehouseCostReportSchema is the warehouse report extended with the stock
+// valued at cost.
+func warehouseCostReportSchema() *openapi3.Schema {
+	s := warehouseReportSchema().
+		WithProperty("warehouse_id", openapi3.NewIntegerSchema()).
+		WithProperty("method", openapi3.NewStringSchema().WithEnum("fifo", "average")).
+		WithProperty("at", openapi3.NewDateTimeSchema()).
+		WithProperty("currency", currencySchema()).
+		WithProperty("units", openapi3.NewIntegerSchema()).
+		WithProperty("value", openapi3.NewFloat64Schema()).
+		WithPropertyRef("products", arrayOf(&openapi3.SchemaRef{Ref: "#/components/schemas/ProductCost", Value: productCostSchema()}))
+	s.Required = append(s.Required, "warehouse_id", "method", "at", "currency", "units", "value", "products")
+	return s
+}
+
+// exchangeRateInputSchema is the body of a manually set rate: what one unit
+// of the base currency buys in the currency of the path.
+func exchangeRateInputSchema() *openapi3.Schema {
+	s := openapi3.NewObjectSchema().
+		WithProperty("rate", openapi3.NewFloat64Schema().WithMin(0).WithExclusiveMin(true))
+	s.Required = []string{"rate"}
+	return closed(s)
+}
+
+// labelTemplateSchema lays labels out on a page; lengths are in millimetres
+// and the font size in points.
+func labelTemplateSchema() *openapi3.Schema {
+	s := openapi3.NewObjectSchema().
+		WithProperty("name", openapi3.NewStringSchema()).
+		WithProperty("page_width", openapi3.NewFloat64Schema()).
+		WithProperty("page_height", openapi3.NewFloat64Schema()).
+		WithProperty("columns", openapi3.NewIntegerSchema()).
+		WithProperty("rows", openapi3.NewIntegerSchema()).
+		WithProperty("margin_top", openapi3.NewFloat64Schema()).
+		WithProperty("margin_left", openapi3.NewFloat64Schema()).
+		WithProperty("label_width", openapi3.NewFloat64Schema()).
+		WithProperty("label_height", openapi3.NewFloat64Schema()).
+		WithProperty("gap_x", openapi3.NewFloat64Schema()).
+		WithProperty("gap_y", openapi3.NewFloat64Schema()).
+		WithProperty("padding", openapi3.NewFloat64Schema()).
+		WithProperty("font_size", openapi3.NewFloat64Schema()).
+		WithProperty("symbology", openapi3.NewStringSchema().WithEnum("code128", "ean13", "qr"))
+	s.Required = []string{"name", "page_width", "page_height", "columns", "rows", "margin_top", "margin_left",
+		"label_width", "label_height", "gap_x", "gap_y", "padding", "font_size", "symbology"}
+	return closed(s)
+}
+
+func healthSchema() *openapi3.Schema {
+	s := openapi3.NewObjectSchema().
+		WithProperty("status", openapi3.NewStringSchema()).
+		WithProperty("checks", openapi3.NewObjectSchema().WithAdditionalProperties(openapi3.NewStringSchema()))
+	s.Required = []string{"status"}
+	return closed(s)
+}
+
+func graphQLRequestSchema() *openapi3.Schema {
+	s := openapi3.NewObjectSchema().
+		WithProperty("query", openapi3.NewStringSchema()).
+		WithProperty("operationName", openapi3.NewStringSchema().WithNullable()).
+		WithProperty("variables", openapi3.NewObjectSchema().WithNullable())
+	s.Required = []string{"query"}
+	return closed(s)
+}
+
+// graphQLResultSchema only fixes the top level; the shape of data depends on
+// the query.
+func graphQLResultSchema() *openapi3.Schema {
+	return openapi3.NewObjectSchema().
+		WithProperty("data", openapi3.NewObjectSchema().WithNullable()).
+		WithProperty("errors", openapi3.NewArraySchema().WithItems(openapi3.NewObjectSchema()))
+}
@@ -0,0 +1,1017 @@
+// Package docs builds the OpenAPI 3 description of the HTTP API. Every route
+// registered by routes.MapRoutes has an entry in operations; the routes
+// tests fail when the two drift apart.
+package docs
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const (
+	tagHealth       = "Health"
+	tagGraphQL      = "GraphQL"
+	tagProducts     = "Products"
+	tagWarehouses   = "Warehouses"
+	tagCategories   = "Categories"
+	tagReservations = "Reservations"
+	tagReorder      = "Reorder"
+	tagLots         = "Lots"
+	tagLocations    = "Locations"
+	tagPrices       = "Prices"
+	tagExchange     = "Exchange rates"
+	tagCosting      = "Costing"
+	tagLabels       = "Labels"
+)
+
+// operation describes one route. A nil response schema means the response
+// has no body, or one of the produces media types when the status is 200.
+type operation struct {
+	method    string
+	path      string
+	id        string
+	summary   string
+	tag       string
+	params    []*openapi3.Parameter
+	body      *openapi3.SchemaRef
+	responses map[int]*openapi3.SchemaRef
+	produces  []string
+}
+
+func operations() []operation {
+	return []operation{
+		{
+			method: http.MethodGet, path: "/healthz", id: "live", tag: tagHealth,
+			summary:   "Liveness probe",
+			responses: map[int]*openapi3.SchemaRef{http.StatusOK: ref("Health")},
+		},
+		{
+			method: http.MethodGet, path: "/readyz", id: "ready", tag: tagHealth,
+			summary: "Readiness probe: database reachable and schema up to date",
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                 ref("Health"),
+				http.StatusServiceUnavailable: ref("Health"),
+			},
+		},
+		{
+			method: http.MethodGet, path: "/metrics", id: "metrics", tag: tagHealth,
+			summary:   "Prometheus metrics in the text exposition format",
+			responses: map[int]*openapi3.SchemaRef{http.StatusOK: nil},
+		},
+
+		// Products
+		{
+			method: http.MethodGet, path: "/api/v1/products/", id: "listProducts", tag: tagProducts,
+			summary: "List all products, or those of a category and its subcategories",
+			params: []*openapi3.Parameter{
+				openapi3.NewQueryParameter("category_id").WithSchema(openapi3.NewIntegerSchema()).WithDescription("Category ID"),
+				currencyQuery("Currency to convert prices into; prices keep their own when missing"),
+			},
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(arrayOf(ref("Product"))),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusUnprocessableEntity: ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodGet, path: "/api/v1/products/search", id: "searchProducts", tag: tagProducts,
+			summary: "Search products by name and code_value, best match first, tolerating typos",
+			params: []*openapi3.Parameter{
+				openapi3.NewQueryParameter("q").WithRequired(true).WithSchema(openapi3.NewStringSchema().WithMinLength(1)).
+					WithDescription("Words to find; the last one also matches as a prefix"),
+				openapi3.NewQueryParameter("limit").WithSchema(openapi3.NewIntegerSchema().WithMin(1).WithMax(100)).
+					WithDescription("Maximum number of matches; defaults to 20"),
+			},
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(arrayOf(ref("ProductMatch"))),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodPost, path: "/api/v1/products", id: "createProduct", tag: tagProducts,
+			summary: "Create a product",
+			body:    ref("ProductInput"),
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusCreated:             envelope(ref("Product")),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusConflict:            ref("Error"),
+				http.StatusUnprocessableEntity: ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodGet, path: "/api/v1/products/{id}", id: "getProduct", tag: tagProducts,
+			summary: "Get a product by id",
+			params:  []*openapi3.Parameter{idParam("Product ID"), ifNoneMatchHeader()},
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(ref("Product")),
+				http.StatusNotModified:         nil,
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodDelete, path: "/api/v1/products/{id}", id: "deleteProduct", tag: tagProducts,
+			summary: "Delete a product",
+			params:  []*openapi3.Parameter{idParam("Product ID")},
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusNoContent:           nil,
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodPatch, path: "/api/v1/products/{id}", id: "updateProduct", tag: tagProducts,
+			summary: "Update some fields of a product",
+			params:  []*openapi3.Parameter{idParam("Product ID")},
+			body:    ref("ProductInput"),
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(ref("Product")),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusConflict:            ref("Error"),
+				http.StatusUnprocessableEntity: ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodGet, path: "/api/v1/products/{id}/withWarehouse", id: "getProductWithWarehouse", tag: tagProducts,
+			summary: "Get a product together with its warehouse",
+			params:  []*openapi3.Parameter{idParam("Product ID")},
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(ref("ProductWithWarehouse")),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodGet, path: "/api/v1/products/by-code/{code}", id: "getProductByCode", tag: tagProducts,
+			summary: "Get a product by its code_value",
+			params:  []*openapi3.Parameter{codeParam()},
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(ref("Product")),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodPut, path: "/api/v1/products/by-code/{code}", id: "upsertProductByCode", tag: tagProducts,
+			summary: "Create or replace the product with the given code_value",
+			params:  []*openapi3.Parameter{codeParam()},
+			body:    ref("ProductInput"),
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(ref("Product")),
+				http.StatusCreated:             envelope(ref("Product")),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusConflict:            ref("Error"),
+				http.StatusUnprocessableEntity: ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodPost, path: "/api/v1/products:batch", id: "batchProducts", tag: tagProducts,
+			summary: "Create, update and delete products in one request, atomically or each on its own",
+			body:    ref("ProductBatch"),
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(arrayOf(ref("BatchResult"))),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+
+		// Warehouses
+		{
+			method: http.MethodGet, path: "/api/v1/warehouses/", id: "listWarehouses", tag: tagWarehouses,
+			summary: "List all warehouses",
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(arrayOf(ref("Warehouse"))),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodPost, path: "/api/v1/warehouses", id: "createWarehouse", tag: tagWarehouses,
+			summary: "Create a warehouse",
+			body:    ref("WarehouseInput"),
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusCreated:    envelope(ref("Warehouse")),
+				http.StatusBadRequest: ref("Error"),
+				http.StatusConflict:   ref("Error"),
+			},
+		},
+		{
+			method: http.MethodGet, path: "/api/v1/warehouses/{id}", id: "getWarehouse", tag: tagWarehouses,
+			summary: "Get a warehouse by id",
+			params:  []*openapi3.Parameter{idParam("Warehouse ID")},
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(ref("Warehouse")),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodDelete, path: "/api/v1/warehouses/{id}", id: "deleteWarehouse", tag: tagWarehouses,
+			summary: "Delete a warehouse",
+			params:  []*openapi3.Parameter{idParam("Warehouse ID")},
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusNoContent:  nil,
+				http.StatusBadRequest: ref("Error"),
+				http.StatusNotFound:   ref("Error"),
+			},
+		},
+		{
+			method: http.MethodPatch, path: "/api/v1/warehouses/{id}", id: "updateWarehouse", tag: tagWarehouses,
+			summary: "Update some fields of a warehouse",
+			params:  []*openapi3.Parameter{idParam("Warehouse ID")},
+			body:    ref("WarehouseInput"),
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:         envelope(ref("Warehouse")),
+				http.StatusBadRequest: ref("Error"),
+				http.StatusNotFound:   ref("Error"),
+			},
+		},
+		{
+			method: http.MethodGet, path: "/api/v1/warehouses/reportProducts", id: "reportWarehouseProducts", tag: tagWarehouses,
+			summary: "Count the products stored in a warehouse",
+			params: []*openapi3.Parameter{
+				openapi3.NewQueryParameter("id").WithRequired(true).WithSchema(openapi3.NewIntegerSchema()).WithDescription("Warehouse ID"),
+				ifNoneMatchHeader(),
+			},
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(ref("WarehouseReport")),
+				http.StatusNotModified:         nil,
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodGet, path: "/api/v1/warehouses/{id}/products", id: "listWarehouseProducts", tag: tagWarehouses,
+			summary: "List the products stored in a warehouse with totals",
+			params: []*openapi3.Parameter{
+				idParam("Warehouse ID"),
+				openapi3.NewQueryParameter("limit").WithSchema(openapi3.NewIntegerSchema().WithMin(1).WithMax(200)),
+				openapi3.NewQueryParameter("offset").WithSchema(openapi3.NewIntegerSchema().WithMin(0)),
+				openapi3.NewQueryParameter("sort").WithSchema(openapi3.NewStringSchema().WithEnum("id", "name", "quantity", "code_value", "expiration", "price")),
+				openapi3.NewQueryParameter("order").WithSchema(openapi3.NewStringSchema().WithEnum("asc", "desc")),
+				openapi3.NewQueryParameter("published").WithSchema(openapi3.NewBoolSchema()),
+				openapi3.NewQueryParameter("expired").WithSchema(openapi3.NewBoolSchema()),
+				currencyQuery("Currency to convert prices and the stock value into; the stock value is in the base currency when missing"),
+			},
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(ref("WarehouseProducts")),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusUnprocessableEntity: ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+
+		// Categories
+		{
+			method: http.MethodGet, path: "/api/v1/categories/", id: "listCategories", tag: tagCategories,
+			summary: "List all categories",
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(arrayOf(ref("Category"))),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodPost, path: "/api/v1/categories", id: "createCategory", tag: tagCategories,
+			summary: "Create a category",
+			body:    ref("CategoryInput"),
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusCreated:             envelope(ref("Category")),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusUnprocessableEntity: ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodGet, path: "/api/v1/categories/tree", id: "categoryTree", tag: tagCategories,
+			summary: "Get the category hierarchy",
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(arrayOf(ref("CategoryNode"))),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodGet, path: "/api/v1/categories/report", id: "reportCategories", tag: tagCategories,
+			summary: "Count products and units per category",
+			params: []*openapi3.Parameter{
+				openapi3.NewQueryParameter("warehouse_id").WithSchema(openapi3.NewIntegerSchema()).WithDescription("Only count products of this warehouse"),
+			},
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(arrayOf(ref("CategoryReport"))),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodGet, path: "/api/v1/categories/{id}", id: "getCategory", tag: tagCategories,
+			summary: "Get a category by id",
+			params:  []*openapi3.Parameter{idParam("Category ID")},
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(ref("Category")),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodPatch, path: "/api/v1/categories/{id}", id: "updateCategory", tag: tagCategories,
+			summary: "Rename a category or move it under another parent",
+			params:  []*openapi3.Parameter{idParam("Category ID")},
+			body:    ref("CategoryInput"),
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(ref("Category")),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusUnprocessableEntity: ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodDelete, path: "/api/v1/categories/{id}", id: "deleteCategory", tag: tagCategories,
+			summary: "Delete a category that has no products or subcategories",
+			params:  []*openapi3.Parameter{idParam("Category ID")},
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusNoContent:           nil,
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusConflict:            ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+
+		// Reservations
+		{
+			method: http.MethodPost, path: "/api/v1/products/{id}/reservations", id: "createReservation", tag: tagReservations,
+			summary: "Hold stock of a product until the reservation is confirmed, released or expires",
+			params:  []*openapi3.Parameter{idParam("Product ID")},
+			body:    ref("ReservationInput"),
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusCreated:             envelope(ref("Reservation")),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusConflict:            ref("Error"),
+				http.StatusUnprocessableEntity: ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodGet, path: "/api/v1/products/{id}/reservations", id: "listProductReservations", tag: tagReservations,
+			summary: "List the reservations made against a product",
+			params:  []*openapi3.Parameter{idParam("Product ID")},
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(arrayOf(ref("Reservation"))),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodGet, path: "/api/v1/products/{id}/availability", id: "getProductAvailability", tag: tagReservations,
+			summary: "Get the quantity of a product not held by active reservations",
+			params:  []*openapi3.Parameter{idParam("Product ID")},
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(ref("StockAvailability")),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodGet, path: "/api/v1/reservations/{id}", id: "getReservation", tag: tagReservations,
+			summary: "Get a reservation by id",
+			params:  []*openapi3.Parameter{idParam("Reservation ID")},
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(ref("Reservation")),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodPost, path: "/api/v1/reservations/{id}/confirm", id: "confirmReservation", tag: tagReservations,
+			summary: "Confirm a reservation, issuing its stock",
+			params:  []*openapi3.Parameter{idParam("Reservation ID")},
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(ref("Reservation")),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusConflict:            ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodPost, path: "/api/v1/reservations/{id}/release", id: "releaseReservation", tag: tagReservations,
+			summary: "Release a reservation, returning its stock",
+			params:  []*openapi3.Parameter{idParam("Reservation ID")},
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(ref("Reservation")),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusConflict:            ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+
+		// Reorder
+		{
+			method: http.MethodGet, path: "/api/v1/products/low-stock", id: "listLowStock", tag: tagReorder,
+			summary: "List the products at or below their reorder point",
+			params: []*openapi3.Parameter{
+				openapi3.NewQueryParameter("warehouse_id").WithSchema(openapi3.NewIntegerSchema()).WithDescription("Only list products of this warehouse"),
+			},
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(arrayOf(ref("LowStockItem"))),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodGet, path: "/api/v1/products/{id}/reorder", id: "getProductReorderPolicy", tag: tagReorder,
+			summary: "Get the reorder policy set on a product",
+			params:  []*openapi3.Parameter{idParam("Product ID")},
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(ref("ReorderPolicy")),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodPut, path: "/api/v1/products/{id}/reorder", id: "setProductReorderPolicy", tag: tagReorder,
+			summary: "Set the reorder policy of a product; null values use the warehouse defaults",
+			params:  []*openapi3.Parameter{idParam("Product ID")},
+			body:    ref("ReorderPolicy"),
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(ref("ReorderPolicy")),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusUnprocessableEntity: ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodGet, path: "/api/v1/warehouses/{id}/reorder", id: "getWarehouseReorderPolicy", tag: tagReorder,
+			summary: "Get the default reorder policy of a warehouse",
+			params:  []*openapi3.Parameter{idParam("Warehouse ID")},
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(ref("ReorderPolicy")),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodPut, path: "/api/v1/warehouses/{id}/reorder", id: "setWarehouseReorderPolicy", tag: tagReorder,
+			summary: "Set the default reorder policy of the products of a warehouse",
+			params:  []*openapi3.Parameter{idParam("Warehouse ID")},
+			body:    ref("ReorderPolicy"),
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(ref("ReorderPolicy")),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusUnprocessableEntity: ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+
+		// Lots
+		{
+			method: http.MethodGet, path: "/api/v1/products/{id}/lots", id: "listProductLots", tag: tagLots,
+			summary: "List the lots of a product, earliest expiration first",
+			params:  []*openapi3.Parameter{idParam("Product ID")},
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(arrayOf(ref("Lot"))),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodPost, path: "/api/v1/products/{id}/lots", id: "receiveLot", tag: tagLots,
+			summary: "Receive a lot of a product",
+			params:  []*openapi3.Parameter{idParam("Product ID")},
+			body:    ref("LotInput"),
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusCreated:             envelope(ref("Lot")),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusConflict:            ref("Error"),
+				http.StatusUnprocessableEntity: ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodPost, path: "/api/v1/products/{id}/lots/issue", id: "issueLots", tag: tagLots,
+			summary: "Issue stock of a product first-expired-first-out from its unexpired lots",
+			params:  []*openapi3.Parameter{idParam("Product ID")},
+			body:    ref("LotIssue"),
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(arrayOf(ref("LotAllocation"))),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusConflict:            ref("Error"),
+				http.StatusUnprocessableEntity: ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodGet, path: "/api/v1/lots/expiring", id: "listExpiringLots", tag: tagLots,
+			summary: "List the lots in stock expiring soon, expired ones included",
+			params: []*openapi3.Parameter{
+				openapi3.NewQueryParameter("within").WithSchema(openapi3.NewStringSchema()).WithDescription("Window as a Go duration such as 72h; defaults to 168h"),
+				openapi3.NewQueryParameter("warehouse_id").WithSchema(openapi3.NewIntegerSchema()).WithDescription("Only list lots of this warehouse"),
+			},
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(arrayOf(ref("ExpiringLot"))),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodGet, path: "/api/v1/lots/{id}", id: "getLot", tag: tagLots,
+			summary: "Get a lot by id",
+			params:  []*openapi3.Parameter{idParam("Lot ID")},
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(ref("Lot")),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+
+		{
+			method: http.MethodPut, path: "/api/v1/lots/{id}/location", id: "moveLot", tag: tagLots,
+			summary: "Place a lot in a bin with room for it",
+			params:  []*openapi3.Parameter{idParam("Lot ID")},
+			body:    ref("LotMove"),
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(ref("Lot")),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusConflict:            ref("Error"),
+				http.StatusUnprocessableEntity: ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+
+		// Locations
+		{
+			method: http.MethodGet, path: "/api/v1/warehouses/{id}/locations", id: "warehouseLocationTree", tag: tagLocations,
+			summary: "Get the location tree of a warehouse with the units stored under each location",
+			params:  []*openapi3.Parameter{idParam("Warehouse ID")},
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(arrayOf(ref("LocationNode"))),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodPost, path: "/api/v1/warehouses/{id}/locations", id: "createLocation", tag: tagLocations,
+			summary: "Add a zone, aisle, shelf or bin to a warehouse",
+			params:  []*openapi3.Parameter{idParam("Warehouse ID")},
+			body:    ref("LocationInput"),
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusCreated:             envelope(ref("Location")),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusUnprocessableEntity: ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodGet, path: "/api/v1/locations/{id}", id: "getLocation", tag: tagLocations,
+			summary: "Get a location with its units and direct sublocations",
+			params:  []*openapi3.Parameter{idParam("Location ID")},
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(ref("LocationNode")),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodPatch, path: "/api/v1/locations/{id}", id: "updateLocation", tag: tagLocations,
+			summary: "Change the code or capacity of a location",
+			params:  []*openapi3.Parameter{idParam("Location ID")},
+			body:    ref("LocationInput"),
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(ref("Location")),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusUnprocessableEntity: ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodDelete, path: "/api/v1/locations/{id}", id: "deleteLocation", tag: tagLocations,
+			summary: "Delete a location without sublocations or stock",
+			params:  []*openapi3.Parameter{idParam("Location ID")},
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusNoContent:           nil,
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusConflict:            ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+
+		// Prices
+		{
+			method: http.MethodGet, path: "/api/v1/products/{id}/prices", id: "listProductPrices", tag: tagPrices,
+			summary: "Get the price timeline of a product, scheduled changes included",
+			params:  []*openapi3.Parameter{idParam("Product ID")},
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(arrayOf(ref("PriceChange"))),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodPost, path: "/api/v1/products/{id}/prices", id: "scheduleProductPrice", tag: tagPrices,
+			summary: "Change the price of a product now or at a future date",
+			params:  []*openapi3.Parameter{idParam("Product ID")},
+			body:    ref("PriceInput"),
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusCreated:             envelope(ref("PriceChange")),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusUnprocessableEntity: ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodDelete, path: "/api/v1/prices/{id}", id: "cancelPrice", tag: tagPrices,
+			summary: "Cancel a scheduled price change that has not been applied",
+			params:  []*openapi3.Parameter{idParam("Price change ID")},
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusNoContent:           nil,
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusConflict:            ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodGet, path: "/api/v1/warehouses/{id}/valuation", id: "warehouseValuation", tag: tagPrices,
+			summary: "Value the stock of a warehouse at the prices in effect at a date",
+			params: []*openapi3.Parameter{
+				idParam("Warehouse ID"),
+				openapi3.NewQueryParameter("at").WithSchema(openapi3.NewDateTimeSchema()).WithDescription("RFC 3339 date to price at; defaults to now"),
+				currencyQuery("Currency to value the stock in; defaults to the base currency"),
+			},
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(ref("StockValuation")),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusUnprocessableEntity: ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+
+		// Exchange rates
+		{
+			method: http.MethodGet, path: "/api/v1/exchange-rates/", id: "listExchangeRates", tag: tagExchange,
+			summary: "List the rates from the base currency, manual and loaded from the rates file",
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(arrayOf(ref("ExchangeRate"))),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodPut, path: "/api/v1/exchange-rates/{currency}", id: "setExchangeRate", tag: tagExchange,
+			summary: "Set the rate of a currency by hand",
+			params:  []*openapi3.Parameter{currencyParam()},
+			body:    ref("ExchangeRateInput"),
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(ref("ExchangeRate")),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusUnprocessableEntity: ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodDelete, path: "/api/v1/exchange-rates/{currency}", id: "deleteExchangeRate", tag: tagExchange,
+			summary: "Delete the rate of a currency",
+			params:  []*openapi3.Parameter{currencyParam()},
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusNoContent:           nil,
+				http.StatusNotFound:            ref("Error"),
+				http.StatusUnprocessableEntity: ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+
+		// Costing
+		{
+			method: http.MethodGet, path: "/api/v1/products/{id}/cost", id: "productCost", tag: tagCosting,
+			summary: "Value the stock of a product on hand at a date at its purchase cost",
+			params: []*openapi3.Parameter{
+				idParam("Product ID"),
+				costMethodQuery(),
+				openapi3.NewQueryParameter("at").WithSchema(openapi3.NewDateTimeSchema()).WithDescription("RFC 3339 date to value at; defaults to now"),
+				currencyQuery("Currency to value the stock in; defaults to the base currency"),
+			},
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(ref("ProductCost")),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusUnprocessableEntity: ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodGet, path: "/api/v1/warehouses/reportValuation", id: "reportWarehouseValuation", tag: tagCosting,
+			summary: "Report the products of a warehouse with their stock at a date valued at purchase cost",
+			params: []*openapi3.Parameter{
+				openapi3.NewQueryParameter("id").WithRequired(true).WithSchema(openapi3.NewIntegerSchema()).WithDescription("Warehouse ID"),
+				costMethodQuery(),
+				openapi3.NewQueryParameter("at").WithSchema(openapi3.NewDateTimeSchema()).WithDescription("RFC 3339 date to value at; defaults to now"),
+				currencyQuery("Currency to value the stock in; defaults to the base currency"),
+			},
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  envelope(ref("WarehouseCostReport")),
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusUnprocessableEntity: ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+
+		// Labels
+		{
+			method: http.MethodGet, path: "/api/v1/products/{id}/barcode", id: "productBarcode", tag: tagLabels,
+			summary:  "Render the code_value of a product as a barcode or QR code",
+			params:   append([]*openapi3.Parameter{idParam("Product ID")}, barcodeParams()...),
+			produces: []string{"image/png", "image/svg+xml"},
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  nil,
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusUnprocessableEntity: ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodGet, path: "/api/v1/locations/{id}/barcode", id: "locationBarcode", tag: tagLabels,
+			summary:  "Render the code of a location, such as a bin, as a barcode or QR code",
+			params:   append([]*openapi3.Parameter{idParam("Location ID")}, barcodeParams()...),
+			produces: []string{"image/png", "image/svg+xml"},
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  nil,
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusUnprocessableEntity: ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodGet, path: "/api/v1/labels/templates", id: "listLabelTemplates", tag: tagLabels,
+			summary: "List the label sheet templates",
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK: envelope(arrayOf(ref("LabelTemplate"))),
+			},
+		},
+		{
+			method: http.MethodGet, path: "/api/v1/labels/products", id: "productLabelSheet", tag: tagLabels,
+			summary: "Print the labels of the products of a warehouse, a category or a list of ids as a PDF",
+			params: append([]*openapi3.Parameter{
+				openapi3.NewQueryParameter("id").WithSchema(openapi3.NewArraySchema().WithItems(openapi3.NewIntegerSchema())).
+					WithDescription("Product IDs, repeated"),
+				openapi3.NewQueryParameter("warehouse_id").WithSchema(openapi3.NewIntegerSchema()).WithDescription("Warehouse ID"),
+				openapi3.NewQueryParameter("category_id").WithSchema(openapi3.NewIntegerSchema()).
+					WithDescription("Category ID; its subcategories are included"),
+				openapi3.NewQueryParameter("published").WithSchema(openapi3.NewBoolSchema()),
+			}, labelSheetParams()...),
+			produces: []string{"application/pdf"},
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  nil,
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusUnprocessableEntity: ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+		{
+			method: http.MethodGet, path: "/api/v1/labels/locations", id: "locationLabelSheet", tag: tagLabels,
+			summary: "Print the labels of the bins, or other locations, of a warehouse as a PDF",
+			params: append([]*openapi3.Parameter{
+				openapi3.NewQueryParameter("warehouse_id").WithRequired(true).WithSchema(openapi3.NewIntegerSchema()).
+					WithDescription("Warehouse ID"),
+				openapi3.NewQueryParameter("kind").WithSchema(openapi3.NewStringSchema().WithEnum("zone", "aisle", "shelf", "bin")).
+					WithDescription("Kind of the locations to print; defaults to bin"),
+			}, labelSheetParams()...),
+			produces: []string{"application/pdf"},
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:                  nil,
+				http.StatusBadRequest:          ref("Error"),
+				http.StatusNotFound:            ref("Error"),
+				http.StatusUnprocessableEntity: ref("Error"),
+				http.StatusInternalServerError: ref("Error"),
+			},
+		},
+
+		// GraphQL
+		{
+			method: http.MethodPost, path: "/graphql", id: "graphql", tag: tagGraphQL,
+			summary: "Run a GraphQL query or mutation over products and warehouses",
+			body:    ref("GraphQLRequest"),
+			responses: map[int]*openapi3.SchemaRef{
+				http.StatusOK:         ref("GraphQLResult"),
+				http.StatusBadRequest: ref("Error"),
+			},
+		},
+	}
+}
+
+func idParam(description string) *openapi3.Parameter {
+	return openapi3.NewPathParameter("id").WithSchema(openapi3.NewIntegerSchema()).WithDescription(description)
+}
+
+func codeParam() *openapi3.Parameter {
+	return openapi3.NewPathParameter("code").WithSchema(openapi3.NewStringSchema()).WithDescription("Product code_value")
+}
+
+func currencyParam() *openapi3.Parameter {
+	return openapi3.NewPathParameter("currency").WithSchema(currencySchema()).WithDescription("ISO 4217 currency code")
+}
+
+// tenantHeader names the tenant a request acts for; without it the request
+// acts for the default tenant of the deployment.
+func tenantHeader() *openapi3.Parameter {
+	return openapi3.NewHeaderParameter("X-Tenant-ID").
+		WithSchema(openapi3.NewStringSchema().WithPattern("^[A-Za-z0-9_-]{1,64}$")).
+		WithDescription("Tenant the request acts for; defaults to the deployment's default tenant")
+}
+
+// ifNoneMatchHeader lets a client revalidate a response it kept; the
+// server answers 304 with no body while the ETag still matches.
+func ifNoneMatchHeader() *openapi3.Parameter {
+	return openapi3.NewHeaderParameter("If-None-Match").WithSchema(openapi3.NewStringSchema()).
+		WithDescription("ETag of a response already held; a match is answered with 304 Not Modified")
+}
+
+// idempotencyKeyHeader names a POST so its retries replay the first
+// response instead of running again.
+func idempotencyKeyHeader() *openapi3.Parameter {
+	return openapi3.NewHeaderParameter("Idempotency-Key").WithSchema(openapi3.NewStringSchema().WithMaxLength(255)).
+		WithDescription("Key of the request; retries with the same key and body get the first response back, " +
+			"409 while it is in flight and 422 with another body")
+}
+
+func symbologyQuery(description string) *openapi3.Parameter {
+	return openapi3.NewQueryParameter("symbology").WithSchema(openapi3.NewStringSchema().WithEnum("code128", "ean13", "qr")).
+		WithDescription(description)
+}
+
+// barcodeParams select the symbology, format and size of a barcode image.
+func barcodeParams() []*openapi3.Parameter {
+	return []*openapi3.Parameter{
+		symbologyQuery("Symbology; defaults to code128. ean13 takes codes of 12 or 13 digits"),
+		openapi3.NewQueryParameter("format").WithSchema(openapi3.NewStringSchema().WithEnum("png", "svg")).
+			WithDescription("Image format; defaults to png"),
+		openapi3.NewQueryParameter("scale").WithSchema(openapi3.NewIntegerSchema().WithMin(1).WithMax(20)).
+			WithDescription("Width of a module, the narrowest bar or a QR square, in pixels; defaults to 4"),
+		openapi3.NewQueryParameter("height").WithSchema(openapi3.NewIntegerSchema().WithMin(1).WithMax(1000)).
+			WithDescription("Height of linear barcodes in pixels; defaults to 100"),
+	}
+}
+
+// labelSheetParams select the template and symbology of a label sheet.
+func labelSheetParams() []*openapi3.Parameter {
+	return []*openapi3.Parameter{
+		openapi3.NewQueryParameter("template").WithSchema(openapi3.NewStringSchema()).
+			WithDescription("Name of the label template; defaults to a4-3x8"),
+		symbologyQuery("Symbology; defaults to the one of the template"),
+	}
+}
+
+func costMethodQuery() *openapi3.Parameter {
+	return openapi3.NewQueryParameter("method").WithSchema(openapi3.NewStringSchema().WithEnum("fifo", "average")).
+		WithDescription("Costing method; defaults to fifo")
+}
+
+func currencyQuery(description string) *openapi3.Parameter {
+	return openapi3.NewQueryParameter("currency").WithSchema(currencySchema()).WithDescription(description)
+}
+
+// Spec returns the OpenAPI document of the API.
+func Spec() *openapi3.T {
+	doc := &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info: &openapi3.Info{
+			Title:   "Inventory API",
+			Version: "1.0.0",
+		},
+		Paths:      openapi3.NewPaths(),
+		Components: &openapi3.Components{Schemas: schemas()},
+	}
+
+	for _, op := range operations() {
+		item := doc.Paths.Value(op.path)
+		if item == nil {
+			item = &openapi3.PathItem{}
+			doc.Paths.Set(op.path, item)
+		}
+		item.SetOperation(op.method, op.build())
+	}
+
+	return doc
+}
+
+func (op operation) build() *openapi3.Operation {
+	o := openapi3.NewOperation()
+	o.OperationID = op.id
+	o.Summary = op.summary
+	o.Tags = []string{op.tag}
+
+	for _, p := range op.params {
+		o.AddParameter(p)
+	}
+	// The API and GraphQL act for a tenant; health, metrics and docs do not.
+	if strings.HasPrefix(op.path, "/api/") || op.path == "/graphql" {
+		o.AddParameter(tenantHeader())
+	}
+	idempotent := strings.HasPrefix(op.path, "/api/") && op.method == http.MethodPost
+	if idempotent {
+		o.AddParameter(idempotencyKeyHeader())
+	}
+
+	if op.body != nil {
+		o.RequestBody = &openapi3.RequestBodyRef{
+			Value: openapi3.NewRequestBody().WithRequired(true).WithJSONSchemaRef(op.body),
+		}
+	}
+
+	responses := op.responses
+	// Every route of the REST API is rate limited per client.
+	if strings.HasPrefix(op.path, "/api/") {
+		responses = make(map[int]*openapi3.SchemaRef, len(op.responses)+1)
+		for status, schema := range op.responses {
+			responses[status] = schema
+		}
+		responses[http.StatusTooManyRequests] = ref("Error")
+		// A POST sent with an Idempotency-Key can clash with an earlier one.
+		if idempotent {
+			responses[http.StatusConflict] = ref("Error")
+			responses[http.StatusUnprocessableEntity] = ref("Error")
+		}
+	}
+
+	var opts []openapi3.NewResponsesOption
+	for status, schema := range responses {
+		res := openapi3.NewResponse().WithDescription(http.StatusText(status))
+		if schema != nil {
+			res.WithJSONSchemaRef(schema)
+		} else if status == http.StatusOK && len(op.produces) > 0 {
+			res.Content = openapi3.Content{}
+			for _, mediaType := range op.produces {
+				res.Content[mediaType] = openapi3.NewMediaType()
+			}
+		}
+		opts = append(opts, openapi3.WithStatus(status, &openapi3.ResponseRef{Value: res}))
+	}
+	o.Responses = openapi3.NewResponses(opts...)
+
+	return o
+}
+
+// Path converts a gin route path such as /products/:id into its OpenAPI
+// form, /products/{id}. Escaped colons, as in /products\\:batch, are literal.
+func Path(ginPath string) string {
+	segments := strings.Split(strings.ReplaceAll(ginPath, `\:`, ":"), "/")
+	for i, s := range segments {
+		if strings.HasPrefix(s, ":") || strings.HasPrefix(s, "*") {
+			segments[i] = "{" + s[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// OperationKey identifies an operation by method and OpenAPI path.
+func OperationKey(method, path string) string {
+	return method + " " + path
+}
+
+// Operations returns the keys of every documented operation.
+func Operations(doc *openapi3.T) map[string]bool {
+	keys := make(map[string]bool)
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			keys[OperationKey(method, path)] = true
+		}
+	}
+	return keys
+}
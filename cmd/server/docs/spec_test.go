@@ -0,0 +1,26 @@
+package docs
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpec(t *testing.T) {
+	doc := Spec()
+	assert.NoError(t, doc.Validate(context.Background()))
+
+	// Served as JSON, every reference must resolve against the components
+	// alone.
+	data, err := json.Marshal(doc)
+	assert.NoError(t, err)
+	loaded, err := openapi3.NewLoader().LoadFromData(data)
+	assert.NoError(t, err)
+	assert.NoError(t, loaded.Validate(context.Background()))
+
+	products := loaded.Components.Schemas["WarehouseProducts"].Value.Properties["products"]
+	assert.Equal(t, "#/components/schemas/Product", products.Value.Items.Ref)
+}
@@ -0,0 +1,97 @@
+package gql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"repository_class/internal/domain"
+	"repository_class/internal/product"
+	"repository_class/internal/warehouse"
+
+	"github.com/graph-gophers/dataloader/v7"
+)
+
+// batchWait is how long a loader collects keys before querying. Resolvers at
+// the same depth run within it, so their lookups share one query.
+const batchWait = 2 * time.Millisecond
+
+type loadersKey struct{}
+
+// loaders batch the lookups made while resolving one request. They cache
+// results, so they must not outlive it.
+type loaders struct {
+	warehouseByID        *dataloader.Loader[int, domain.Warehouse]
+	productsByWarehouses *dataloader.Loader[int, []domain.Product]
+}
+
+// WithLoaders returns a copy of ctx carrying fresh loaders backed by the
+// services. It must be called once per GraphQL request.
+func WithLoaders(ctx context.Context, products product.Service, warehouses warehouse.Service) context.Context {
+	l := &loaders{
+		warehouseByID: dataloader.NewBatchedLoader(
+			warehouseBatch(warehouses),
+			dataloader.WithWait[int, domain.Warehouse](batchWait),
+		),
+		productsByWarehouses: dataloader.NewBatchedLoader(
+			productsBatch(products),
+			dataloader.WithWait[int, []domain.Product](batchWait),
+		),
+	}
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+func warehouseBatch(s warehouse.Service) dataloader.BatchFunc[int, domain.Warehouse] {
+	return func(ctx context.Context, ids []int) []*dataloader.Result[domain.Warehouse] {
+		results := make([]*dataloader.Result[domain.Warehouse], len(ids))
+
+		warehouses, err := s.List(ctx, domain.WarehouseFilter{IDs: ids})
+		if err != nil {
+			for i := range results {
+				results[i] = &dataloader.Result[domain.Warehouse]{Error: err}
+			}
+			return results
+		}
+
+		byID := make(map[int]domain.Warehouse, len(warehouses))
+		for _, w := range warehouses {
+			byID[w.ID] = w
+		}
+		for i, id := range ids {
+			w, ok := byID[id]
+			if !ok {
+				results[i] = &dataloader.Result[domain.Warehouse]{Error: fmt.Errorf("%w: %d", warehouse.ErrNotFound, id)}
+				continue
+			}
+			results[i] = &dataloader.Result[domain.Warehouse]{Data: w}
+		}
+		return results
+	}
+}
+
+func productsBatch(s product.Service) dataloader.BatchFunc[int, []domain.Product] {
+	return func(ctx context.Context, warehouseIDs []int) []*dataloader.Result[[]domain.Product] {
+		results := make([]*dataloader.Result[[]domain.Product], len(warehouseIDs))
+
+		products, err := s.List(ctx, domain.ProductFilter{WarehouseIDs: warehouseIDs})
+		if err != nil {
+			for i := range results {
+				results[i] = &dataloader.Result[[]domain.Product]{Error: err}
+			}
+			return results
+		}
+
+		byWarehouse := make(map[int][]domain.Product, len(warehouseIDs))
+		for _, p := range products {
+			byWarehouse[p.IdWarehouse] = append(byWarehouse[p.IdWarehouse], p)
+		}
+		for i, id := range warehouseIDs {
+			results[i] = &dataloader.Result[[]domain.Product]{Data: byWarehouse[id]}
+		}
+		return results
+	}
+}
@@ -0,0 +1,245 @@
+package gql
+
+import (
+	"errors"
+
+	"repository_class/internal/domain"
+	"repository_class/internal/product"
+	"repository_class/internal/warehouse"
+
+	"github.com/graphql-go/graphql"
+	"github.com/shopspring/decimal"
+)
+
+// productPrice exposes the decimal price as a GraphQL Float, which has no
+// exact decimal type.
+func productPrice(p graphql.ResolveParams) (interface{}, error) {
+	return p.Source.(domain.Product).Price.InexactFloat64(), nil
+}
+
+func (r *resolver) product(p graphql.ResolveParams) (interface{}, error) {
+	prod, err := r.products.Get(p.Context, p.Args["id"].(int))
+	if errors.Is(err, product.ErrNotFound) {
+		return nil, nil
+	}
+	return prod, err
+}
+
+func (r *resolver) productByCode(p graphql.ResolveParams) (interface{}, error) {
+	prod, err := r.products.GetByCode(p.Context, p.Args["code"].(string))
+	if errors.Is(err, product.ErrNotFound) {
+		return nil, nil
+	}
+	return prod, err
+}
+
+func (r *resolver) productList(p graphql.ResolveParams) (interface{}, error) {
+	f := domain.ProductFilter{}
+	f.Limit, f.Offset = page(p.Args)
+	if v, ok := p.Args["published"].(bool); ok {
+		f.Published = &v
+	}
+	if v, ok := p.Args["warehouseId"].(int); ok {
+		f.WarehouseIDs = []int{v}
+	}
+	return r.products.List(p.Context, f)
+}
+
+// productWarehouse returns a thunk so the warehouses of every product in the
+// current list are fetched together.
+func (r *resolver) productWarehouse(p graphql.ResolveParams) (interface{}, error) {
+	thunk := loadersFrom(p.Context).warehouseByID.Load(p.Context, p.Source.(domain.Product).IdWarehouse)
+	return func() (interface{}, error) {
+		w, err := thunk()
+		if errors.Is(err, warehouse.ErrNotFound) {
+			return nil, nil
+		}
+		return w, err
+	}, nil
+}
+
+func (r *resolver) warehouse(p graphql.ResolveParams) (interface{}, error) {
+	w, err := r.warehouses.Get(p.Context, p.Args["id"].(int))
+	if err != nil {
+		return nil, err
+	}
+	if w == (domain.Warehouse{}) {
+		return nil, nil
+	}
+	return w, nil
+}
+
+func (r *resolver) warehouseList(p graphql.ResolveParams) (interface{}, error) {
+	f := domain.WarehouseFilter{}
+	f.Limit, f.Offset = page(p.Args)
+	return r.warehouses.List(p.Context, f)
+}
+
+// warehouseProducts pages the products of the warehouse in the repository
+// when limit or offset is given. Otherwise it returns all of them, loaded in
+// one query with those of every other warehouse in the current list.
+func (r *resolver) warehouseProducts(p graphql.ResolveParams) (interface{}, error) {
+	w := p.Source.(domain.Warehouse)
+	published, filterPublished := p.Args["published"].(bool)
+
+	_, hasLimit := p.Args["limit"]
+	_, hasOffset := p.Args["offset"]
+	if hasLimit || hasOffset {
+		f := domain.ProductFilter{WarehouseIDs: []int{w.ID}}
+		f.Limit, f.Offset = page(p.Args)
+		if filterPublished {
+			f.Published = &published
+		}
+		return r.products.List(p.Context, f)
+	}
+
+	thunk := loadersFrom(p.Context).productsByWarehouses.Load(p.Context, w.ID)
+	return func() (interface{}, error) {
+		all, err := thunk()
+		if err != nil {
+			return nil, err
+		}
+
+		products := []domain.Product{}
+		for _, prod := range all {
+			if filterPublished && prod.IsPublished != published {
+				continue
+			}
+			products = append(products, prod)
+		}
+		return products, nil
+	}, nil
+}
+
+// warehouseReport derives the report from the batched product load instead
+// of issuing one report query per warehouse.
+func (r *resolver) warehouseReport(p graphql.ResolveParams) (interface{}, error) {
+	w := p.Source.(domain.Warehouse)
+	thunk := loadersFrom(p.Context).productsByWarehouses.Load(p.Context, w.ID)
+
+	return func() (interface{}, error) {
+		products, err := thunk()
+		if err != nil {
+			return nil, err
+		}
+		return domain.WarehouseReport{WarehouseName: w.Name, ProductCount: len(products)}, nil
+	}, nil
+}
+
+func (r *resolver) createProduct(p graphql.ResolveParams) (interface{}, error) {
+	prod, err := productFromInput(p.Args["input"])
+	if err != nil {
+		return nil, err
+	}
+	return r.products.Create(p.Context, prod)
+}
+
+func (r *resolver) updateProduct(p graphql.ResolveParams) (interface{}, error) {
+	prod, err := productFromInput(p.Args["input"])
+	if err != nil {
+		return nil, err
+	}
+	return r.products.Update(p.Context, prod, p.Args["id"].(int))
+}
+
+func (r *resolver) deleteProduct(p graphql.ResolveParams) (interface{}, error) {
+	if err := r.products.Delete(p.Context, p.Args["id"].(int)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *resolver) createWarehouse(p graphql.ResolveParams) (interface{}, error) {
+	w := domain.Warehouse{}
+	if err := applyWarehouseInput(&w, p.Args["input"]); err != nil {
+		return nil, err
+	}
+	return r.warehouses.Create(p.Context, w)
+}
+
+func (r *resolver) updateWarehouse(p graphql.ResolveParams) (interface{}, error) {
+	id := p.Args["id"].(int)
+	w, err := r.warehouses.Get(p.Context, id)
+	if err != nil {
+		return nil, err
+	}
+	if w == (domain.Warehouse{}) {
+		return nil, warehouse.ErrNotFound
+	}
+	if err := applyWarehouseInput(&w, p.Args["input"]); err != nil {
+		return nil, err
+	}
+	return r.warehouses.Update(p.Context, w, id)
+}
+
+func (r *resolver) deleteWarehouse(p graphql.ResolveParams) (interface{}, error) {
+	if err := r.warehouses.Delete(p.Context, p.Args["id"].(int)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func productFromInput(v interface{}) (domain.Product, error) {
+	in, ok := v.(map[string]interface{})
+	if !ok {
+		return domain.Product{}, ErrInvalidInput
+	}
+
+	prod := domain.Product{}
+	if s, ok := in["name"].(string); ok {
+		prod.Name = s
+	}
+	if n, ok := in["quantity"].(int); ok {
+		prod.Quantity = n
+	}
+	if s, ok := in["code_value"].(string); ok {
+		prod.CodeValue = s
+	}
+	if b, ok := in["is_published"].(bool); ok {
+		prod.IsPublished = b
+	}
+	if raw, ok := in["expiration"]; ok && raw != nil {
+		t, ok := timeArg(raw)
+		if !ok {
+			return domain.Product{}, ErrInvalidInput
+		}
+		prod.Expiration = t
+	}
+	if f, ok := in["price"].(float64); ok {
+		prod.Price = decimal.NewFromFloat(f)
+	}
+	if s, ok := in["currency"].(string); ok {
+		prod.Currency = s
+	}
+	if n, ok := in["id_warehouse"].(int); ok {
+		prod.IdWarehouse = n
+	}
+	if n, ok := in["category_id"].(int); ok {
+		prod.CategoryID = &n
+	}
+	return prod, nil
+}
+
+func applyWarehouseInput(w *domain.Warehouse, v interface{}) error {
+	in, ok := v.(map[string]interface{})
+	if !ok {
+		return ErrInvalidInput
+	}
+
+	if s, ok := in["warehouse_code"].(string); ok {
+		w.WarehouseCode = s
+	}
+	if s, ok := in["name"].(string); ok {
+		w.Name = s
+	}
+	if s, ok := in["address"].(string); ok {
+		w.Address = s
+	}
+	if s, ok := in["telephone"].(string); ok {
+		w.Telephone = s
+	}
+	if n, ok := in["capacity"].(int); ok {
+		w.Capacity = n
+	}
+	return nil
+}
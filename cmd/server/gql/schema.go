@@ -0,0 +1,238 @@
+// Package gql exposes products and warehouses as a GraphQL schema. Object
+// fields use the same names as the JSON of the REST API; nested relations
+// are resolved through per-request loaders so a query costs one SQL
+// statement per level rather than one per parent.
+package gql
+
+import (
+	"errors"
+	"time"
+
+	"repository_class/internal/domain"
+	"repository_class/internal/product"
+	"repository_class/internal/warehouse"
+
+	"github.com/graphql-go/graphql"
+)
+
+// maxPageSize caps limit arguments so one query cannot load a whole table.
+const maxPageSize = 100
+
+var ErrInvalidInput = errors.New("invalid input")
+
+type resolver struct {
+	products   product.Service
+	warehouses warehouse.Service
+}
+
+// NewSchema builds the schema resolving against the given services.
+func NewSchema(products product.Service, warehouses warehouse.Service) (graphql.Schema, error) {
+	r := &resolver{products: products, warehouses: warehouses}
+
+	productType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Product",
+		Fields: graphql.Fields{
+			"id":           &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"name":         &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"quantity":     &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"code_value":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"is_published": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"expiration":   &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+			"price":        &graphql.Field{Type: graphql.NewNonNull(graphql.Float), Resolve: productPrice},
+			"currency":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"id_warehouse": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"category_id":  &graphql.Field{Type: graphql.Int},
+		},
+	})
+
+	reportType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "WarehouseReport",
+		Fields: graphql.Fields{
+			"warehouse_name": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"product_count":  &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+
+	warehouseType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Warehouse",
+		Fields: graphql.Fields{
+			"id":             &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"warehouse_code": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"name":           &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"address": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(domain.Warehouse).Address, nil
+				},
+			},
+			"telephone": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"capacity":  &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"products": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(productType))),
+				Args: graphql.FieldConfigArgument{
+					"published": &graphql.ArgumentConfig{Type: graphql.Boolean},
+					"limit":     &graphql.ArgumentConfig{Type: graphql.Int},
+					"offset":    &graphql.ArgumentConfig{Type: graphql.Int},
+				},
+				Resolve: r.warehouseProducts,
+			},
+			"report": &graphql.Field{
+				Type:    graphql.NewNonNull(reportType),
+				Resolve: r.warehouseReport,
+			},
+		},
+	})
+
+	productType.AddFieldConfig("warehouse", &graphql.Field{
+		Type:    warehouseType,
+		Resolve: r.productWarehouse,
+	})
+
+	productInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "ProductInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"name":         &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"quantity":     &graphql.InputObjectFieldConfig{Type: graphql.Int},
+			"code_value":   &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"is_published": &graphql.InputObjectFieldConfig{Type: graphql.Boolean},
+			"expiration":   &graphql.InputObjectFieldConfig{Type: graphql.DateTime},
+			"price":        &graphql.InputObjectFieldConfig{Type: graphql.Float},
+			"currency":     &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"id_warehouse": &graphql.InputObjectFieldConfig{Type: graphql.Int},
+			"category_id":  &graphql.InputObjectFieldConfig{Type: graphql.Int},
+		},
+	})
+
+	warehouseInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "WarehouseInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"warehouse_code": &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"name":           &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"address":        &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"telephone":      &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"capacity":       &graphql.InputObjectFieldConfig{Type: graphql.Int},
+		},
+	})
+
+	pageArgs := func(extra graphql.FieldConfigArgument) graphql.FieldConfigArgument {
+		args := graphql.FieldConfigArgument{
+			"limit":  &graphql.ArgumentConfig{Type: graphql.Int},
+			"offset": &graphql.ArgumentConfig{Type: graphql.Int},
+		}
+		for name, arg := range extra {
+			args[name] = arg
+		}
+		return args
+	}
+	idArg := graphql.FieldConfigArgument{
+		"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+	}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"product": &graphql.Field{
+				Type:    productType,
+				Args:    idArg,
+				Resolve: r.product,
+			},
+			"productByCode": &graphql.Field{
+				Type: productType,
+				Args: graphql.FieldConfigArgument{
+					"code": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: r.productByCode,
+			},
+			"products": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(productType))),
+				Args: pageArgs(graphql.FieldConfigArgument{
+					"published":   &graphql.ArgumentConfig{Type: graphql.Boolean},
+					"warehouseId": &graphql.ArgumentConfig{Type: graphql.Int},
+				}),
+				Resolve: r.productList,
+			},
+			"warehouse": &graphql.Field{
+				Type:    warehouseType,
+				Args:    idArg,
+				Resolve: r.warehouse,
+			},
+			"warehouses": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(warehouseType))),
+				Args:    pageArgs(nil),
+				Resolve: r.warehouseList,
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createProduct": &graphql.Field{
+				Type: productType,
+				Args: graphql.FieldConfigArgument{
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(productInput)},
+				},
+				Resolve: r.createProduct,
+			},
+			"updateProduct": &graphql.Field{
+				Type: productType,
+				Args: graphql.FieldConfigArgument{
+					"id":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(productInput)},
+				},
+				Resolve: r.updateProduct,
+			},
+			"deleteProduct": &graphql.Field{
+				Type:    graphql.Boolean,
+				Args:    idArg,
+				Resolve: r.deleteProduct,
+			},
+			"createWarehouse": &graphql.Field{
+				Type: warehouseType,
+				Args: graphql.FieldConfigArgument{
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(warehouseInput)},
+				},
+				Resolve: r.createWarehouse,
+			},
+			"updateWarehouse": &graphql.Field{
+				Type: warehouseType,
+				Args: graphql.FieldConfigArgument{
+					"id":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(warehouseInput)},
+				},
+				Resolve: r.updateWarehouse,
+			},
+			"deleteWarehouse": &graphql.Field{
+				Type:    graphql.Boolean,
+				Args:    idArg,
+				Resolve: r.deleteWarehouse,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+}
+
+// page reads the limit and offset arguments, capping limit at maxPageSize.
+func page(args map[string]interface{}) (limit, offset int) {
+	limit = maxPageSize
+	if v, ok := args["limit"].(int); ok && v > 0 && v < maxPageSize {
+		limit = v
+	}
+	if v, ok := args["offset"].(int); ok && v > 0 {
+		offset = v
+	}
+	return limit, offset
+}
+
+func timeArg(v interface{}) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, true
+	case *time.Time:
+		if t != nil {
+			return *t, true
+		}
+	}
+	return time.Time{}, false
+}
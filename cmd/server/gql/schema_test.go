@@ -0,0 +1,227 @@
+package gql
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"repository_class/internal/domain"
+	"repository_class/internal/product"
+	"repository_class/internal/warehouse"
+
+	"github.com/graphql-go/graphql"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeProducts serves products from memory and records the filters List was
+// called with. Methods the tests do not call panic through the nil embedded
+// Service.
+type fakeProducts struct {
+	product.Service
+	mu       sync.Mutex
+	products []domain.Product
+	lists    []domain.ProductFilter
+	created  []domain.Product
+}
+
+func (f *fakeProducts) List(ctx context.Context, filter domain.ProductFilter) ([]domain.Product, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lists = append(f.lists, filter)
+
+	in := make(map[int]bool)
+	for _, id := range filter.WarehouseIDs {
+		in[id] = true
+	}
+	products := []domain.Product{}
+	for _, p := range f.products {
+		if len(in) == 0 || in[p.IdWarehouse] {
+			products = append(products, p)
+		}
+	}
+	if filter.Limit > 0 && len(products) > filter.Limit {
+		products = products[:filter.Limit]
+	}
+	return products, nil
+}
+
+func (f *fakeProducts) Create(ctx context.Context, p domain.Product) (domain.Product, error) {
+	f.created = append(f.created, p)
+	p.ID = 100
+	return p, nil
+}
+
+func (f *fakeProducts) Delete(ctx context.Context, id int) error {
+	if id != 1 {
+		return product.ErrNotFound
+	}
+	return nil
+}
+
+// fakeWarehouses serves warehouses from memory and records the filters List
+// was called with. A missing warehouse is a zero value, as in the real
+// service.
+type fakeWarehouses struct {
+	warehouse.Service
+	mu         sync.Mutex
+	warehouses []domain.Warehouse
+	lists      []domain.WarehouseFilter
+	updated    []domain.Warehouse
+}
+
+func (f *fakeWarehouses) Get(ctx context.Context, id int) (domain.Warehouse, error) {
+	for _, w := range f.warehouses {
+		if w.ID == id {
+			return w, nil
+		}
+	}
+	return domain.Warehouse{}, nil
+}
+
+func (f *fakeWarehouses) List(ctx context.Context, filter domain.WarehouseFilter) ([]domain.Warehouse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lists = append(f.lists, filter)
+
+	in := make(map[int]bool)
+	for _, id := range filter.IDs {
+		in[id] = true
+	}
+	var warehouses []domain.Warehouse
+	for _, w := range f.warehouses {
+		if len(in) == 0 || in[w.ID] {
+			warehouses = append(warehouses, w)
+		}
+	}
+	return warehouses, nil
+}
+
+func (f *fakeWarehouses) Update(ctx context.Context, w domain.Warehouse, id int) (domain.Warehouse, error) {
+	f.updated = append(f.updated, w)
+	return w, nil
+}
+
+func newFakes() (*fakeProducts, *fakeWarehouses) {
+	products := &fakeProducts{products: []domain.Product{
+		{ID: 1, Name: "widget", IdWarehouse: 1},
+		{ID: 2, Name: "gadget", IdWarehouse: 2, IsPublished: true},
+		{ID: 3, Name: "gizmo", IdWarehouse: 2},
+	}}
+	warehouses := &fakeWarehouses{warehouses: []domain.Warehouse{
+		{ID: 1, Name: "north"},
+		{ID: 2, Name: "south"},
+		{ID: 3, Name: "empty"},
+	}}
+	return products, warehouses
+}
+
+// do runs the query against the schema as the GraphQL handler does and
+// returns its data as JSON.
+func do(t *testing.T, products product.Service, warehouses warehouse.Service, query string) string {
+	t.Helper()
+	schema, err := NewSchema(products, warehouses)
+	assert.NoError(t, err)
+
+	result := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: query,
+		Context:       WithLoaders(context.Background(), products, warehouses),
+	})
+	assert.Empty(t, result.Errors)
+	data, err := json.Marshal(result.Data)
+	assert.NoError(t, err)
+	return string(data)
+}
+
+func TestNestedQueryBatchesEachLevel(t *testing.T) {
+	products, warehouses := newFakes()
+
+	data := do(t, products, warehouses, `{
+		products { name warehouse { name products { name } } }
+	}`)
+	assert.JSONEq(t, `{"products": [
+		{"name": "widget", "warehouse": {"name": "north", "products": [{"name": "widget"}]}},
+		{"name": "gadget", "warehouse": {"name": "south", "products": [{"name": "gadget"}, {"name": "gizmo"}]}},
+		{"name": "gizmo", "warehouse": {"name": "south", "products": [{"name": "gadget"}, {"name": "gizmo"}]}}
+	]}`, data)
+
+	// One List for the products, one for their warehouses and one for the
+	// products of those warehouses.
+	if assert.Len(t, warehouses.lists, 1) {
+		assert.ElementsMatch(t, []int{1, 2}, warehouses.lists[0].IDs)
+	}
+	if assert.Len(t, products.lists, 2) {
+		assert.Empty(t, products.lists[0].WarehouseIDs)
+		assert.ElementsMatch(t, []int{1, 2}, products.lists[1].WarehouseIDs)
+	}
+}
+
+func TestWarehouseProducts(t *testing.T) {
+	products, warehouses := newFakes()
+
+	data := do(t, products, warehouses, `{
+		warehouses {
+			name
+			products { name }
+			published: products(published: true) { name }
+			report { product_count }
+		}
+	}`)
+	assert.JSONEq(t, `{"warehouses": [
+		{"name": "north", "products": [{"name": "widget"}], "published": [], "report": {"product_count": 1}},
+		{"name": "south", "products": [{"name": "gadget"}, {"name": "gizmo"}], "published": [{"name": "gadget"}], "report": {"product_count": 2}},
+		{"name": "empty", "products": [], "published": [], "report": {"product_count": 0}}
+	]}`, data)
+
+	// The products, reports and filtered products of every warehouse share
+	// one List.
+	assert.Len(t, warehouses.lists, 1)
+	if assert.Len(t, products.lists, 1) {
+		assert.ElementsMatch(t, []int{1, 2, 3}, products.lists[0].WarehouseIDs)
+	}
+
+	// Paging queries the products of each warehouse on its own.
+	products.lists = nil
+	data = do(t, products, warehouses, `{ warehouse(id: 2) { products(limit: 1) { name } } }`)
+	assert.JSONEq(t, `{"warehouse": {"products": [{"name": "gadget"}]}}`, data)
+	if assert.Len(t, products.lists, 1) {
+		assert.Equal(t, []int{2}, products.lists[0].WarehouseIDs)
+		assert.Equal(t, 1, products.lists[0].Limit)
+	}
+}
+
+func TestMutations(t *testing.T) {
+	products, warehouses := newFakes()
+
+	data := do(t, products, warehouses, `mutation {
+		createProduct(input: {name: "sprocket", code_value: "S-1", price: 2.5, id_warehouse: 1}) { id name price }
+	}`)
+	assert.JSONEq(t, `{"createProduct": {"id": 100, "name": "sprocket", "price": 2.5}}`, data)
+	if assert.Len(t, products.created, 1) {
+		assert.Equal(t, "S-1", products.created[0].CodeValue)
+		assert.Equal(t, 1, products.created[0].IdWarehouse)
+	}
+
+	data = do(t, products, warehouses, `mutation { updateWarehouse(id: 2, input: {capacity: 50}) { name capacity } }`)
+	assert.JSONEq(t, `{"updateWarehouse": {"name": "south", "capacity": 50}}`, data)
+	if assert.Len(t, warehouses.updated, 1) {
+		assert.Equal(t, domain.Warehouse{ID: 2, Name: "south", Capacity: 50}, warehouses.updated[0])
+	}
+
+	data = do(t, products, warehouses, `mutation { deleteProduct(id: 1) }`)
+	assert.JSONEq(t, `{"deleteProduct": true}`, data)
+
+	// Service errors are reported in the result.
+	schema, err := NewSchema(products, warehouses)
+	assert.NoError(t, err)
+	result := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: `mutation { deleteProduct(id: 2) updateWarehouse(id: 9, input: {}) { name } }`,
+		Context:       WithLoaders(context.Background(), products, warehouses),
+	})
+	if assert.Len(t, result.Errors, 2) {
+		assert.Equal(t, product.ErrNotFound.Error(), result.Errors[0].Message)
+		assert.Equal(t, warehouse.ErrNotFound.Error(), result.Errors[1].Message)
+	}
+}
@@ -0,0 +1,71 @@
+package grpcapi
+
+import (
+	inventoryv1 "repository_class/api/proto/inventory/v1"
+	"repository_class/internal/domain"
+
+	"github.com/shopspring/decimal"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func productToProto(p domain.Product) *inventoryv1.Product {
+	pb := &inventoryv1.Product{
+		Id:          int64(p.ID),
+		Name:        p.Name,
+		Quantity:    int32(p.Quantity),
+		CodeValue:   p.CodeValue,
+		IsPublished: p.IsPublished,
+		Expiration:  timestamppb.New(p.Expiration),
+		Price:       p.Price.InexactFloat64(),
+		Currency:    p.Currency,
+		WarehouseId: int64(p.IdWarehouse),
+	}
+	if p.CategoryID != nil {
+		id := int64(*p.CategoryID)
+		pb.CategoryId = &id
+	}
+	return pb
+}
+
+func productFromProto(p *inventoryv1.Product) domain.Product {
+	prod := domain.Product{
+		ID:          int(p.GetId()),
+		Name:        p.GetName(),
+		Quantity:    int(p.GetQuantity()),
+		CodeValue:   p.GetCodeValue(),
+		IsPublished: p.GetIsPublished(),
+		Price:       decimal.NewFromFloat(p.GetPrice()),
+		Currency:    p.GetCurrency(),
+		IdWarehouse: int(p.GetWarehouseId()),
+	}
+	if p.GetExpiration() != nil {
+		prod.Expiration = p.GetExpiration().AsTime()
+	}
+	if p.CategoryId != nil {
+		id := int(p.GetCategoryId())
+		prod.CategoryID = &id
+	}
+	return prod
+}
+
+func warehouseToProto(w domain.Warehouse) *inventoryv1.Warehouse {
+	return &inventoryv1.Warehouse{
+		Id:            int64(w.ID),
+		Name:          w.Name,
+		Address:       w.Address,
+		Telephone:     w.Telephone,
+		Capacity:      int32(w.Capacity),
+		WarehouseCode: w.WarehouseCode,
+	}
+}
+
+func warehouseFromProto(w *inventoryv1.Warehouse) domain.Warehouse {
+	return domain.Warehouse{
+		ID:            int(w.GetId()),
+		WarehouseCode: w.GetWarehouseCode(),
+		Name:          w.GetName(),
+		Address:       w.GetAddress(),
+		Telephone:     w.GetTelephone(),
+		Capacity:      int(w.GetCapacity()),
+	}
+}
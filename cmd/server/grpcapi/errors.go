@@ -0,0 +1,44 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+
+	"repository_class/internal/lot"
+	"repository_class/internal/product"
+	"repository_class/internal/warehouse"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// toStatus maps service errors to gRPC status codes, as the REST handlers
+// map them to HTTP statuses. Unknown errors are reported as Internal without
+// leaking their message.
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	switch {
+	case errors.Is(err, product.ErrNotFound), errors.Is(err, warehouse.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, product.ErrProductRegistered), errors.Is(err, product.ErrUniqueProduct),
+		errors.Is(err, warehouse.ErrWarehouseRegistered):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, lot.ErrInsufficientStock):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, product.ErrInvalidStruct), errors.Is(err, product.ErrCodeMismatch),
+		errors.Is(err, product.ErrCategoryNotFound), errors.Is(err, product.ErrWarehouseNotFound),
+		errors.Is(err, product.ErrInvalidCurrency), errors.Is(err, product.ErrStockNotReceived), errors.Is(err, lot.ErrInvalidQuantity),
+		errors.Is(err, lot.ErrInvalidExpiration),
+		errors.Is(err, warehouse.ErrInvalidStruct), errors.Is(err, warehouse.ErrInvalidId):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
@@ -0,0 +1,99 @@
+package grpcapi
+
+import (
+	"context"
+
+	inventoryv1 "repository_class/api/proto/inventory/v1"
+	"repository_class/internal/domain"
+	"repository_class/internal/product"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type productServer struct {
+	inventoryv1.UnimplementedProductServiceServer
+	service product.Service
+}
+
+func (s *productServer) GetProduct(ctx context.Context, req *inventoryv1.GetProductRequest) (*inventoryv1.Product, error) {
+	p, err := s.service.Get(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return productToProto(p), nil
+}
+
+func (s *productServer) GetProductByCode(ctx context.Context, req *inventoryv1.GetProductByCodeRequest) (*inventoryv1.Product, error) {
+	p, err := s.service.GetByCode(ctx, req.GetCodeValue())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return productToProto(p), nil
+}
+
+func (s *productServer) GetProductWithWarehouse(ctx context.Context, req *inventoryv1.GetProductRequest) (*inventoryv1.ProductWithWarehouse, error) {
+	p, err := s.service.GetWithWarehouse(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &inventoryv1.ProductWithWarehouse{
+		Product:   productToProto(p.Product),
+		Warehouse: warehouseToProto(p.Warehouse),
+	}, nil
+}
+
+// ListProducts reads the products page by page so large listings are not
+// held in memory while they are streamed.
+func (s *productServer) ListProducts(req *inventoryv1.ListProductsRequest, stream inventoryv1.ProductService_ListProductsServer) error {
+	f := domain.ProductFilter{Published: req.Published, Limit: streamPageSize}
+	for _, id := range req.GetWarehouseIds() {
+		f.WarehouseIDs = append(f.WarehouseIDs, int(id))
+	}
+
+	for {
+		products, err := s.service.List(stream.Context(), f)
+		if err != nil {
+			return toStatus(err)
+		}
+		for _, p := range products {
+			if err := stream.Send(productToProto(p)); err != nil {
+				return err
+			}
+		}
+		if len(products) < f.Limit {
+			return nil
+		}
+		f.Offset += f.Limit
+	}
+}
+
+func (s *productServer) CreateProduct(ctx context.Context, req *inventoryv1.CreateProductRequest) (*inventoryv1.Product, error) {
+	p, err := s.service.Create(ctx, productFromProto(req.GetProduct()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return productToProto(p), nil
+}
+
+func (s *productServer) UpdateProduct(ctx context.Context, req *inventoryv1.UpdateProductRequest) (*inventoryv1.Product, error) {
+	p, err := s.service.Update(ctx, productFromProto(req.GetProduct()), int(req.GetId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return productToProto(p), nil
+}
+
+func (s *productServer) UpsertProduct(ctx context.Context, req *inventoryv1.UpsertProductRequest) (*inventoryv1.UpsertProductResponse, error) {
+	p, created, err := s.service.Upsert(ctx, req.GetCodeValue(), productFromProto(req.GetProduct()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &inventoryv1.UpsertProductResponse{Product: productToProto(p), Created: created}, nil
+}
+
+func (s *productServer) DeleteProduct(ctx context.Context, req *inventoryv1.DeleteProductRequest) (*emptypb.Empty, error) {
+	if err := s.service.Delete(ctx, int(req.GetId())); err != nil {
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
@@ -0,0 +1,36 @@
+// Package grpcapi serves the product and warehouse services over gRPC,
+// next to the REST handlers and sharing the same service instances.
+package grpcapi
+
+import (
+	inventoryv1 "repository_class/api/proto/inventory/v1"
+	"repository_class/internal/product"
+	"repository_class/internal/warehouse"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// streamPageSize is how many rows list RPCs read per query while streaming.
+const streamPageSize = 100
+
+// NewServer returns a gRPC server with the product, warehouse, health and
+// reflection services registered. Calls that name no tenant act for
+// defaultTenant, and are rejected when it is empty.
+func NewServer(products product.Service, warehouses warehouse.Service, defaultTenant string) *grpc.Server {
+	srv := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(tenantUnaryInterceptor(defaultTenant)),
+		grpc.ChainStreamInterceptor(tenantStreamInterceptor(defaultTenant)),
+	)
+
+	inventoryv1.RegisterProductServiceServer(srv, &productServer{service: products})
+	inventoryv1.RegisterWarehouseServiceServer(srv, &warehouseServer{service: warehouses})
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	reflection.Register(srv)
+
+	return srv
+}
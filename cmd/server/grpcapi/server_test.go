@@ -0,0 +1,220 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	inventoryv1 "repository_class/api/proto/inventory/v1"
+	"repository_class/internal/domain"
+	"repository_class/internal/lot"
+	"repository_class/internal/product"
+	"repository_class/internal/warehouse"
+	"repository_class/pkg/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeProducts serves the products of each tenant from memory. Methods the
+// tests do not call panic through the nil embedded Service.
+type fakeProducts struct {
+	product.Service
+	products map[string][]domain.Product
+	err      error
+}
+
+func (f *fakeProducts) Get(ctx context.Context, id int) (domain.Product, error) {
+	if f.err != nil {
+		return domain.Product{}, f.err
+	}
+	tenantID, _ := tenant.FromContext(ctx)
+	for _, p := range f.products[tenantID] {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	return domain.Product{}, product.ErrNotFound
+}
+
+func (f *fakeProducts) List(ctx context.Context, filter domain.ProductFilter) ([]domain.Product, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	tenantID, _ := tenant.FromContext(ctx)
+	products := f.products[tenantID]
+	if filter.Offset >= len(products) {
+		return nil, nil
+	}
+	products = products[filter.Offset:]
+	if len(products) > filter.Limit {
+		products = products[:filter.Limit]
+	}
+	return products, nil
+}
+
+// fakeWarehouses reports a missing warehouse as a zero value, as the real
+// service does.
+type fakeWarehouses struct {
+	warehouse.Service
+	warehouses []domain.Warehouse
+}
+
+func (f *fakeWarehouses) Get(ctx context.Context, id int) (domain.Warehouse, error) {
+	for _, w := range f.warehouses {
+		if w.ID == id {
+			return w, nil
+		}
+	}
+	return domain.Warehouse{}, nil
+}
+
+func (f *fakeWarehouses) List(ctx context.Context, filter domain.WarehouseFilter) ([]domain.Warehouse, error) {
+	if filter.Offset >= len(f.warehouses) {
+		return nil, nil
+	}
+	return f.warehouses[filter.Offset:], nil
+}
+
+// dial serves NewServer over an in-memory listener and returns a connection
+// to it.
+func dial(t *testing.T, products product.Service, warehouses warehouse.Service) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := NewServer(products, warehouses, "acme")
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestGetProduct(t *testing.T) {
+	products := &fakeProducts{products: map[string][]domain.Product{
+		"acme":   {{ID: 1, Name: "widget", CodeValue: "W-1", Currency: "USD"}},
+		"globex": {{ID: 2, Name: "gadget", CodeValue: "G-1", Currency: "USD"}},
+	}}
+	client := inventoryv1.NewProductServiceClient(dial(t, products, &fakeWarehouses{}))
+	ctx := context.Background()
+
+	// Calls without x-tenant-id act for the default tenant.
+	p, err := client.GetProduct(ctx, &inventoryv1.GetProductRequest{Id: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, "widget", p.GetName())
+	assert.Equal(t, "W-1", p.GetCodeValue())
+
+	globex := metadata.AppendToOutgoingContext(ctx, tenantMetadataKey, "globex")
+	p, err = client.GetProduct(globex, &inventoryv1.GetProductRequest{Id: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, "gadget", p.GetName())
+
+	_, err = client.GetProduct(globex, &inventoryv1.GetProductRequest{Id: 1})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestListProducts(t *testing.T) {
+	var acme []domain.Product
+	for i := 1; i <= streamPageSize+5; i++ {
+		acme = append(acme, domain.Product{ID: i, Name: "widget", Currency: "USD"})
+	}
+	products := &fakeProducts{products: map[string][]domain.Product{"acme": acme}}
+	client := inventoryv1.NewProductServiceClient(dial(t, products, &fakeWarehouses{}))
+
+	stream, err := client.ListProducts(context.Background(), &inventoryv1.ListProductsRequest{})
+	assert.NoError(t, err)
+
+	var ids []int64
+	for {
+		p, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		ids = append(ids, p.GetId())
+	}
+	assert.Len(t, ids, streamPageSize+5)
+	assert.Equal(t, int64(streamPageSize+5), ids[len(ids)-1])
+}
+
+func TestGetAndListWarehouses(t *testing.T) {
+	warehouses := &fakeWarehouses{warehouses: []domain.Warehouse{
+		{ID: 1, Name: "north", WarehouseCode: "N"},
+		{ID: 2, Name: "south", WarehouseCode: "S"},
+	}}
+	client := inventoryv1.NewWarehouseServiceClient(dial(t, &fakeProducts{}, warehouses))
+	ctx := context.Background()
+
+	w, err := client.GetWarehouse(ctx, &inventoryv1.GetWarehouseRequest{Id: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, "south", w.GetName())
+	assert.Equal(t, "S", w.GetWarehouseCode())
+
+	_, err = client.GetWarehouse(ctx, &inventoryv1.GetWarehouseRequest{Id: 3})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	stream, err := client.ListWarehouses(ctx, &inventoryv1.ListWarehousesRequest{})
+	assert.NoError(t, err)
+	var names []string
+	for {
+		w, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		names = append(names, w.GetName())
+	}
+	assert.Equal(t, []string{"north", "south"}, names)
+}
+
+func TestErrorCodes(t *testing.T) {
+	tests := []struct {
+		err  error
+		code codes.Code
+		msg  string
+	}{
+		{product.ErrNotFound, codes.NotFound, product.ErrNotFound.Error()},
+		{product.ErrUniqueProduct, codes.AlreadyExists, product.ErrUniqueProduct.Error()},
+		{product.ErrInvalidStruct, codes.InvalidArgument, product.ErrInvalidStruct.Error()},
+		{lot.ErrInsufficientStock, codes.FailedPrecondition, lot.ErrInsufficientStock.Error()},
+		{lot.ErrInvalidQuantity, codes.InvalidArgument, lot.ErrInvalidQuantity.Error()},
+		{context.DeadlineExceeded, codes.DeadlineExceeded, context.DeadlineExceeded.Error()},
+		{errors.New("connection refused"), codes.Internal, "internal server error"},
+	}
+
+	products := &fakeProducts{}
+	conn := dial(t, products, &fakeWarehouses{})
+	client := inventoryv1.NewProductServiceClient(conn)
+	for _, test := range tests {
+		products.err = test.err
+
+		_, err := client.GetProduct(context.Background(), &inventoryv1.GetProductRequest{Id: 1})
+		st, _ := status.FromError(err)
+		assert.Equal(t, test.code, st.Code(), test.err.Error())
+		assert.Equal(t, test.msg, st.Message())
+
+		stream, err := client.ListProducts(context.Background(), &inventoryv1.ListProductsRequest{})
+		assert.NoError(t, err)
+		_, err = stream.Recv()
+		assert.Equal(t, test.code, status.Code(err), test.err.Error())
+	}
+
+	// A call naming an invalid tenant never reaches the service.
+	products.err = nil
+	ctx := metadata.AppendToOutgoingContext(context.Background(), tenantMetadataKey, "not a tenant!")
+	_, err := client.GetProduct(ctx, &inventoryv1.GetProductRequest{Id: 1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,80 @@
+package grpcapi
+
+import (
+	"context"
+	"strings"
+
+	"repository_class/pkg/tenant"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// tenantMetadataKey is the metadata key a client names its tenant with, the
+// gRPC form of the X-Tenant-ID header.
+const tenantMetadataKey = "x-tenant-id"
+
+// tenantScoped reports whether the method serves tenant data; health checks
+// and reflection do not need a tenant.
+func tenantScoped(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, "/inventory.v1.")
+}
+
+// withTenant resolves the tenant of a call as the REST middleware does: the
+// one already in ctx, then the x-tenant-id metadata, then fallback.
+func withTenant(ctx context.Context, fallback string) (context.Context, error) {
+	id, ok := tenant.FromContext(ctx)
+	if !ok {
+		if values := metadata.ValueFromIncomingContext(ctx, tenantMetadataKey); len(values) > 0 {
+			id = values[0]
+		}
+	}
+	if id == "" {
+		id = fallback
+	}
+	switch {
+	case id == "":
+		return nil, status.Error(codes.InvalidArgument, tenant.ErrMissing.Error())
+	case !tenant.Valid(id):
+		return nil, status.Error(codes.InvalidArgument, tenant.ErrInvalid.Error())
+	}
+	return tenant.WithContext(ctx, id), nil
+}
+
+func tenantUnaryInterceptor(fallback string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !tenantScoped(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		ctx, err := withTenant(ctx, fallback)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func tenantStreamInterceptor(fallback string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !tenantScoped(info.FullMethod) {
+			return handler(srv, ss)
+		}
+		ctx, err := withTenant(ss.Context(), fallback)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &tenantStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// tenantStream is a server stream whose context carries the tenant.
+type tenantStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *tenantStream) Context() context.Context {
+	return s.ctx
+}
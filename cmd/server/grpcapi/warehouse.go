@@ -0,0 +1,94 @@
+package grpcapi
+
+import (
+	"context"
+
+	inventoryv1 "repository_class/api/proto/inventory/v1"
+	"repository_class/internal/domain"
+	"repository_class/internal/warehouse"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type warehouseServer struct {
+	inventoryv1.UnimplementedWarehouseServiceServer
+	service warehouse.Service
+}
+
+// get wraps Service.Get, which reports a missing warehouse as a zero value.
+func (s *warehouseServer) get(ctx context.Context, id int) (domain.Warehouse, error) {
+	w, err := s.service.Get(ctx, id)
+	if err != nil {
+		return domain.Warehouse{}, err
+	}
+	if w == (domain.Warehouse{}) {
+		return domain.Warehouse{}, warehouse.ErrNotFound
+	}
+	return w, nil
+}
+
+func (s *warehouseServer) GetWarehouse(ctx context.Context, req *inventoryv1.GetWarehouseRequest) (*inventoryv1.Warehouse, error) {
+	w, err := s.get(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return warehouseToProto(w), nil
+}
+
+func (s *warehouseServer) ListWarehouses(_ *inventoryv1.ListWarehousesRequest, stream inventoryv1.WarehouseService_ListWarehousesServer) error {
+	f := domain.WarehouseFilter{Limit: streamPageSize}
+
+	for {
+		warehouses, err := s.service.List(stream.Context(), f)
+		if err != nil {
+			return toStatus(err)
+		}
+		for _, w := range warehouses {
+			if err := stream.Send(warehouseToProto(w)); err != nil {
+				return err
+			}
+		}
+		if len(warehouses) < f.Limit {
+			return nil
+		}
+		f.Offset += f.Limit
+	}
+}
+
+func (s *warehouseServer) CreateWarehouse(ctx context.Context, req *inventoryv1.CreateWarehouseRequest) (*inventoryv1.Warehouse, error) {
+	w, err := s.service.Create(ctx, warehouseFromProto(req.GetWarehouse()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return warehouseToProto(w), nil
+}
+
+func (s *warehouseServer) UpdateWarehouse(ctx context.Context, req *inventoryv1.UpdateWarehouseRequest) (*inventoryv1.Warehouse, error) {
+	id := int(req.GetId())
+	w := warehouseFromProto(req.GetWarehouse())
+	w.ID = id
+
+	w, err := s.service.Update(ctx, w, id)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return warehouseToProto(w), nil
+}
+
+func (s *warehouseServer) DeleteWarehouse(ctx context.Context, req *inventoryv1.DeleteWarehouseRequest) (*emptypb.Empty, error) {
+	if err := s.service.Delete(ctx, int(req.GetId())); err != nil {
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *warehouseServer) ReportProducts(ctx context.Context, req *inventoryv1.ReportProductsRequest) (*inventoryv1.WarehouseReport, error) {
+	r, err := s.service.ReportProducts(ctx, int(req.GetWarehouseId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &inventoryv1.WarehouseReport{
+		WarehouseName: r.WarehouseName,
+		ProductCount:  int32(r.ProductCount),
+	}, nil
+}
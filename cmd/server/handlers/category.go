@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"repository_class/internal/category"
+	"repository_class/internal/domain"
+	"repository_class/pkg/web"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Category struct {
+	service category.Service
+}
+
+func NewCategory(c category.Service) *Category {
+	return &Category{
+		service: c,
+	}
+}
+
+func (cat *Category) GetAll() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		categories, err := cat.service.GetAll(c)
+		if err != nil {
+			web.Error(c, http.StatusInternalServerError, "%s", ErrProductInternalServer.Error())
+			return
+		}
+		web.Success(c, http.StatusOK, categories)
+	}
+}
+
+// Tree returns the root categories with their subcategories nested.
+func (cat *Category) Tree() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tree, err := cat.service.Tree(c)
+		if err != nil {
+			web.Error(c, http.StatusInternalServerError, "%s", ErrProductInternalServer.Error())
+			return
+		}
+		web.Success(c, http.StatusOK, tree)
+	}
+}
+
+// Report aggregates products by category, restricted to one warehouse when
+// warehouse_id is given.
+func (cat *Category) Report() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var warehouseID *int
+		if v := c.Query("warehouse_id"); v != "" {
+			id, err := strconv.Atoi(v)
+			if err != nil {
+				web.Error(c, http.StatusBadRequest, "%s", err.Error())
+				return
+			}
+			warehouseID = &id
+		}
+		report, err := cat.service.Report(c, warehouseID)
+		if err != nil {
+			web.Error(c, http.StatusInternalServerError, "%s", ErrProductInternalServer.Error())
+			return
+		}
+		web.Success(c, http.StatusOK, report)
+	}
+}
+
+func (cat *Category) Get() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		ct, err := cat.service.Get(c, id)
+		if err != nil {
+			if errors.Is(err, category.ErrNotFound) {
+				web.Error(c, http.StatusNotFound, "%s", err.Error())
+				return
+			}
+			web.Error(c, http.StatusInternalServerError, "%s", ErrProductInternalServer.Error())
+			return
+		}
+		web.Success(c, http.StatusOK, ct)
+	}
+}
+
+func (cat *Category) Create() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ct domain.Category
+		if err := c.ShouldBindJSON(&ct); err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		created, err := cat.service.Create(c, ct)
+		if err != nil {
+			if errors.Is(err, category.ErrInvalidStruct) || errors.Is(err, category.ErrParentNotFound) {
+				web.Error(c, http.StatusUnprocessableEntity, "%s", err.Error())
+				return
+			}
+			web.Error(c, http.StatusInternalServerError, "%s", ErrProductInternalServer.Error())
+			return
+		}
+		web.Success(c, http.StatusCreated, created)
+	}
+}
+
+func (cat *Category) Update() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		var ct domain.Category
+		if err := c.ShouldBindJSON(&ct); err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		updated, err := cat.service.Update(c, ct, id)
+		if err != nil {
+			if errors.Is(err, category.ErrNotFound) {
+				web.Error(c, http.StatusNotFound, "%s", err.Error())
+				return
+			} else if errors.Is(err, category.ErrParentNotFound) || errors.Is(err, category.ErrCycle) {
+				web.Error(c, http.StatusUnprocessableEntity, "%s", err.Error())
+				return
+			}
+			web.Error(c, http.StatusInternalServerError, "%s", ErrProductInternalServer.Error())
+			return
+		}
+		web.Success(c, http.StatusOK, updated)
+	}
+}
+
+func (cat *Category) Delete() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		err = cat.service.Delete(c, id)
+		if err != nil {
+			if errors.Is(err, category.ErrNotFound) {
+				web.Error(c, http.StatusNotFound, "%s", err.Error())
+				return
+			} else if errors.Is(err, category.ErrInUse) {
+				web.Error(c, http.StatusConflict, "%s", err.Error())
+				return
+			}
+			web.Error(c, http.StatusInternalServerError, "%s", ErrProductInternalServer.Error())
+			return
+		}
+		web.Success(c, http.StatusNoContent, nil)
+	}
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"repository_class/internal/costing"
+	"repository_class/internal/exchange"
+	"repository_class/internal/warehouse"
+	"repository_class/pkg/web"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Costing struct {
+	service costing.Service
+}
+
+func NewCosting(s costing.Service) *Costing {
+	return &Costing{
+		service: s,
+	}
+}
+
+func costingError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, costing.ErrProductNotFound), errors.Is(err, costing.ErrWarehouseNotFound):
+		web.Error(c, http.StatusNotFound, "%s", err.Error())
+	case errors.Is(err, costing.ErrInvalidMethod), errors.Is(err, exchange.ErrUnknownCurrency), errors.Is(err, exchange.ErrNoRate):
+		web.Error(c, http.StatusUnprocessableEntity, "%s", err.Error())
+	default:
+		web.Error(c, http.StatusInternalServerError, "%s", ErrProductInternalServer.Error())
+	}
+}
+
+// costingAt reads the at query parameter, now when it is missing.
+func costingAt(c *gin.Context) (time.Time, error) {
+	v := c.Query("at")
+	if v == "" {
+		return time.Now(), nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
+
+// Product values the stock of the product in the path at cost, with the
+// method, at and currency query parameters.
+func (h *Costing) Product() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		at, err := costingAt(c)
+		if err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		p, err := h.service.Product(c, id, c.Query("method"), at, c.Query("currency"))
+		if err != nil {
+			costingError(c, err)
+			return
+		}
+		web.Success(c, http.StatusOK, p)
+	}
+}
+
+// ReportValuation extends the products report of the warehouse in the id
+// query parameter with its stock valued at cost.
+func (h *Costing) ReportValuation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Query("id"))
+		if err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", warehouse.ErrInvalidId.Error())
+			return
+		}
+		at, err := costingAt(c)
+		if err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		report, err := h.service.Warehouse(c, id, c.Query("method"), at, c.Query("currency"))
+		if err != nil {
+			costingError(c, err)
+			return
+		}
+		web.Success(c, http.StatusOK, report)
+	}
+}
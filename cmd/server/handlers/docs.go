@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+)
+
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Inventory API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "/openapi.json", dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>`
+
+// Docs serves the OpenAPI document and a Swagger UI page rendering it.
+type Docs struct {
+	spec *openapi3.T
+}
+
+func NewDocs(spec *openapi3.T) *Docs {
+	return &Docs{
+		spec: spec,
+	}
+}
+
+func (d *Docs) Spec() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, d.spec)
+	}
+}
+
+func (d *Docs) UI() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUIPage))
+	}
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"repository_class/internal/exchange"
+	"repository_class/pkg/web"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+type Exchange struct {
+	service exchange.Service
+}
+
+func NewExchange(e exchange.Service) *Exchange {
+	return &Exchange{
+		service: e,
+	}
+}
+
+// exchangeRateRequest is the body of a manually set rate: what one unit of
+// the base currency buys in the currency of the path.
+type exchangeRateRequest struct {
+	Rate decimal.Decimal `json:"rate"`
+}
+
+func exchangeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, exchange.ErrNotFound):
+		web.Error(c, http.StatusNotFound, "%s", err.Error())
+	case errors.Is(err, exchange.ErrUnknownCurrency), errors.Is(err, exchange.ErrInvalidRate), errors.Is(err, exchange.ErrBaseCurrency):
+		web.Error(c, http.StatusUnprocessableEntity, "%s", err.Error())
+	default:
+		web.Error(c, http.StatusInternalServerError, "%s", ErrProductInternalServer.Error())
+	}
+}
+
+// List returns every exchange rate, manual and loaded from the rates file.
+func (e *Exchange) List() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rates, err := e.service.List(c)
+		if err != nil {
+			exchangeError(c, err)
+			return
+		}
+		web.Success(c, http.StatusOK, rates)
+	}
+}
+
+// Set stores the rate of the currency in the path by hand.
+func (e *Exchange) Set() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req exchangeRateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		rate, err := e.service.Set(c, c.Param("currency"), req.Rate)
+		if err != nil {
+			exchangeError(c, err)
+			return
+		}
+		web.Success(c, http.StatusOK, rate)
+	}
+}
+
+func (e *Exchange) Delete() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := e.service.Delete(c, c.Param("currency")); err != nil {
+			exchangeError(c, err)
+			return
+		}
+		web.Success(c, http.StatusNoContent, nil)
+	}
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http"
+
+	"repository_class/cmd/server/gql"
+	"repository_class/internal/product"
+	"repository_class/internal/warehouse"
+	"repository_class/pkg/web"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+)
+
+type graphQLRequest struct {
+	Query         string                 `json:"query" binding:"required"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// GraphQL executes queries and mutations against the product and warehouse
+// services.
+type GraphQL struct {
+	schema     graphql.Schema
+	products   product.Service
+	warehouses warehouse.Service
+}
+
+func NewGraphQL(schema graphql.Schema, p product.Service, w warehouse.Service) *GraphQL {
+	return &GraphQL{
+		schema:     schema,
+		products:   p,
+		warehouses: w,
+	}
+}
+
+// Execute answers with the standard {"data": ..., "errors": [...]} GraphQL
+// result; resolver errors are reported there with a 200 status.
+func (g *GraphQL) Execute() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req graphQLRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+
+		result := graphql.Do(graphql.Params{
+			Schema:         g.schema,
+			RequestString:  req.Query,
+			OperationName:  req.OperationName,
+			VariableValues: req.Variables,
+			Context:        gql.WithLoaders(c, g.products, g.warehouses),
+		})
+
+		web.Response(c, http.StatusOK, result)
+	}
+}
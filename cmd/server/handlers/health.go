@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"repository_class/db/migrations"
+	"repository_class/pkg/logger"
+	"repository_class/pkg/web"
+
+	"github.com/gin-gonic/gin"
+)
+
+const readinessTimeout = 2 * time.Second
+
+// Health serves the liveness and readiness probes. Once shuttingDown is set
+// the service reports itself not ready, so load balancers stop sending it
+// requests while it drains the ones in flight.
+type Health struct {
+	db           *sql.DB
+	shuttingDown *atomic.Bool
+}
+
+// NewHealth returns the probes over db. A nil shuttingDown is never set.
+func NewHealth(db *sql.DB, shuttingDown *atomic.Bool) *Health {
+	if shuttingDown == nil {
+		shuttingDown = new(atomic.Bool)
+	}
+	return &Health{
+		db:           db,
+		shuttingDown: shuttingDown,
+	}
+}
+
+// Live reports that the process is up and serving HTTP.
+func (h *Health) Live() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		web.Response(c, http.StatusOK, gin.H{"status": "ok"})
+	}
+}
+
+// Ready reports whether the service can take traffic: it is not shutting
+// down, the database answers and its schema is at the version this binary
+// expects.
+func (h *Health) Ready() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if h.shuttingDown.Load() {
+			web.Response(c, http.StatusServiceUnavailable, gin.H{"status": "shutting_down"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c, readinessTimeout)
+		defer cancel()
+
+		checks := gin.H{"database": "ok", "migrations": "ok"}
+		status := http.StatusOK
+
+		if err := h.db.PingContext(ctx); err != nil {
+			logger.FromContext(ctx).Warn("readiness: ping database", "error", err)
+			checks["database"] = err.Error()
+			checks["migrations"] = "skipped"
+			status = http.StatusServiceUnavailable
+		} else if err := migrations.Check(ctx, h.db); err != nil {
+			logger.FromContext(ctx).Warn("readiness: check migrations", "error", err)
+			checks["migrations"] = err.Error()
+			status = http.StatusServiceUnavailable
+		}
+
+		result := "ok"
+		if status != http.StatusOK {
+			result = "unavailable"
+		}
+		web.Response(c, status, gin.H{"status": result, "checks": checks})
+	}
+}
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"repository_class/internal/category"
+	"repository_class/internal/domain"
+	"repository_class/internal/label"
+	"repository_class/internal/location"
+	"repository_class/internal/product"
+	"repository_class/pkg/web"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Bounds of the size of barcode images, in pixels.
+const (
+	defaultBarcodeScale  = 4
+	maxBarcodeScale      = 20
+	defaultBarcodeHeight = 100
+	maxBarcodeHeight     = 1000
+)
+
+type Label struct {
+	service    label.Service
+	categories category.Service
+}
+
+func NewLabel(l label.Service, c category.Service) *Label {
+	return &Label{
+		service:    l,
+		categories: c,
+	}
+}
+
+func labelError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, product.ErrNotFound), errors.Is(err, location.ErrNotFound),
+		errors.Is(err, location.ErrWarehouseNotFound), errors.Is(err, category.ErrNotFound), errors.Is(err, label.ErrNoLabels):
+		web.Error(c, http.StatusNotFound, "%s", err.Error())
+	case errors.Is(err, label.ErrUnknownSymbology), errors.Is(err, label.ErrInvalidContent),
+		errors.Is(err, label.ErrUnknownTemplate), errors.Is(err, label.ErrTooManyLabels):
+		web.Error(c, http.StatusUnprocessableEntity, "%s", err.Error())
+	default:
+		web.Error(c, http.StatusInternalServerError, "%s", ErrProductInternalServer.Error())
+	}
+}
+
+// ProductBarcode renders the code_value of the product in the path.
+func (l *Label) ProductBarcode() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		s, err := l.service.ProductBarcode(c, id, c.DefaultQuery("symbology", label.Code128))
+		if err != nil {
+			labelError(c, err)
+			return
+		}
+		writeSymbol(c, s)
+	}
+}
+
+// LocationBarcode renders the code of the location, usually a bin, in the
+// path.
+func (l *Label) LocationBarcode() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		s, err := l.service.LocationBarcode(c, id, c.DefaultQuery("symbology", label.Code128))
+		if err != nil {
+			labelError(c, err)
+			return
+		}
+		writeSymbol(c, s)
+	}
+}
+
+// writeSymbol answers with s as a PNG or, with format=svg, an SVG. Modules
+// are scale pixels wide; linear barcodes are height pixels high.
+func writeSymbol(c *gin.Context, s label.Symbol) {
+	scale, err := intQuery(c, "scale", defaultBarcodeScale, maxBarcodeScale)
+	if err != nil {
+		web.Error(c, http.StatusBadRequest, "%s", err.Error())
+		return
+	}
+	height, err := intQuery(c, "height", defaultBarcodeHeight, maxBarcodeHeight)
+	if err != nil {
+		web.Error(c, http.StatusBadRequest, "%s", err.Error())
+		return
+	}
+
+	var buf bytes.Buffer
+	contentType := "image/png"
+	switch c.DefaultQuery("format", "png") {
+	case "png":
+		err = s.PNG(&buf, scale, height)
+	case "svg":
+		contentType = "image/svg+xml"
+		err = s.SVG(&buf, scale, height)
+	default:
+		web.Error(c, http.StatusBadRequest, "format must be png or svg")
+		return
+	}
+	if err != nil {
+		web.Error(c, http.StatusInternalServerError, "%s", ErrProductInternalServer.Error())
+		return
+	}
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+// intQuery reads the query parameter name, between 1 and limit.
+func intQuery(c *gin.Context, name string, fallback, limit int) (int, error) {
+	v := c.Query(name)
+	if v == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 || n > limit {
+		return 0, fmt.Errorf("%s must be between 1 and %d", name, limit)
+	}
+	return n, nil
+}
+
+func (l *Label) Templates() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		web.Success(c, http.StatusOK, l.service.Templates(c))
+	}
+}
+
+// ProductSheet prints the labels of the products of a warehouse, a category
+// and its subcategories, or a list of ids, optionally only the published
+// ones, as a PDF.
+func (l *Label) ProductSheet() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var f domain.ProductFilter
+		for _, v := range c.QueryArray("id") {
+			id, err := strconv.Atoi(v)
+			if err != nil {
+				web.Error(c, http.StatusBadRequest, "%s", err.Error())
+				return
+			}
+			f.IDs = append(f.IDs, id)
+		}
+		if v := c.Query("warehouse_id"); v != "" {
+			id, err := strconv.Atoi(v)
+			if err != nil {
+				web.Error(c, http.StatusBadRequest, "%s", err.Error())
+				return
+			}
+			f.WarehouseIDs = []int{id}
+		}
+		if v := c.Query("category_id"); v != "" {
+			id, err := strconv.Atoi(v)
+			if err != nil {
+				web.Error(c, http.StatusBadRequest, "%s", err.Error())
+				return
+			}
+			if f.CategoryIDs, err = l.categories.Descendants(c, id); err != nil {
+				labelError(c, err)
+				return
+			}
+		}
+		if v := c.Query("published"); v != "" {
+			published, err := strconv.ParseBool(v)
+			if err != nil {
+				web.Error(c, http.StatusBadRequest, "published must be a boolean")
+				return
+			}
+			f.Published = &published
+		}
+
+		var buf bytes.Buffer
+		if err := l.service.ProductSheet(c, &buf, f, labelSheet(c)); err != nil {
+			labelError(c, err)
+			return
+		}
+		writePDF(c, "product-labels.pdf", buf.Bytes())
+	}
+}
+
+// LocationSheet prints the labels of the locations of a warehouse, its bins
+// unless kind names another level, as a PDF.
+func (l *Label) LocationSheet() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		warehouseID, err := strconv.Atoi(c.Query("warehouse_id"))
+		if err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		var buf bytes.Buffer
+		kind := c.DefaultQuery("kind", domain.LocationBin)
+		if err := l.service.LocationSheet(c, &buf, warehouseID, kind, labelSheet(c)); err != nil {
+			labelError(c, err)
+			return
+		}
+		writePDF(c, "location-labels.pdf", buf.Bytes())
+	}
+}
+
+func labelSheet(c *gin.Context) domain.LabelSheet {
+	return domain.LabelSheet{Template: c.Query("template"), Symbology: c.Query("symbology")}
+}
+
+func writePDF(c *gin.Context, filename string, pdf []byte) {
+	c.Header("Content-Disposition", `inline; filename="`+filename+`"`)
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
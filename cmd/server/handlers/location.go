@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"repository_class/internal/domain"
+	"repository_class/internal/location"
+	"repository_class/pkg/web"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Location struct {
+	service location.Service
+}
+
+func NewLocation(l location.Service) *Location {
+	return &Location{
+		service: l,
+	}
+}
+
+func locationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, location.ErrNotFound), errors.Is(err, location.ErrWarehouseNotFound):
+		web.Error(c, http.StatusNotFound, "%s", err.Error())
+	case errors.Is(err, location.ErrInUse):
+		web.Error(c, http.StatusConflict, "%s", err.Error())
+	case errors.Is(err, location.ErrInvalidKind), errors.Is(err, location.ErrInvalidStruct):
+		web.Error(c, http.StatusUnprocessableEntity, "%s", err.Error())
+	default:
+		web.Error(c, http.StatusInternalServerError, "%s", ErrProductInternalServer.Error())
+	}
+}
+
+// Tree returns the locations of the warehouse in the path, zones first, with
+// the units stored under each.
+func (l *Location) Tree() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		warehouseID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		tree, err := l.service.Tree(c, warehouseID)
+		if err != nil {
+			locationError(c, err)
+			return
+		}
+		web.Success(c, http.StatusOK, tree)
+	}
+}
+
+// Create adds a location to the warehouse in the path.
+func (l *Location) Create() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		warehouseID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		var in domain.Location
+		if err := c.ShouldBindJSON(&in); err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		in.WarehouseID = warehouseID
+		created, err := l.service.Create(c, in)
+		if err != nil {
+			locationError(c, err)
+			return
+		}
+		web.Success(c, http.StatusCreated, created)
+	}
+}
+
+func (l *Location) Get() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		node, err := l.service.Get(c, id)
+		if err != nil {
+			locationError(c, err)
+			return
+		}
+		web.Success(c, http.StatusOK, node)
+	}
+}
+
+func (l *Location) Update() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		var in domain.Location
+		if err := c.ShouldBindJSON(&in); err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		updated, err := l.service.Update(c, in, id)
+		if err != nil {
+			locationError(c, err)
+			return
+		}
+		web.Success(c, http.StatusOK, updated)
+	}
+}
+
+func (l *Location) Delete() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		if err := l.service.Delete(c, id); err != nil {
+			locationError(c, err)
+			return
+		}
+		web.Success(c, http.StatusNoContent, nil)
+	}
+}
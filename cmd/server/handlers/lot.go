@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"repository_class/internal/domain"
+	"repository_class/internal/exchange"
+	"repository_class/internal/location"
+	"repository_class/internal/lot"
+	"repository_class/pkg/web"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultExpiringWindow is used when the expiring lots are listed without
+// a within parameter.
+const defaultExpiringWindow = 7 * 24 * time.Hour
+
+type Lot struct {
+	service lot.Service
+}
+
+func NewLot(l lot.Service) *Lot {
+	return &Lot{
+		service: l,
+	}
+}
+
+type lotIssueRequest struct {
+	Quantity int `json:"quantity"`
+}
+
+type lotMoveRequest struct {
+	LocationID int `json:"location_id"`
+}
+
+func lotError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, lot.ErrNotFound), errors.Is(err, lot.ErrProductNotFound):
+		web.Error(c, http.StatusNotFound, "%s", err.Error())
+	case errors.Is(err, lot.ErrInsufficientStock), errors.Is(err, location.ErrCapacityExceeded):
+		web.Error(c, http.StatusConflict, "%s", err.Error())
+	case errors.Is(err, lot.ErrInvalidQuantity), errors.Is(err, lot.ErrInvalidExpiration),
+		errors.Is(err, lot.ErrInvalidCost), errors.Is(err, exchange.ErrUnknownCurrency),
+		errors.Is(err, location.ErrNotFound), errors.Is(err, location.ErrNotBin), errors.Is(err, location.ErrWrongWarehouse):
+		web.Error(c, http.StatusUnprocessableEntity, "%s", err.Error())
+	default:
+		web.Error(c, http.StatusInternalServerError, "%s", ErrProductInternalServer.Error())
+	}
+}
+
+// ListByProduct returns the lots of the product in the path in FEFO order.
+func (l *Lot) ListByProduct() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		productID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		lots, err := l.service.ListByProduct(c, productID)
+		if err != nil {
+			lotError(c, err)
+			return
+		}
+		web.Success(c, http.StatusOK, lots)
+	}
+}
+
+// Receive adds a lot to the product in the path.
+func (l *Lot) Receive() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		productID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		var in domain.Lot
+		if err := c.ShouldBindJSON(&in); err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		in.ProductID = productID
+		received, err := l.service.Receive(c, in)
+		if err != nil {
+			lotError(c, err)
+			return
+		}
+		web.Success(c, http.StatusCreated, received)
+	}
+}
+
+// Move places the lot in the path in the bin given in the body.
+func (l *Lot) Move() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		var req lotMoveRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		moved, err := l.service.Move(c, id, req.LocationID)
+		if err != nil {
+			lotError(c, err)
+			return
+		}
+		web.Success(c, http.StatusOK, moved)
+	}
+}
+
+// Issue takes stock of the product in the path first-expired-first-out and
+// returns the lots it came from.
+func (l *Lot) Issue() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		productID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		var req lotIssueRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		allocations, err := l.service.Issue(c, productID, req.Quantity)
+		if err != nil {
+			lotError(c, err)
+			return
+		}
+		web.Success(c, http.StatusOK, allocations)
+	}
+}
+
+// Expiring lists the lots in stock expiring within the duration given by
+// within, such as 72h, optionally only those of warehouse_id.
+func (l *Lot) Expiring() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		within := defaultExpiringWindow
+		if v := c.Query("within"); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				web.Error(c, http.StatusBadRequest, "%s", err.Error())
+				return
+			}
+			within = d
+		}
+		var warehouseID *int
+		if v := c.Query("warehouse_id"); v != "" {
+			id, err := strconv.Atoi(v)
+			if err != nil {
+				web.Error(c, http.StatusBadRequest, "%s", err.Error())
+				return
+			}
+			warehouseID = &id
+		}
+		lots, err := l.service.Expiring(c, within, warehouseID)
+		if err != nil {
+			lotError(c, err)
+			return
+		}
+		web.Success(c, http.StatusOK, lots)
+	}
+}
+
+func (l *Lot) Get() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		found, err := l.service.Get(c, id)
+		if err != nil {
+			lotError(c, err)
+			return
+		}
+		web.Success(c, http.StatusOK, found)
+	}
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"repository_class/internal/domain"
+	"repository_class/internal/exchange"
+	"repository_class/internal/price"
+	"repository_class/pkg/web"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+type Price struct {
+	service price.Service
+}
+
+func NewPrice(p price.Service) *Price {
+	return &Price{
+		service: p,
+	}
+}
+
+// priceRequest is the body of a price change. A missing EffectiveAt applies
+// the price right away and a missing Currency keeps the product's.
+type priceRequest struct {
+	Price       decimal.Decimal `json:"price"`
+	Currency    string          `json:"currency"`
+	EffectiveAt time.Time       `json:"effective_at"`
+}
+
+func priceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, price.ErrNotFound), errors.Is(err, price.ErrProductNotFound), errors.Is(err, price.ErrWarehouseNotFound):
+		web.Error(c, http.StatusNotFound, "%s", err.Error())
+	case errors.Is(err, price.ErrApplied):
+		web.Error(c, http.StatusConflict, "%s", err.Error())
+	case errors.Is(err, price.ErrInvalidPrice), errors.Is(err, exchange.ErrUnknownCurrency), errors.Is(err, exchange.ErrNoRate):
+		web.Error(c, http.StatusUnprocessableEntity, "%s", err.Error())
+	default:
+		web.Error(c, http.StatusInternalServerError, "%s", ErrProductInternalServer.Error())
+	}
+}
+
+// History returns the price timeline of the product in the path.
+func (p *Price) History() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		productID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		changes, err := p.service.History(c, productID)
+		if err != nil {
+			priceError(c, err)
+			return
+		}
+		web.Success(c, http.StatusOK, changes)
+	}
+}
+
+// Schedule changes the price of the product in the path, now or at a
+// future date.
+func (p *Price) Schedule() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		productID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		var req priceRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		change, err := p.service.Schedule(c, domain.PriceChange{
+			ProductID:   productID,
+			Price:       req.Price,
+			Currency:    req.Currency,
+			EffectiveAt: req.EffectiveAt,
+		})
+		if err != nil {
+			priceError(c, err)
+			return
+		}
+		web.Success(c, http.StatusCreated, change)
+	}
+}
+
+// Cancel drops a price change that has not been applied yet.
+func (p *Price) Cancel() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		if err := p.service.Cancel(c, id); err != nil {
+			priceError(c, err)
+			return
+		}
+		web.Success(c, http.StatusNoContent, nil)
+	}
+}
+
+// Valuation values the stock of the warehouse in the path at the prices in
+// effect at the at query parameter, now when it is missing, in the currency
+// query parameter or the base currency.
+func (p *Price) Valuation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		at := time.Now()
+		if v := c.Query("at"); v != "" {
+			at, err = time.Parse(time.RFC3339, v)
+			if err != nil {
+				web.Error(c, http.StatusBadRequest, "%s", err.Error())
+				return
+			}
+		}
+		v, err := p.service.Valuation(c, id, at, c.Query("currency"))
+		if err != nil {
+			priceError(c, err)
+			return
+		}
+		web.Success(c, http.StatusOK, v)
+	}
+}
@@ -5,7 +5,10 @@ import (
 	"net/http"
 	"strconv"
 
+	"repository_class/internal/category"
 	"repository_class/internal/domain"
+	"repository_class/internal/exchange"
+	"repository_class/internal/lot"
 	"repository_class/internal/product"
 	"repository_class/pkg/web"
 
@@ -17,40 +20,149 @@ var (
 	ErrProductInternalServer = errors.New("internal server error")
 )
 
+// maxSearchLimit bounds the matches of one product search.
+const maxSearchLimit = 100
+
 type Product struct {
-	service product.Service
+	service    product.Service
+	categories category.Service
 }
 
-func NewProduct(p product.Service) *Product {
+func NewProduct(p product.Service, c category.Service) *Product {
 	return &Product{
-		service: p,
+		service:    p,
+		categories: c,
+	}
+}
+
+// productStatus maps an error of the product service to the status of the
+// response, so single and batch requests answer alike.
+func productStatus(err error) int {
+	switch {
+	case errors.Is(err, product.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, product.ErrProductRegistered), errors.Is(err, product.ErrUniqueProduct),
+		errors.Is(err, lot.ErrInsufficientStock):
+		return http.StatusConflict
+	case errors.Is(err, product.ErrCodeMismatch), errors.Is(err, product.ErrEmptySearch):
+		return http.StatusBadRequest
+	case errors.Is(err, product.ErrInvalidStruct), errors.Is(err, product.ErrCategoryNotFound),
+		errors.Is(err, product.ErrWarehouseNotFound), errors.Is(err, product.ErrInvalidCurrency), errors.Is(err, exchange.ErrNoRate),
+		errors.Is(err, product.ErrStockNotReceived), errors.Is(err, lot.ErrInvalidQuantity), errors.Is(err, lot.ErrInvalidExpiration):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// productMessage is the message of the error response; internal errors are
+// not shown to clients.
+func productMessage(status int, err error) string {
+	if status == http.StatusInternalServerError {
+		return ErrProductInternalServer.Error()
 	}
+	return err.Error()
 }
 
+func productError(c *gin.Context, err error) {
+	status := productStatus(err)
+	web.Error(c, status, "%s", productMessage(status, err))
+}
+
+// GetAll lists every product, or only those in the category given by
+// category_id and its subcategories. A currency converts the prices into it.
 func (prod *Product) GetAll() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		products, err := prod.service.GetAll(c)
+		f := domain.ProductFilter{Currency: c.Query("currency")}
+		if v := c.Query("category_id"); v != "" {
+			id, err := strconv.Atoi(v)
+			if err != nil {
+				web.Error(c, http.StatusBadRequest, "%s", err.Error())
+				return
+			}
+			if f.CategoryIDs, err = prod.categories.Descendants(c, id); err != nil {
+				if errors.Is(err, category.ErrNotFound) {
+					web.Error(c, http.StatusNotFound, "%s", err.Error())
+					return
+				}
+				web.Error(c, http.StatusInternalServerError, "%s", ErrProductInternalServer.Error())
+				return
+			}
+		}
+		products, err := prod.service.List(c, f)
 		if err != nil {
-			web.Error(c, http.StatusInternalServerError, ErrProductInternalServer.Error())
+			productError(c, err)
 			return
 		}
 		web.Success(c, http.StatusOK, products)
 	}
 }
 
+// Search finds products by name or code_value. The last word of q also
+// matches as a prefix, so it can back an autocomplete.
+func (prod *Product) Search() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		q := domain.ProductSearch{Query: c.Query("q")}
+		if v := c.Query("limit"); v != "" {
+			limit, err := strconv.Atoi(v)
+			if err != nil || limit < 1 || limit > maxSearchLimit {
+				web.Error(c, http.StatusBadRequest, "limit must be between 1 and %d", maxSearchLimit)
+				return
+			}
+			q.Limit = limit
+		}
+		matches, err := prod.service.Search(c, q)
+		if err != nil {
+			productError(c, err)
+			return
+		}
+		web.Success(c, http.StatusOK, matches)
+	}
+}
+
 func (prod *Product) Get() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id, err := strconv.Atoi(c.Param("id"))
 		if err != nil {
-			web.Error(c, http.StatusBadRequest, err.Error())
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
 			return
 		}
 		p, err := prod.service.Get(c, id)
 		if err != nil {
-			if errors.Is(err, product.ErrNotFound) {
-				web.Error(c, http.StatusNotFound, err.Error())
-			}
-			web.Error(c, http.StatusInternalServerError, ErrProductInternalServer.Error())
+			productError(c, err)
+			return
+		}
+		web.Success(c, http.StatusOK, p)
+	}
+}
+
+func (prod *Product) GetByCode() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p, err := prod.service.GetByCode(c, c.Param("code"))
+		if err != nil {
+			productError(c, err)
+			return
+		}
+		web.Success(c, http.StatusOK, p)
+	}
+}
+
+// UpsertByCode creates the product with the code given in the path, or
+// replaces it when it already exists, so scanners can retry safely.
+func (prod *Product) UpsertByCode() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var p domain.Product
+		if err := c.ShouldBindJSON(&p); err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		p, created, err := prod.service.Upsert(c, c.Param("code"), p)
+		if err != nil {
+			productError(c, err)
+			return
+		}
+		if created {
+			web.Success(c, http.StatusCreated, p)
 			return
 		}
 		web.Success(c, http.StatusOK, p)
@@ -61,15 +173,12 @@ func (prod *Product) GetWithWarehouse() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id, err := strconv.Atoi(c.Param("id"))
 		if err != nil {
-			web.Error(c, http.StatusBadRequest, err.Error())
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
 			return
 		}
 		p, err := prod.service.GetWithWarehouse(c, id)
 		if err != nil {
-			if errors.Is(err, product.ErrNotFound) {
-				web.Error(c, http.StatusNotFound, err.Error())
-			}
-			web.Error(c, http.StatusInternalServerError, ErrProductInternalServer.Error())
+			productError(c, err)
 			return
 		}
 		web.Success(c, http.StatusOK, p)
@@ -81,19 +190,12 @@ func (p *Product) Create() gin.HandlerFunc {
 		var prod domain.Product
 		// check json type
 		if err := c.ShouldBindJSON(&prod); err != nil {
-			web.Error(c, http.StatusBadRequest, err.Error())
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
 			return
 		}
 		productCreated, err := p.service.Create(c, prod)
 		if err != nil {
-			if errors.Is(err, product.ErrProductRegistered) {
-				web.Error(c, http.StatusConflict, err.Error())
-				return
-			} else if errors.Is(err, product.ErrInvalidStruct) {
-				web.Error(c, http.StatusUnprocessableEntity, err.Error())
-				return
-			}
-			web.Error(c, http.StatusInternalServerError, ErrProductInternalServer.Error())
+			productError(c, err)
 			return
 		}
 
@@ -105,25 +207,18 @@ func (p *Product) Update() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id, err := strconv.Atoi(c.Param("id"))
 		if err != nil {
-			web.Error(c, http.StatusBadRequest, err.Error())
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
 			return
 		}
 		var prod domain.Product
 		// check json type
 		if err := c.ShouldBindJSON(&prod); err != nil {
-			web.Error(c, http.StatusBadRequest, err.Error())
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
 			return
 		}
 		prod, err = p.service.Update(c, prod, id)
 		if err != nil {
-			if errors.Is(err, product.ErrProductRegistered) {
-				web.Error(c, http.StatusConflict, err.Error())
-				return
-			} else if errors.Is(err, product.ErrNotFound) {
-				web.Error(c, http.StatusNotFound, err.Error())
-				return
-			}
-			web.Error(c, http.StatusInternalServerError, ErrProductInternalServer.Error())
+			productError(c, err)
 			return
 		}
 		web.Success(c, http.StatusOK, prod)
@@ -134,16 +229,12 @@ func (prod *Product) Delete() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id, err := strconv.Atoi(c.Param("id"))
 		if err != nil {
-			web.Error(c, http.StatusBadRequest, err.Error())
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
 			return
 		}
 		err = prod.service.Delete(c, id)
 		if err != nil {
-			if errors.Is(err, product.ErrNotFound) {
-				web.Error(c, http.StatusNotFound, err.Error())
-				return
-			}
-			web.Error(c, http.StatusInternalServerError, ErrProductInternalServer.Error())
+			productError(c, err)
 			return
 		}
 		web.Success(c, http.StatusNoContent, prod)
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"repository_class/internal/domain"
+	"repository_class/internal/product"
+	"repository_class/pkg/logger"
+	"repository_class/pkg/txn"
+	"repository_class/pkg/web"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxBatchOperations bounds the operations of one batch.
+const maxBatchOperations = 100
+
+// Batch operation methods.
+const (
+	batchCreate = "create"
+	batchUpdate = "update"
+	batchDelete = "delete"
+)
+
+var errBatchFailed = errors.New("batch operation failed")
+
+type ProductBatch struct {
+	service product.Service
+	db      *sql.DB
+}
+
+func NewProductBatch(p product.Service, db *sql.DB) *ProductBatch {
+	return &ProductBatch{
+		service: p,
+		db:      db,
+	}
+}
+
+// productBatchRequest lists the operations of a batch. Atomic runs them in
+// one transaction that is rolled back when any of them fails; otherwise each
+// one succeeds or fails on its own.
+type productBatchRequest struct {
+	Atomic     bool                    `json:"atomic"`
+	Operations []productBatchOperation `json:"operations"`
+}
+
+// productBatchOperation is a create, an update of some fields of product
+// ID, or a delete of product ID. Body is the body of the single request.
+type productBatchOperation struct {
+	Method string          `json:"method"`
+	ID     int             `json:"id"`
+	Body   json.RawMessage `json:"body"`
+}
+
+// batchResult is the status and body the single request would have been
+// answered with.
+type batchResult struct {
+	Status int         `json:"status"`
+	Body   interface{} `json:"body,omitempty"`
+}
+
+// Execute runs the operations in order and answers with their results, in
+// the same order. When an atomic batch fails, the failed operation keeps its
+// result and every other one gets 424 Failed Dependency.
+func (b *ProductBatch) Execute() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req productBatchRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		if len(req.Operations) == 0 || len(req.Operations) > maxBatchOperations {
+			web.Error(c, http.StatusBadRequest, "a batch holds 1 to %d operations", maxBatchOperations)
+			return
+		}
+
+		results := make([]batchResult, len(req.Operations))
+		if !req.Atomic {
+			for i, op := range req.Operations {
+				results[i] = b.run(c, op)
+			}
+			web.Success(c, http.StatusOK, results)
+			return
+		}
+
+		failed := -1
+		err := txn.Run(c, b.db, func(ctx context.Context) error {
+			for i, op := range req.Operations {
+				results[i] = b.run(ctx, op)
+				if results[i].Status >= http.StatusBadRequest {
+					failed = i
+					return errBatchFailed
+				}
+			}
+			return nil
+		})
+		if err != nil && failed < 0 {
+			logger.FromContext(c).Error("run product batch", "error", err)
+			web.Error(c, http.StatusInternalServerError, "%s", ErrProductInternalServer.Error())
+			return
+		}
+		for i := range results {
+			switch {
+			case failed < 0 || i == failed:
+			case i < failed:
+				results[i] = batchResult{Status: http.StatusFailedDependency,
+					Body: web.ErrorBody(http.StatusFailedDependency, "rolled back because operation %d failed", failed)}
+			default:
+				results[i] = batchResult{Status: http.StatusFailedDependency,
+					Body: web.ErrorBody(http.StatusFailedDependency, "not run because operation %d failed", failed)}
+			}
+		}
+		web.Success(c, http.StatusOK, results)
+	}
+}
+
+// run calls the product service method of op and maps its outcome as the
+// single request handler does.
+func (b *ProductBatch) run(ctx context.Context, op productBatchOperation) batchResult {
+	var (
+		p      domain.Product
+		status int
+		err    error
+	)
+	if op.Method == batchCreate || op.Method == batchUpdate {
+		if err := json.Unmarshal(op.Body, &p); err != nil {
+			return batchResult{Status: http.StatusBadRequest, Body: web.ErrorBody(http.StatusBadRequest, "%s", err.Error())}
+		}
+	}
+
+	switch op.Method {
+	case batchCreate:
+		status = http.StatusCreated
+		p, err = b.service.Create(ctx, p)
+	case batchUpdate:
+		status = http.StatusOK
+		p, err = b.service.Update(ctx, p, op.ID)
+	case batchDelete:
+		if err = b.service.Delete(ctx, op.ID); err == nil {
+			return batchResult{Status: http.StatusNoContent}
+		}
+	default:
+		return batchResult{Status: http.StatusBadRequest,
+			Body: web.ErrorBody(http.StatusBadRequest, "method must be one of %s, %s or %s", batchCreate, batchUpdate, batchDelete)}
+	}
+	if err != nil {
+		status = productStatus(err)
+		if status == http.StatusInternalServerError {
+			logger.FromContext(ctx).Error("product batch operation", "method", op.Method, "id", op.ID, "error", err)
+		}
+		return batchResult{Status: status, Body: web.ErrorBody(status, "%s", productMessage(status, err))}
+	}
+	return batchResult{Status: status, Body: web.SuccessBody(p)}
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"repository_class/internal/domain"
+	"repository_class/internal/reorder"
+	"repository_class/pkg/web"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Reorder struct {
+	service reorder.Service
+}
+
+func NewReorder(r reorder.Service) *Reorder {
+	return &Reorder{
+		service: r,
+	}
+}
+
+func reorderError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, reorder.ErrProductNotFound), errors.Is(err, reorder.ErrWarehouseNotFound):
+		web.Error(c, http.StatusNotFound, "%s", err.Error())
+	case errors.Is(err, reorder.ErrInvalidPolicy):
+		web.Error(c, http.StatusUnprocessableEntity, "%s", err.Error())
+	default:
+		web.Error(c, http.StatusInternalServerError, "%s", ErrProductInternalServer.Error())
+	}
+}
+
+// LowStock lists the products at or below their reorder point, only those of
+// one warehouse when warehouse_id is given.
+func (ro *Reorder) LowStock() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var warehouseID *int
+		if v := c.Query("warehouse_id"); v != "" {
+			id, err := strconv.Atoi(v)
+			if err != nil {
+				web.Error(c, http.StatusBadRequest, "%s", err.Error())
+				return
+			}
+			warehouseID = &id
+		}
+		items, err := ro.service.LowStock(c, warehouseID)
+		if err != nil {
+			reorderError(c, err)
+			return
+		}
+		web.Success(c, http.StatusOK, items)
+	}
+}
+
+// GetProductPolicy returns the reorder policy set on the product itself;
+// nil values fall back to the warehouse defaults.
+func (ro *Reorder) GetProductPolicy() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		p, err := ro.service.ProductPolicy(c, id)
+		if err != nil {
+			reorderError(c, err)
+			return
+		}
+		web.Success(c, http.StatusOK, p)
+	}
+}
+
+// SetProductPolicy replaces the reorder policy of the product. Sending null
+// values makes the product use the warehouse defaults again.
+func (ro *Reorder) SetProductPolicy() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		var p domain.ReorderPolicy
+		if err := c.ShouldBindJSON(&p); err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		p, err = ro.service.SetProductPolicy(c, id, p)
+		if err != nil {
+			reorderError(c, err)
+			return
+		}
+		web.Success(c, http.StatusOK, p)
+	}
+}
+
+func (ro *Reorder) GetWarehousePolicy() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		p, err := ro.service.WarehousePolicy(c, id)
+		if err != nil {
+			reorderError(c, err)
+			return
+		}
+		web.Success(c, http.StatusOK, p)
+	}
+}
+
+// SetWarehousePolicy replaces the default reorder policy of the products of
+// the warehouse.
+func (ro *Reorder) SetWarehousePolicy() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		var p domain.ReorderPolicy
+		if err := c.ShouldBindJSON(&p); err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		p, err = ro.service.SetWarehousePolicy(c, id, p)
+		if err != nil {
+			reorderError(c, err)
+			return
+		}
+		web.Success(c, http.StatusOK, p)
+	}
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"repository_class/internal/reservation"
+	"repository_class/pkg/web"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Reservation struct {
+	service reservation.Service
+}
+
+func NewReservation(r reservation.Service) *Reservation {
+	return &Reservation{
+		service: r,
+	}
+}
+
+// reservationRequest is the body of a new reservation. A zero TTLSeconds
+// uses reservation.DefaultTTL.
+type reservationRequest struct {
+	Quantity   int `json:"quantity"`
+	TTLSeconds int `json:"ttl_seconds"`
+}
+
+// reservationStatus maps the reservation errors to HTTP statuses.
+func reservationStatus(err error) int {
+	switch {
+	case errors.Is(err, reservation.ErrNotFound), errors.Is(err, reservation.ErrProductNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, reservation.ErrInsufficientStock), errors.Is(err, reservation.ErrNotActive):
+		return http.StatusConflict
+	case errors.Is(err, reservation.ErrInvalidQuantity), errors.Is(err, reservation.ErrInvalidTTL):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func reservationError(c *gin.Context, err error) {
+	status := reservationStatus(err)
+	if status == http.StatusInternalServerError {
+		web.Error(c, status, "%s", ErrProductInternalServer.Error())
+		return
+	}
+	web.Error(c, status, "%s", err.Error())
+}
+
+// Create reserves stock of the product in the path.
+func (res *Reservation) Create() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		productID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		var req reservationRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		r, err := res.service.Create(c, productID, req.Quantity, time.Duration(req.TTLSeconds)*time.Second)
+		if err != nil {
+			reservationError(c, err)
+			return
+		}
+		web.Success(c, http.StatusCreated, r)
+	}
+}
+
+// ListByProduct returns every reservation made against the product in the
+// path, whatever its status.
+func (res *Reservation) ListByProduct() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		productID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		reservations, err := res.service.ListByProduct(c, productID)
+		if err != nil {
+			reservationError(c, err)
+			return
+		}
+		web.Success(c, http.StatusOK, reservations)
+	}
+}
+
+// Availability returns the stock of the product in the path that can still
+// be reserved.
+func (res *Reservation) Availability() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		productID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		a, err := res.service.Availability(c, productID)
+		if err != nil {
+			reservationError(c, err)
+			return
+		}
+		web.Success(c, http.StatusOK, a)
+	}
+}
+
+func (res *Reservation) Get() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		r, err := res.service.Get(c, id)
+		if err != nil {
+			reservationError(c, err)
+			return
+		}
+		web.Success(c, http.StatusOK, r)
+	}
+}
+
+func (res *Reservation) Confirm() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		r, err := res.service.Confirm(c, id)
+		if err != nil {
+			reservationError(c, err)
+			return
+		}
+		web.Success(c, http.StatusOK, r)
+	}
+}
+
+func (res *Reservation) Release() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		r, err := res.service.Release(c, id)
+		if err != nil {
+			reservationError(c, err)
+			return
+		}
+		web.Success(c, http.StatusOK, r)
+	}
+}
@@ -2,8 +2,10 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"repository_class/internal/domain"
+	"repository_class/internal/exchange"
 	"repository_class/internal/warehouse"
 	"repository_class/pkg/web"
 	"strconv"
@@ -11,6 +13,12 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// Paging defaults of the warehouse product listing.
+const (
+	defaultProductsLimit = 50
+	maxProductsLimit     = 200
+)
+
 // Struct for warehouse with service
 type Warehouse struct {
 	warehouseService warehouse.Service
@@ -23,34 +31,25 @@ func NewWarehouse(w warehouse.Service) *Warehouse {
 	}
 }
 
-// GetOne for warehouse
-//
-// @Summary		GetOne for warehouse
-// @Description	Get a warehouse by id
-// @Tags		Warehouse
-// @Produce		json
-// @Param		id	path		int	true	"warehouse ID"
-// @Success		200	{object}	domain.Warehouse
-// @Failure		400	{string}	string	"Bad request"
-// @Failure		404 {string}	string	"warehouse not found"
-// @Failure		500	{string}	string	"Internal server error"
-// @Router		/warehouse/{id} [get]
+// Get returns the warehouse with the id given in the path.
 func (w *Warehouse) Get() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id, err := strconv.Atoi(c.Param("id"))
 		if err != nil {
-			web.Error(c, http.StatusBadRequest, warehouse.ErrInvalidId.Error())
+			web.Error(c, http.StatusBadRequest, "%s", warehouse.ErrInvalidId.Error())
 			return
 		}
-		warehouse, err := w.warehouseService.Get(c, id)
+		current, err := w.warehouseService.Get(c, id)
 		if err != nil {
-			web.Error(c, http.StatusInternalServerError, err.Error())
+			web.Error(c, http.StatusInternalServerError, "%s", err.Error())
 			return
 		}
-		if warehouse == (domain.Warehouse{}) {
-			web.Error(c, http.StatusInternalServerError, err.Error())
+		// The warehouses of other tenants are as missing as unknown ones.
+		if current == (domain.Warehouse{}) {
+			web.Error(c, http.StatusNotFound, "%s", warehouse.ErrNotFound.Error())
+			return
 		}
-		web.Success(c, http.StatusOK, warehouse)
+		web.Success(c, http.StatusOK, current)
 	}
 }
 
@@ -58,61 +57,41 @@ func (w *Warehouse) ReportProducts() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id, err := strconv.Atoi(c.Query("id"))
 		if err != nil {
-			web.Error(c, http.StatusBadRequest, warehouse.ErrInvalidId.Error())
+			web.Error(c, http.StatusBadRequest, "%s", warehouse.ErrInvalidId.Error())
 			return
 		}
-		warehouse, err := w.warehouseService.ReportProducts(c, id)
+		report, err := w.warehouseService.ReportProducts(c, id)
 		if err != nil {
-			web.Error(c, http.StatusInternalServerError, err.Error())
+			if errors.Is(err, warehouse.ErrNotFound) {
+				web.Error(c, http.StatusNotFound, "%s", err.Error())
+				return
+			}
+			web.Error(c, http.StatusInternalServerError, "%s", err.Error())
 			return
 		}
-		if warehouse == (domain.WarehouseReport{}) {
-			web.Error(c, http.StatusInternalServerError, err.Error())
-		}
-		web.Success(c, http.StatusOK, warehouse)
+		web.Success(c, http.StatusOK, report)
 	}
 }
 
-// GetAll for warehouse
-//
-// @Summary		GetAll for warehouse
-// @Description	Get a list of all created warehouse
-// @Tags		Warehouse
-// @Produce		json
-// @Success		200	{object}	[]domain.Warehouse
-// @Failure		500	{string}	string	"Internal server error"
-// @Router		/warehouse [get]
+// GetAll returns every warehouse.
 func (w *Warehouse) GetAll() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		warehouse, err := w.warehouseService.GetAll(c)
 
 		if err != nil {
-			web.Error(c, http.StatusInternalServerError, err.Error())
+			web.Error(c, http.StatusInternalServerError, "%s", err.Error())
 		}
 		web.Success(c, http.StatusOK, warehouse)
 	}
 }
 
-// Create warehouse
-//
-// @Summary		Create a warehouse
-// @Description	Create a warehouse with the struct domain.warehouse
-// @Tags		Warehouse
-// @Accept 		json
-// @Produce		json
-// @Param		warehouse	body	domain.Warehouse	true	"Add warehouse"
-// @Success		201	{object}	domain.Warehouse
-// @Failure		400	{string}	string	"Bad request"
-// @Failure		409 {string}	string	"warehouse number is already registered"
-// @Failure		422 {string}	string	"Invalid input structure for warehouse"
-// @Failure		500	{string}	string	"Internal server error"
-// @Router		/warehouse [post]
+// Create stores the warehouse sent in the body.
 func (w *Warehouse) Create() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var war domain.Warehouse
 		err := c.ShouldBindJSON(&war)
 		if err != nil {
-			web.Error(c, http.StatusBadRequest, err.Error())
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
 			return
 		}
 		// if war.WarehouseCode == "" {
@@ -121,80 +100,137 @@ func (w *Warehouse) Create() gin.HandlerFunc {
 		// }
 		warehouse, err := w.warehouseService.Create(c, war)
 		if err != nil {
-			web.Error(c, http.StatusConflict, err.Error())
+			web.Error(c, http.StatusConflict, "%s", err.Error())
 			return
 		}
 		web.Success(c, http.StatusCreated, warehouse)
 	}
 }
 
-// Update a warehouse
-//
-// @Summary		Update a warehouse
-// @Description	Update a warehouse or some fields of it
-// @Tags		Warehouse
-// @Accept 		json
-// @Produce		json
-// @Param		id	path	int	true	"Warehouse ID"
-// @Param		warehouseUpdate	body	domain.Warehouse	true	"Update Warehouse"
-// @Success		200	{object}	domain.Warehouse
-// @Failure		400	{string}	string	"Bad request"
-// @Failure		404 {string}	string	"Warehouse not found"
-// @Failure		409 {string}	string	"Warehouse number is already registered"
-// @Failure		500	{string}	string	"Internal server error"
-// @Router		/Warehouse/{id} [patch]
+// Update changes the fields of the warehouse sent in the body.
 func (w *Warehouse) Update() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id, err := strconv.Atoi(c.Param("id"))
 		if err != nil {
-			web.Error(c, http.StatusBadRequest, warehouse.ErrInvalidId.Error())
+			web.Error(c, http.StatusBadRequest, "%s", warehouse.ErrInvalidId.Error())
 			return
 		}
-		warehouse, err := w.warehouseService.Get(c, id)
+		current, err := w.warehouseService.Get(c, id)
 		if err != nil {
-			web.Error(c, http.StatusNotFound, err.Error())
+			web.Error(c, http.StatusNotFound, "%s", err.Error())
 			return
 		}
-		if warehouse == (domain.Warehouse{}) {
-			web.Error(c, http.StatusNotFound, err.Error())
+		if current == (domain.Warehouse{}) {
+			web.Error(c, http.StatusNotFound, "%s", warehouse.ErrNotFound.Error())
+			return
 		}
-		err = json.NewDecoder(c.Request.Body).Decode(&warehouse)
+		err = json.NewDecoder(c.Request.Body).Decode(&current)
 		if err != nil {
-			web.Error(c, http.StatusBadRequest, err.Error())
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
 			return
 		}
-		war, eror := w.warehouseService.Update(c, warehouse, id)
+		war, eror := w.warehouseService.Update(c, current, id)
 		if eror != nil {
-			web.Error(c, http.StatusNotFound, eror.Error())
+			if errors.Is(eror, warehouse.ErrWarehouseRegistered) {
+				web.Error(c, http.StatusConflict, "%s", eror.Error())
+				return
+			}
+			web.Error(c, http.StatusNotFound, "%s", eror.Error())
+			return
 		}
 		web.Success(c, http.StatusOK, war)
 	}
 }
 
-// Delete a warehouse
-//
-// @Summary		Delete a warehouse
-// @Description	Delete a warehouse by id
-// @Tags		Warehouse
-// @Produce		json
-// @Param		id	path	int	true	"Delete Warehouse"
-// @Success		204	{string}	string ""
-// @Failure		400	{string}	string	"Bad request"
-// @Failure		404 {string}	string	"Warehouse not found"
-// @Failure		500	{string}	string	"Internal server error"
-// @Router		/warehouse/{id} [delete]
+// Delete removes the warehouse with the id given in the path.
 func (w *Warehouse) Delete() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id, err := strconv.Atoi(c.Param("id"))
 		if err != nil {
-			web.Error(c, http.StatusBadRequest, warehouse.ErrInvalidId.Error())
+			web.Error(c, http.StatusBadRequest, "%s", warehouse.ErrInvalidId.Error())
 			return
 		}
 		err = w.warehouseService.Delete(c, id)
 		if err != nil {
-			web.Error(c, http.StatusNotFound, err.Error())
+			web.Error(c, http.StatusNotFound, "%s", err.Error())
 			return
 		}
 		web.Success(c, http.StatusNoContent, "")
 	}
 }
+
+// Products lists the products stored in a warehouse, one page at a time,
+// with totals over every product matching the filters.
+func (w *Warehouse) Products() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", warehouse.ErrInvalidId.Error())
+			return
+		}
+		f, err := parseWarehouseProductsFilter(c)
+		if err != nil {
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		products, err := w.warehouseService.Products(c, id, f)
+		if err != nil {
+			if errors.Is(err, warehouse.ErrNotFound) {
+				web.Error(c, http.StatusNotFound, "%s", err.Error())
+				return
+			} else if errors.Is(err, warehouse.ErrInvalidSort) {
+				web.Error(c, http.StatusBadRequest, "%s", err.Error())
+				return
+			} else if errors.Is(err, exchange.ErrUnknownCurrency) || errors.Is(err, exchange.ErrNoRate) {
+				web.Error(c, http.StatusUnprocessableEntity, "%s", err.Error())
+				return
+			}
+			web.Error(c, http.StatusInternalServerError, "%s", ErrProductInternalServer.Error())
+			return
+		}
+		web.Success(c, http.StatusOK, products)
+	}
+}
+
+func parseWarehouseProductsFilter(c *gin.Context) (domain.WarehouseProductsFilter, error) {
+	f := domain.WarehouseProductsFilter{
+		Sort:     c.DefaultQuery("sort", "id"),
+		Limit:    defaultProductsLimit,
+		Currency: c.Query("currency"),
+	}
+
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxProductsLimit {
+			return f, errors.New("limit must be between 1 and " + strconv.Itoa(maxProductsLimit))
+		}
+		f.Limit = limit
+	}
+	if v := c.Query("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return f, errors.New("offset must be a non negative integer")
+		}
+		f.Offset = offset
+	}
+	switch c.DefaultQuery("order", "asc") {
+	case "asc":
+	case "desc":
+		f.Desc = true
+	default:
+		return f, errors.New("order must be asc or desc")
+	}
+	for name, dst := range map[string]**bool{"published": &f.Published, "expired": &f.Expired} {
+		v := c.Query(name)
+		if v == "" {
+			continue
+		}
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return f, errors.New(name + " must be a boolean")
+		}
+		*dst = &b
+	}
+
+	return f, nil
+}
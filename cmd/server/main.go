@@ -1,14 +1,42 @@
 package main
 
 import (
+	"context"
 	"database/sql"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
 
+	"repository_class/cmd/server/config"
+	"repository_class/cmd/server/docs"
+	"repository_class/cmd/server/grpcapi"
+	"repository_class/cmd/server/middleware"
 	"repository_class/cmd/server/routes"
+	"repository_class/internal/exchange"
+	"repository_class/internal/idempotency"
+	"repository_class/internal/label"
+	"repository_class/internal/reorder"
+	"repository_class/internal/search"
+	"repository_class/internal/warehouse"
+	"repository_class/pkg/cache"
+	"repository_class/pkg/instrument"
+	"repository_class/pkg/logger"
+	"repository_class/pkg/metrics"
+	"repository_class/pkg/ratelimit"
+	"repository_class/pkg/tracing"
+	"repository_class/pkg/worker"
 
 	"github.com/DATA-DOG/go-txdb"
 	"github.com/gin-gonic/gin"
-	"github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 func init() {
@@ -16,32 +44,279 @@ func init() {
 }
 
 func main() {
-	// Open database connection.
-	databaseConfig := &mysql.Config{
-		User:      "root",
-		Passwd:    "",
-		Addr:      "localhost:3306",
-		DBName:    "my_db",
-		ParseTime: true,
+	cfg := config.Load()
+
+	log := logger.New(os.Stdout, cfg.LogLevel)
+	slog.SetDefault(log)
+
+	if err := run(cfg, log); err != nil {
+		log.Error("server stopped", "error", err)
+		os.Exit(1)
+	}
+}
+
+// run starts the HTTP and gRPC servers and the background workers, and blocks
+// until it receives SIGINT or SIGTERM. It then stops accepting connections,
+// waits up to cfg.ShutdownTimeout for in-flight requests, and releases
+// resources in reverse order of creation: the workers first, then the
+// database pool, then the trace exporter so the spans of the last requests
+// are flushed.
+func run(cfg config.Config, log *slog.Logger) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	instrument.SetSlowQueryThreshold(cfg.SlowQueryThreshold)
+
+	shutdownTracing, err := tracing.Setup(ctx, tracing.Config{
+		ServiceName:  cfg.ServiceName,
+		Exporter:     cfg.TraceExporter,
+		OTLPEndpoint: cfg.OTLPEndpoint,
+	})
+	if err != nil {
+		return fmt.Errorf("setup tracing: %w", err)
 	}
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			log.Error("shutdown tracing", "error", err)
+		}
+		log.Info("tracing stopped")
+	}()
 
-	db, err := sql.Open("mysql", databaseConfig.FormatDSN())
+	// Open database connection.
+	db, err := sql.Open("mysql", cfg.Database.FormatDSN())
 	if err != nil {
-		log.Fatal(err)
+		return fmt.Errorf("open database: %w", err)
 	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			log.Error("close database", "error", err)
+		}
+		log.Info("database closed")
+	}()
 
 	// Ping database connection.
-	if err = db.Ping(); err != nil {
-		log.Fatal(err)
+	if err = db.PingContext(ctx); err != nil {
+		return fmt.Errorf("ping database: %w", err)
+	}
+
+	log.Info("connection established", "addr", cfg.Database.Addr, "database", cfg.Database.DBName)
+
+	metrics.Registry.MustRegister(
+		collectors.NewDBStatsCollector(db, cfg.Database.DBName),
+		warehouse.NewCollector(warehouse.NewRepository(db)),
+	)
+
+	validator, err := middleware.OpenAPIValidator(docs.Spec(), middleware.ValidatorOptions{
+		MaxBodyBytes:      cfg.MaxBodyBytes,
+		ValidateResponses: cfg.ValidateResponses,
+	})
+	if err != nil {
+		return fmt.Errorf("build request validator: %w", err)
 	}
 
-	log.Println("Connection stablished")
+	eng := gin.New()
+	// Let services read request scoped values (logger, request ID) through
+	// the *gin.Context handlers pass as their context.
+	eng.ContextWithFallback = true
+	eng.Use(
+		gin.Recovery(),
+		otelgin.Middleware(cfg.ServiceName),
+		middleware.RequestID(),
+		middleware.AccessLog(),
+		middleware.Metrics(),
+		validator,
+	)
 
-	eng := gin.Default()
-	router := routes.NewRouter(eng, db)
+	notifier, err := reorder.NewNotifier(reorder.NotifierConfig{
+		Kind:       cfg.ReorderNotifier,
+		WebhookURL: cfg.ReorderWebhookURL,
+		SMTPAddr:   cfg.SMTPAddr,
+		SMTPFrom:   cfg.SMTPFrom,
+		SMTPTo:     cfg.SMTPTo,
+	})
+	if err != nil {
+		return fmt.Errorf("build reorder notifier: %w", err)
+	}
+
+	baseCurrency, err := exchange.ParseCurrency(cfg.BaseCurrency)
+	if err != nil {
+		return fmt.Errorf("base currency: %w", err)
+	}
+
+	rateLimits, err := middleware.ParseRateLimits(cfg.RateLimit, cfg.RateLimits)
+	if err != nil {
+		return fmt.Errorf("rate limits: %w", err)
+	}
+
+	index, err := search.NewIndex(search.Config{Kind: cfg.SearchIndex}, db)
+	if err != nil {
+		return fmt.Errorf("build search index: %w", err)
+	}
+	defer index.Close()
+
+	idempotencyRepository := idempotency.NewRepository(db)
+	idempotencyService := idempotency.NewService(&idempotencyRepository, cfg.IdempotencyTTL)
+
+	opts := routes.Options{
+		BaseCurrency: baseCurrency,
+		MaxAge:       cfg.HTTPCacheMaxAge,
+		// Only the API is scoped to a tenant; health, metrics and docs are
+		// not.
+		API: []gin.HandlerFunc{middleware.Tenant(cfg.DefaultTenant)},
+		REST: []gin.HandlerFunc{
+			middleware.RateLimit(ratelimit.NewMemoryStore(), "/api/v1", rateLimits),
+			middleware.Idempotency(idempotencyService),
+		},
+		Search:       index,
+		ShuttingDown: new(atomic.Bool),
+	}
+	if cfg.LabelTemplatesFile != "" {
+		if opts.LabelTemplates, err = label.LoadTemplates(cfg.LabelTemplatesFile); err != nil {
+			return fmt.Errorf("load label templates: %w", err)
+		}
+	}
+	if cfg.CacheTTL > 0 {
+		opts.Cache = cache.New("repository", cache.NewLRU(cfg.CacheSize), cfg.CacheTTL)
+	}
+	router := routes.NewRouter(eng, db, notifier, opts)
 	router.MapRoutes()
 
-	if err := eng.Run(); err != nil {
-		log.Fatal(err)
+	rates := router.ExchangeService()
+	if cfg.ExchangeRatesFile != "" {
+		n, err := rates.LoadFile(ctx, cfg.ExchangeRatesFile)
+		if err != nil {
+			return fmt.Errorf("load exchange rates: %w", err)
+		}
+		log.Info("exchange rates loaded", "file", cfg.ExchangeRatesFile, "count", n)
 	}
+
+	products := router.ProductService()
+	n, err := products.Reindex(ctx)
+	if err != nil {
+		return fmt.Errorf("build search index: %w", err)
+	}
+	log.Info("search index built", "index", cfg.SearchIndex, "count", n)
+
+	// Workers get their own context so they keep running while the servers
+	// drain, and are stopped before the database pool is closed.
+	workerCtx, stopWorkers := context.WithCancel(logger.WithContext(context.Background(), log))
+	var workers worker.Group
+	defer func() {
+		stopWorkers()
+		workers.Wait()
+		log.Info("workers stopped")
+	}()
+
+	reservations := router.ReservationService()
+	if err := workers.Every(workerCtx, "reservation-expiry", cfg.ReservationSweepInterval, func(ctx context.Context) error {
+		n, err := reservations.ExpireDue(ctx)
+		if n > 0 {
+			logger.FromContext(ctx).Info("reservations expired", "count", n)
+		}
+		return err
+	}); err != nil {
+		return fmt.Errorf("start worker: %w", err)
+	}
+
+	prices := router.PriceService()
+	if err := workers.Every(workerCtx, "price-scheduler", cfg.PriceScheduleInterval, func(ctx context.Context) error {
+		n, err := prices.ApplyDue(ctx)
+		if n > 0 {
+			logger.FromContext(ctx).Info("scheduled prices applied", "count", n)
+		}
+		return err
+	}); err != nil {
+		return fmt.Errorf("start worker: %w", err)
+	}
+
+	if err := workers.Every(workerCtx, "idempotency-expiry", cfg.IdempotencySweepInterval, func(ctx context.Context) error {
+		n, err := idempotencyService.DeleteExpired(ctx)
+		if n > 0 {
+			logger.FromContext(ctx).Info("idempotency keys expired", "count", n)
+		}
+		return err
+	}); err != nil {
+		return fmt.Errorf("start worker: %w", err)
+	}
+
+	if err := workers.Every(workerCtx, "search-reindex", cfg.SearchReindexInterval, func(ctx context.Context) error {
+		_, err := products.Reindex(ctx)
+		return err
+	}); err != nil {
+		return fmt.Errorf("start worker: %w", err)
+	}
+
+	if cfg.ExchangeRatesFile != "" {
+		if err := workers.Every(workerCtx, "exchange-rates", cfg.ExchangeRatesReloadInterval, func(ctx context.Context) error {
+			_, err := rates.LoadFile(ctx, cfg.ExchangeRatesFile)
+			return err
+		}); err != nil {
+			return fmt.Errorf("start worker: %w", err)
+		}
+	}
+
+	srv := &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           eng,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	grpcSrv := grpcapi.NewServer(router.ProductService(), router.WarehouseService(), cfg.DefaultTenant)
+	grpcLis, err := net.Listen("tcp", cfg.GRPCAddr)
+	if err != nil {
+		return fmt.Errorf("listen grpc: %w", err)
+	}
+
+	serveErr := make(chan error, 2)
+	go func() {
+		log.Info("server listening", "addr", cfg.Addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- fmt.Errorf("http: %w", err)
+		}
+	}()
+	go func() {
+		log.Info("grpc server listening", "addr", cfg.GRPCAddr)
+		if err := grpcSrv.Serve(grpcLis); err != nil {
+			serveErr <- fmt.Errorf("grpc: %w", err)
+		}
+	}()
+
+	select {
+	case err := <-serveErr:
+		grpcSrv.Stop()
+		_ = srv.Close()
+		return fmt.Errorf("serve: %w", err)
+	case <-ctx.Done():
+	}
+
+	log.Info("shutting down", "timeout", cfg.ShutdownTimeout)
+	// Readiness fails from now on, so no new traffic is routed here while
+	// the requests in flight drain.
+	opts.ShuttingDown.Store(true)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcSrv.GracefulStop()
+		close(grpcStopped)
+	}()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		grpcSrv.Stop()
+		return fmt.Errorf("drain connections: %w", err)
+	}
+	log.Info("server stopped")
+
+	select {
+	case <-grpcStopped:
+	case <-shutdownCtx.Done():
+		grpcSrv.Stop()
+	}
+	log.Info("grpc server stopped")
+
+	return nil
 }
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"repository_class/pkg/cache"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConditionalGET tags successful responses with an ETag hashed from their
+// body and a Cache-Control letting clients keep them for maxAge, revalidating
+// after that. A request whose If-None-Match names the current tag gets a
+// 304 Not Modified without the body. A request sent with Cache-Control:
+// no-cache also skips the server side cache.
+func ConditionalGET(maxAge time.Duration) gin.HandlerFunc {
+	cacheControl := "private, no-cache"
+	if maxAge > 0 {
+		cacheControl = "private, max-age=" + strconv.Itoa(int(maxAge.Seconds()))
+	}
+
+	return func(c *gin.Context) {
+		if strings.Contains(c.GetHeader("Cache-Control"), "no-cache") {
+			c.Request = c.Request.WithContext(cache.Bypass(c.Request.Context()))
+		}
+
+		w := &bufferedWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		if w.status != http.StatusOK {
+			c.Writer.WriteHeader(w.status)
+			_, _ = c.Writer.Write(w.body.Bytes())
+			return
+		}
+
+		sum := sha256.Sum256(w.body.Bytes())
+		etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+		h := c.Writer.Header()
+		h.Set("ETag", etag)
+		h.Set("Cache-Control", cacheControl)
+		// The same path answers differently per tenant.
+		h.Add("Vary", TenantHeader)
+
+		if matchETag(c.GetHeader("If-None-Match"), etag) {
+			h.Del("Content-Type")
+			h.Del("Content-Length")
+			c.Writer.WriteHeader(http.StatusNotModified)
+			c.Writer.WriteHeaderNow()
+			return
+		}
+		c.Writer.WriteHeader(w.status)
+		_, _ = c.Writer.Write(w.body.Bytes())
+	}
+}
+
+// matchETag reports whether the If-None-Match header names etag, comparing
+// weakly as RFC 9110 asks for GET.
+func matchETag(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConditionalGET(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	eng := gin.New()
+	eng.GET("/", ConditionalGET(time.Minute), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"id": 1})
+	})
+	eng.GET("/missing", ConditionalGET(time.Minute), func(c *gin.Context) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	})
+
+	rec := httptest.NewRecorder()
+	eng.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	etag := rec.Header().Get("ETag")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotEmpty(t, etag)
+	assert.Equal(t, "private, max-age=60", rec.Header().Get("Cache-Control"))
+	assert.JSONEq(t, `{"id":1}`, rec.Body.String())
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("If-None-Match", `"other", `+etag)
+	rec = httptest.NewRecorder()
+	eng.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("If-None-Match", `"other"`)
+	rec = httptest.NewRecorder()
+	eng.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	eng.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Empty(t, rec.Header().Get("ETag"))
+}
@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+
+	"repository_class/internal/domain"
+	"repository_class/internal/idempotency"
+	"repository_class/pkg/logger"
+	"repository_class/pkg/web"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IdempotencyKeyHeader names a POST request so retries of it are answered
+// with the first response instead of running again.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// ReplayedHeader marks a response replayed for a retried request.
+const ReplayedHeader = "Idempotent-Replayed"
+
+const maxIdempotencyKeyLength = 255
+
+// Idempotency runs a POST request sent with an Idempotency-Key once per key:
+// its response is stored and replayed to the retries of the same request.
+// A retry sent while the first request runs gets 409, and the key sent with
+// another request gets 422. Responses with a 5xx status are not kept, so
+// the request can be retried.
+func Idempotency(svc idempotency.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if c.Request.Method != http.MethodPost || key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			web.Error(c, http.StatusBadRequest, "%s must be at most %d characters", IdempotencyKeyHeader, maxIdempotencyKeyLength)
+			c.Abort()
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil {
+			var err error
+			if body, err = io.ReadAll(c.Request.Body); err != nil {
+				web.Error(c, http.StatusBadRequest, "%s", err.Error())
+				c.Abort()
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		hash := sha256.New()
+		hash.Write([]byte(c.Request.Method + " " + c.Request.URL.RequestURI() + "\n"))
+		hash.Write(body)
+		requestHash := hex.EncodeToString(hash.Sum(nil))
+
+		stored, claimed, err := svc.Begin(c, key, requestHash)
+		switch {
+		case errors.Is(err, idempotency.ErrInFlight):
+			web.Error(c, http.StatusConflict, "%s", err.Error())
+			c.Abort()
+			return
+		case errors.Is(err, idempotency.ErrKeyReused):
+			web.Error(c, http.StatusUnprocessableEntity, "%s", err.Error())
+			c.Abort()
+			return
+		case err != nil:
+			logger.FromContext(c).Error("begin idempotent request", "error", err)
+			web.Error(c, http.StatusInternalServerError, "could not check the %s", IdempotencyKeyHeader)
+			c.Abort()
+			return
+		case !claimed:
+			c.Header(ReplayedHeader, "true")
+			c.Data(stored.Status, stored.ContentType, stored.Body)
+			c.Abort()
+			return
+		}
+
+		w := &bufferedWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+		c.Writer.WriteHeader(w.status)
+		_, _ = c.Writer.Write(w.body.Bytes())
+
+		// The response is stored even when the client went away, since that
+		// is when it retries.
+		ctx := context.WithoutCancel(c.Request.Context())
+		if w.status >= http.StatusInternalServerError {
+			err = svc.Release(ctx, key)
+		} else {
+			err = svc.Complete(ctx, domain.IdempotentRequest{
+				Key:         key,
+				RequestHash: requestHash,
+				Status:      w.status,
+				ContentType: w.Header().Get("Content-Type"),
+				Body:        w.body.Bytes(),
+			})
+		}
+		if err != nil {
+			logger.FromContext(c).Error("store idempotent response", "error", err)
+		}
+	}
+}
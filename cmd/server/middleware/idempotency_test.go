@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"repository_class/internal/domain"
+	"repository_class/internal/idempotency"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryIdempotency keeps the requests in a map, in place of the database.
+type memoryIdempotency struct {
+	mu       sync.Mutex
+	requests map[string]domain.IdempotentRequest
+}
+
+func (m *memoryIdempotency) Begin(_ context.Context, key, requestHash string) (domain.IdempotentRequest, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r, ok := m.requests[key]
+	switch {
+	case !ok:
+		m.requests[key] = domain.IdempotentRequest{Key: key, RequestHash: requestHash}
+		return m.requests[key], true, nil
+	case r.RequestHash != requestHash:
+		return domain.IdempotentRequest{}, false, idempotency.ErrKeyReused
+	case r.InFlight():
+		return domain.IdempotentRequest{}, false, idempotency.ErrInFlight
+	}
+	return r, false, nil
+}
+
+func (m *memoryIdempotency) Complete(_ context.Context, r domain.IdempotentRequest) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[r.Key] = r
+	return nil
+}
+
+func (m *memoryIdempotency) Release(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.requests, key)
+	return nil
+}
+
+func (m *memoryIdempotency) DeleteExpired(context.Context) (int64, error) {
+	return 0, nil
+}
+
+func TestIdempotency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var created int
+	started, block := make(chan struct{}), make(chan struct{})
+	eng := gin.New()
+	eng.Use(Idempotency(&memoryIdempotency{requests: make(map[string]domain.IdempotentRequest)}))
+	eng.POST("/warehouses", func(c *gin.Context) {
+		if c.Query("block") != "" {
+			close(started)
+			<-block
+		}
+		created++
+		c.JSON(http.StatusCreated, gin.H{"id": created})
+	})
+	eng.POST("/fail", func(c *gin.Context) {
+		c.Status(http.StatusInternalServerError)
+	})
+
+	post := func(path, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		rec := httptest.NewRecorder()
+		eng.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := post("/warehouses", "a", `{"name":"north"}`)
+	assert.Equal(t, http.StatusCreated, first.Code)
+
+	replay := post("/warehouses", "a", `{"name":"north"}`)
+	assert.Equal(t, http.StatusCreated, replay.Code)
+	assert.Equal(t, "true", replay.Header().Get(ReplayedHeader))
+	assert.Equal(t, first.Body.String(), replay.Body.String())
+	assert.Equal(t, first.Header().Get("Content-Type"), replay.Header().Get("Content-Type"))
+	assert.Equal(t, 1, created)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, post("/warehouses", "a", `{"name":"south"}`).Code)
+
+	// Requests without a key always run.
+	post("/warehouses", "", `{"name":"north"}`)
+	assert.Equal(t, 2, created)
+
+	done := make(chan struct{})
+	go func() {
+		post("/warehouses?block=1", "b", "")
+		close(done)
+	}()
+	<-started
+	assert.Equal(t, http.StatusConflict, post("/warehouses?block=1", "b", "").Code)
+	close(block)
+	<-done
+
+	// Failed requests free their key.
+	assert.Equal(t, http.StatusInternalServerError, post("/fail", "c", "").Code)
+	assert.Equal(t, http.StatusInternalServerError, post("/fail", "c", "").Code)
+	assert.Empty(t, post("/fail", "c", "").Header().Get(ReplayedHeader))
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"log/slog"
+	"time"
+
+	"repository_class/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccessLog writes one log line per request once it has been served. It
+// must run after RequestID so the line carries the request ID.
+func AccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("route", route),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("client_ip", c.ClientIP()),
+			slog.Int("bytes", c.Writer.Size()),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+
+		ctx := c.Request.Context()
+		logger.FromContext(ctx).LogAttrs(ctx, level, "request", attrs...)
+	}
+}
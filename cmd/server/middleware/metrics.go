@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"repository_class/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics records the request count and latency of every request, labelled
+// by route template rather than raw path to keep cardinality bounded.
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+
+		metrics.HTTPRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+		metrics.HTTPDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,219 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"repository_class/pkg/logger"
+	"repository_class/pkg/web"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultMaxBodyBytes bounds request bodies when ValidatorOptions leaves
+// MaxBodyBytes unset.
+const DefaultMaxBodyBytes = 1 << 20
+
+// ValidatorOptions configures OpenAPIValidator.
+type ValidatorOptions struct {
+	// MaxBodyBytes is the largest request body accepted.
+	MaxBodyBytes int64
+	// ValidateResponses buffers every response and checks it against the
+	// document too, replacing it with a 500 when it does not conform. It is
+	// meant for tests.
+	ValidateResponses bool
+}
+
+func init() {
+	openapi3.DefineStringFormatValidator("date-time", openapi3.NewRegexpFormatValidator(openapi3.FormatOfStringDateTime))
+}
+
+// OpenAPIValidator checks path parameters, query parameters and bodies of
+// every request against spec before it reaches the handlers, answering with
+// a 400 listing the offending fields. Requests to routes the document does
+// not describe are passed through.
+func OpenAPIValidator(spec *openapi3.T, opts ValidatorOptions) (gin.HandlerFunc, error) {
+	router, err := gorillamux.NewRouter(spec)
+	if err != nil {
+		return nil, err
+	}
+	if opts.MaxBodyBytes <= 0 {
+		opts.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+
+	filterOpts := &openapi3filter.Options{
+		MultiError:         true,
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+	}
+
+	return func(c *gin.Context) {
+		route, pathParams, err := router.FindRoute(c.Request)
+		if err != nil {
+			if errors.Is(err, routers.ErrPathNotFound) || errors.Is(err, routers.ErrMethodNotAllowed) {
+				c.Next()
+				return
+			}
+			web.Error(c, http.StatusBadRequest, "%s", err.Error())
+			c.Abort()
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			body, err = io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, opts.MaxBodyBytes))
+			if err != nil {
+				var maxErr *http.MaxBytesError
+				if errors.As(err, &maxErr) {
+					web.Error(c, http.StatusRequestEntityTooLarge, "request body exceeds %d bytes", opts.MaxBodyBytes)
+				} else {
+					web.Error(c, http.StatusBadRequest, "%s", err.Error())
+				}
+				c.Abort()
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		input := &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    filterOpts,
+		}
+		if err := openapi3filter.ValidateRequest(c, input); err != nil {
+			web.ErrorWithDetails(c, http.StatusBadRequest, validationDetails(err), "request does not match the API specification")
+			c.Abort()
+			return
+		}
+		if body != nil {
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		if !opts.ValidateResponses {
+			c.Next()
+			return
+		}
+
+		w := &bufferedWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		err = openapi3filter.ValidateResponse(c, &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: input,
+			Status:                 w.status,
+			Header:                 w.Header(),
+			Body:                   io.NopCloser(bytes.NewReader(w.body.Bytes())),
+			Options:                filterOpts,
+		})
+		if err != nil {
+			logger.FromContext(c).Error("response does not match the API specification", "error", err)
+			w.Header().Del("Content-Length")
+			web.ErrorWithDetails(c, http.StatusInternalServerError, validationDetails(err), "response does not match the API specification")
+			return
+		}
+
+		c.Writer.WriteHeader(w.status)
+		_, _ = c.Writer.Write(w.body.Bytes())
+	}, nil
+}
+
+// validationDetails flattens the errors returned by openapi3filter into one
+// entry per offending parameter or field.
+func validationDetails(err error) []web.ErrorDetail {
+	var multi openapi3.MultiError
+	if errors.As(err, &multi) {
+		var details []web.ErrorDetail
+		for _, e := range multi {
+			details = append(details, validationDetails(e)...)
+		}
+		return details
+	}
+
+	field, cause := "", err
+	var reqErr *openapi3filter.RequestError
+	var resErr *openapi3filter.ResponseError
+	switch {
+	case errors.As(err, &reqErr):
+		field, cause = "body", reqErr.Err
+		if reqErr.Parameter != nil {
+			field = reqErr.Parameter.In + "." + reqErr.Parameter.Name
+		}
+	case errors.As(err, &resErr):
+		field, cause = "response", resErr.Err
+	}
+
+	details := schemaDetails(cause)
+	if len(details) == 0 {
+		return []web.ErrorDetail{{Field: field, Message: err.Error()}}
+	}
+	for i := range details {
+		details[i].Field = strings.Trim(field+"."+details[i].Field, ".")
+	}
+	return details
+}
+
+func schemaDetails(err error) []web.ErrorDetail {
+	if err == nil {
+		return nil
+	}
+
+	var multi openapi3.MultiError
+	if errors.As(err, &multi) {
+		var details []web.ErrorDetail
+		for _, e := range multi {
+			details = append(details, schemaDetails(e)...)
+		}
+		return details
+	}
+
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		return []web.ErrorDetail{{
+			Field:   strings.Join(schemaErr.JSONPointer(), "."),
+			Message: schemaErr.Reason,
+		}}
+	}
+	return nil
+}
+
+// bufferedWriter holds the response back so it can be validated before it
+// is sent.
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {}
+
+func (w *bufferedWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferedWriter) Size() int {
+	return w.body.Len()
+}
+
+func (w *bufferedWriter) Written() bool {
+	return w.body.Len() > 0
+}
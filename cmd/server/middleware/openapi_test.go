@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"repository_class/cmd/server/docs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newValidatedEngine(t *testing.T, opts ValidatorOptions, h gin.HandlerFunc) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	validator, err := OpenAPIValidator(docs.Spec(), opts)
+	assert.NoError(t, err)
+
+	eng := gin.New()
+	eng.Use(validator)
+	eng.POST("/api/v1/products", h)
+	eng.GET("/api/v1/products/:id", h)
+	return eng
+}
+
+func serve(eng *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	eng.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestOpenAPIValidatorRejectsInvalidRequests(t *testing.T) {
+	reached := false
+	eng := newValidatedEngine(t, ValidatorOptions{MaxBodyBytes: 256}, func(c *gin.Context) {
+		reached = true
+		c.Status(http.StatusCreated)
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"unknown field", http.MethodPost, "/api/v1/products", `{"name":"x","colour":"red"}`, http.StatusBadRequest},
+		{"bad expiration", http.MethodPost, "/api/v1/products", `{"name":"x","expiration":"tomorrow"}`, http.StatusBadRequest},
+		{"wrong type", http.MethodPost, "/api/v1/products", `{"quantity":"ten"}`, http.StatusBadRequest},
+		{"bad path param", http.MethodGet, "/api/v1/products/abc", ``, http.StatusBadRequest},
+		{"oversized body", http.MethodPost, "/api/v1/products", `{"name":"` + strings.Repeat("x", 512) + `"}`, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reached = false
+			rec := serve(eng, tt.method, tt.path, tt.body)
+
+			assert.Equal(t, tt.status, rec.Code)
+			assert.False(t, reached)
+
+			var res struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+			assert.NotEmpty(t, res.Code)
+		})
+	}
+}
+
+func TestOpenAPIValidatorPassesValidRequests(t *testing.T) {
+	eng := newValidatedEngine(t, ValidatorOptions{}, func(c *gin.Context) {
+		var body map[string]any
+		assert.NoError(t, c.ShouldBindJSON(&body))
+		assert.Equal(t, "x", body["name"])
+		c.Status(http.StatusNoContent)
+	})
+
+	rec := serve(eng, http.MethodPost, "/api/v1/products", `{"name":"x","expiration":"2030-01-02T00:00:00Z"}`)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
+
+func TestOpenAPIValidatorChecksResponses(t *testing.T) {
+	eng := newValidatedEngine(t, ValidatorOptions{ValidateResponses: true}, func(c *gin.Context) {
+		// Missing the {"data": ...} envelope.
+		c.JSON(http.StatusOK, gin.H{"id": 1})
+	})
+
+	rec := serve(eng, http.MethodGet, "/api/v1/products/1", ``)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
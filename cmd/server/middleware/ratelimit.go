@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"repository_class/pkg/logger"
+	"repository_class/pkg/ratelimit"
+	"repository_class/pkg/web"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader identifies the integration a request comes from.
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyContextKey is where an authentication middleware stores the API key
+// of a request once it verified it. Until then the header is only a claim.
+const APIKeyContextKey = "api_key"
+
+// RateLimits sets the limit of each route group, the first path segment
+// after the prefix RateLimit is given, such as "products" for
+// /api/v1/products/:id. Groups without an entry get Default.
+type RateLimits struct {
+	Default ratelimit.Limit
+	Groups  map[string]ratelimit.Limit
+}
+
+// ParseRateLimits reads the default limit and the per group ones, written
+// as group=limit, such as products=100/1m.
+func ParseRateLimits(def string, groups []string) (RateLimits, error) {
+	limits := RateLimits{Groups: make(map[string]ratelimit.Limit)}
+	var err error
+	if limits.Default, err = ratelimit.ParseLimit(def); err != nil {
+		return RateLimits{}, err
+	}
+	for _, g := range groups {
+		name, limit, ok := strings.Cut(g, "=")
+		if !ok {
+			return RateLimits{}, ratelimit.ErrInvalidLimit
+		}
+		if limits.Groups[strings.TrimSpace(name)], err = ratelimit.ParseLimit(limit); err != nil {
+			return RateLimits{}, err
+		}
+	}
+	return limits, nil
+}
+
+func (l RateLimits) of(group string) ratelimit.Limit {
+	if limit, ok := l.Groups[group]; ok {
+		return limit
+	}
+	return l.Default
+}
+
+// RateLimit meters the requests of each client per route group under
+// prefix. A client is identified by the API key or user an authentication
+// middleware set in APIKeyContextKey or gin.AuthUserKey, or else by its IP.
+// It sends the RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset
+// headers and answers 429 with a Retry-After once the client ran out. When
+// store fails the request is let through.
+func RateLimit(store ratelimit.Store, prefix string, limits RateLimits) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		group := strings.TrimPrefix(strings.TrimPrefix(c.FullPath(), prefix), "/")
+		// Custom methods such as products:batch count against their
+		// collection.
+		if i := strings.IndexAny(group, `/\:`); i >= 0 {
+			group = group[:i]
+		}
+		limit := limits.of(group)
+		if limit.Unlimited() {
+			c.Next()
+			return
+		}
+
+		res, err := store.Take(c, group+":"+ClientKey(c), limit)
+		if err != nil {
+			logger.FromContext(c).Warn("rate limit", "error", err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(limit.Requests))
+		c.Header("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		c.Header("RateLimit-Reset", ceilSeconds(res.Reset))
+		c.Header("RateLimit-Policy", strconv.Itoa(limit.Requests)+";w="+ceilSeconds(limit.Per))
+		if !res.Allowed {
+			retry := ceilSeconds(res.RetryAfter)
+			c.Header("Retry-After", retry)
+			web.Error(c, http.StatusTooManyRequests, "rate limit of %s exceeded, retry in %ss", limit, retry)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// ClientKey names the client of a request. Only verified API keys count:
+// trusting the header would let a client rotate it for a fresh bucket on
+// every request. API keys are hashed so they are not kept in the limiter
+// store.
+func ClientKey(c *gin.Context) string {
+	if key := c.GetString(APIKeyContextKey); key != "" {
+		sum := sha256.Sum256([]byte(key))
+		return "key:" + hex.EncodeToString(sum[:8])
+	}
+	if user := c.GetString(gin.AuthUserKey); user != "" {
+		return "user:" + user
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"repository_class/pkg/ratelimit"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	limits, err := ParseRateLimits("2/1m", []string{"warehouses=1/1m", "categories=off"})
+	assert.NoError(t, err)
+
+	eng := gin.New()
+	// Stands in for an authentication middleware that verified the key.
+	verify := func(c *gin.Context) {
+		if key := c.GetHeader(APIKeyHeader); key != "" {
+			c.Set(APIKeyContextKey, key)
+		}
+	}
+	api := eng.Group("/api/v1", verify, RateLimit(ratelimit.NewMemoryStore(), "/api/v1", limits))
+	for _, path := range []string{"/products/:id", "/warehouses/:id", "/categories/:id"} {
+		api.GET(path, func(c *gin.Context) { c.Status(http.StatusOK) })
+	}
+
+	get := func(path, apiKey string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if apiKey != "" {
+			req.Header.Set(APIKeyHeader, apiKey)
+		}
+		rec := httptest.NewRecorder()
+		eng.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("/api/v1/products/1", "a")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", rec.Header().Get("RateLimit-Remaining"))
+	// Every product shares the bucket of the group.
+	assert.Equal(t, http.StatusOK, get("/api/v1/products/2", "a").Code)
+
+	rec = get("/api/v1/products/3", "a")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "30", rec.Header().Get("Retry-After"))
+	assert.Contains(t, rec.Body.String(), `"code":"too_many_requests"`)
+
+	// Other clients and groups have their own buckets.
+	assert.Equal(t, http.StatusOK, get("/api/v1/products/1", "b").Code)
+	assert.Equal(t, http.StatusOK, get("/api/v1/warehouses/1", "a").Code)
+	assert.Equal(t, http.StatusTooManyRequests, get("/api/v1/warehouses/1", "a").Code)
+
+	for i := 0; i < 5; i++ {
+		rec = get("/api/v1/categories/1", "a")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get("RateLimit-Limit"))
+	}
+}
+
+func TestRateLimitIgnoresUnverifiedAPIKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	limits, err := ParseRateLimits("2/1m", nil)
+	assert.NoError(t, err)
+
+	eng := gin.New()
+	api := eng.Group("/api/v1", RateLimit(ratelimit.NewMemoryStore(), "/api/v1", limits))
+	api.GET("/products/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	// A new key on every request still draws on the bucket of the IP.
+	codes := make([]int, 3)
+	for i, key := range []string{"a", "b", "c"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/products/1", nil)
+		req.Header.Set(APIKeyHeader, key)
+		rec := httptest.NewRecorder()
+		eng.ServeHTTP(rec, req)
+		codes[i] = rec.Code
+	}
+	assert.Equal(t, []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}, codes)
+}
+
+func TestParseRateLimits(t *testing.T) {
+	limits, err := ParseRateLimits("100/1m", []string{"products=10/1s"})
+	assert.NoError(t, err)
+	assert.Equal(t, ratelimit.Limit{Requests: 100, Per: time.Minute}, limits.of("warehouses"))
+	assert.Equal(t, ratelimit.Limit{Requests: 10, Per: time.Second}, limits.of("products"))
+
+	_, err = ParseRateLimits("100/1m", []string{"products"})
+	assert.ErrorIs(t, err, ratelimit.ErrInvalidLimit)
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"repository_class/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader is the header used to accept and return request IDs.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDKey is the gin context key holding the request ID.
+const RequestIDKey = "request_id"
+
+const maxRequestIDLength = 128
+
+// RequestID reuses the X-Request-ID sent by the client, or generates one,
+// echoes it in the response and stores a logger tagged with it, and with the
+// trace ID when a span is active, in the request context.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		c.Set(RequestIDKey, id)
+		c.Header(RequestIDHeader, id)
+
+		ctx := c.Request.Context()
+		l := logger.FromContext(ctx).With("request_id", id)
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			l = l.With("trace_id", sc.TraceID().String())
+		}
+		c.Request = c.Request.WithContext(logger.WithContext(ctx, l))
+
+		c.Next()
+	}
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if r < 0x21 || r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
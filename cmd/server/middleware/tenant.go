@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+
+	"repository_class/pkg/logger"
+	"repository_class/pkg/tenant"
+	"repository_class/pkg/web"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TenantHeader is the header a client names the tenant it acts for with.
+const TenantHeader = "X-Tenant-ID"
+
+// Tenant resolves the tenant of the request and stores it, with a logger
+// tagged with it, in the request context. The tenant of the authenticated
+// principal, set by an authentication middleware running earlier, takes
+// precedence over the X-Tenant-ID header, which takes precedence over
+// fallback. A request left without a tenant, because fallback is empty, or
+// naming an invalid one is rejected.
+func Tenant(fallback string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		id, ok := tenant.FromContext(ctx)
+		if !ok {
+			id = c.GetHeader(TenantHeader)
+		}
+		if id == "" {
+			id = fallback
+		}
+		switch {
+		case id == "":
+			web.Error(c, http.StatusBadRequest, "%s", tenant.ErrMissing.Error())
+			c.Abort()
+			return
+		case !tenant.Valid(id):
+			web.Error(c, http.StatusBadRequest, "%s", tenant.ErrInvalid.Error())
+			c.Abort()
+			return
+		}
+
+		ctx = tenant.WithContext(ctx, id)
+		ctx = logger.WithContext(ctx, logger.FromContext(ctx).With("tenant", id))
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"repository_class/pkg/tenant"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// authenticate stands in for an authentication middleware that knows
+	// the tenant of the principal.
+	authenticate := func(c *gin.Context) {
+		if c.GetHeader("Authorization") != "" {
+			c.Request = c.Request.WithContext(tenant.WithContext(c.Request.Context(), "principal"))
+		}
+	}
+
+	tests := []struct {
+		name     string
+		fallback string
+		header   string
+		auth     bool
+		status   int
+		tenant   string
+	}{
+		{"header", "default", "acme", false, http.StatusOK, "acme"},
+		{"fallback", "default", "", false, http.StatusOK, "default"},
+		{"principal over header", "default", "acme", true, http.StatusOK, "principal"},
+		{"missing", "", "", false, http.StatusBadRequest, ""},
+		{"invalid", "default", "acme corp", false, http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			eng := gin.New()
+			eng.Use(authenticate, Tenant(tt.fallback))
+			eng.GET("/", func(c *gin.Context) {
+				got, _ = tenant.FromContext(c.Request.Context())
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(TenantHeader, tt.header)
+			}
+			if tt.auth {
+				req.Header.Set("Authorization", "Bearer token")
+			}
+			rec := httptest.NewRecorder()
+			eng.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Equal(t, tt.tenant, got)
+		})
+	}
+}
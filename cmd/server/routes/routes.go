@@ -2,59 +2,244 @@ package routes
 
 import (
 	"database/sql"
+	"sync/atomic"
+	"time"
+
+	"repository_class/cmd/server/docs"
+	"repository_class/cmd/server/gql"
 	"repository_class/cmd/server/handlers"
+	"repository_class/cmd/server/middleware"
+	"repository_class/internal/category"
+	"repository_class/internal/costing"
+	"repository_class/internal/domain"
+	"repository_class/internal/exchange"
+	"repository_class/internal/label"
+	"repository_class/internal/location"
+	"repository_class/internal/lot"
+	"repository_class/internal/price"
 	"repository_class/internal/product"
+	"repository_class/internal/reorder"
+	"repository_class/internal/reservation"
+	"repository_class/internal/search"
 	"repository_class/internal/warehouse"
+	"repository_class/pkg/cache"
+	"repository_class/pkg/metrics"
 
 	"github.com/gin-gonic/gin"
 )
 
 type Router interface {
 	MapRoutes()
+	// ProductService and WarehouseService return the instances the routes
+	// use, so other transports can share them.
+	ProductService() product.Service
+	WarehouseService() warehouse.Service
+	// ReservationService is shared with the background expiry worker.
+	ReservationService() reservation.Service
+	// PriceService is shared with the scheduled price worker.
+	PriceService() price.Service
+	// ExchangeService is shared with the rates file loader.
+	ExchangeService() exchange.Service
 }
 
 type router struct {
-	eng *gin.Engine
-	rg  *gin.RouterGroup
-	db  *sql.DB
+	eng      *gin.Engine
+	rg       *gin.RouterGroup
+	db       *sql.DB
+	notifier reorder.Notifier
+	opts     Options
+
+	productService     product.Service
+	warehouseService   warehouse.Service
+	categoryService    category.Service
+	reservationService reservation.Service
+	reorderService     reorder.Service
+	lotService         lot.Service
+	locationService    location.Service
+	priceService       price.Service
+	exchangeService    exchange.Service
+	costingService     costing.Service
+	labelService       label.Service
 }
 
-func NewRouter(eng *gin.Engine, db *sql.DB) Router {
-	return &router{eng: eng, db: db}
+// Options configures the services and routes NewRouter builds.
+type Options struct {
+	// BaseCurrency is the currency exchange rates are quoted against.
+	BaseCurrency string
+	// Cache, when set, serves product and warehouse reads.
+	Cache *cache.Cache
+	// MaxAge is how long clients may keep the product and warehouse
+	// report responses before revalidating them with their ETag.
+	MaxAge time.Duration
+	// API runs before the REST and GraphQL handlers only.
+	API []gin.HandlerFunc
+	// REST runs after API before the /api/v1 handlers only.
+	REST []gin.HandlerFunc
+	// Search indexes the products for full-text search. When it is nil
+	// products are searched with the FULLTEXT index of MySQL.
+	Search search.Index
+	// LabelTemplates add to or replace the built in label templates.
+	LabelTemplates []domain.LabelTemplate
+	// ShuttingDown, once set, makes /readyz answer 503.
+	ShuttingDown *atomic.Bool
+}
+
+// NewRouter builds the services over db. Reorder alerts raised by stock
+// changes go to notifier.
+func NewRouter(eng *gin.Engine, db *sql.DB, notifier reorder.Notifier, opts Options) Router {
+	r := &router{eng: eng, db: db, notifier: notifier, opts: opts}
+	r.buildServices()
+	return r
+}
+
+func (r *router) ProductService() product.Service {
+	return r.productService
+}
+
+func (r *router) WarehouseService() warehouse.Service {
+	return r.warehouseService
+}
+
+func (r *router) ReservationService() reservation.Service {
+	return r.reservationService
+}
+
+func (r *router) PriceService() price.Service {
+	return r.priceService
+}
+
+func (r *router) ExchangeService() exchange.Service {
+	return r.exchangeService
 }
 
 func (r *router) MapRoutes() {
+	r.buildHealthRoutes()
+	r.buildMetricsRoutes()
+	r.buildDocsRoutes()
+
 	r.setGroup()
 
 	r.buildProductsRoutes()
 	r.buildWarehouseRoutes()
+	r.buildCategoryRoutes()
+	r.buildReservationRoutes()
+	r.buildReorderRoutes()
+	r.buildLotRoutes()
+	r.buildLocationRoutes()
+	r.buildPriceRoutes()
+	r.buildExchangeRoutes()
+	r.buildCostingRoutes()
+	r.buildLabelRoutes()
+	r.buildGraphQLRoutes()
+}
+
+// buildServices creates the services once so every route and transport
+// shares them.
+func (r *router) buildServices() {
+	exchangeRepository := exchange.NewRepository(r.db)
+	r.exchangeService = exchange.NewService(&exchangeRepository, r.opts.BaseCurrency)
+
+	reorderRepository := reorder.NewRepository(r.db)
+	r.reorderService = reorder.NewService(&reorderRepository, r.notifier)
+
+	// Lots, reservations and prices write to the products rows too, so
+	// with a cache they drop the products they change from it.
+	storedProducts := product.NewRepository(r.db)
+
+	lotRepository := lot.NewRepository(r.db)
+	if r.opts.Cache != nil {
+		lotRepository = product.NewCachedLots(lotRepository, storedProducts, r.opts.Cache)
+	}
+	r.lotService = lot.NewService(&lotRepository, r.reorderService)
+
+	categoryRepository := category.NewRepository(r.db)
+	r.categoryService = category.NewService(&categoryRepository)
+
+	productRepository := storedProducts
+	if r.opts.Cache != nil {
+		productRepository = product.NewCachedRepository(productRepository, r.opts.Cache)
+	}
+	index := r.opts.Search
+	if index == nil {
+		index = search.NewMySQLIndex(r.db)
+	}
+	productRepository = product.NewIndexedRepository(productRepository, index)
+	r.productService = product.NewService(r.db, &productRepository, categoryRepository, r.lotService, r.exchangeService, index)
+
+	warehouseRepository := warehouse.NewRepository(r.db)
+	if r.opts.Cache != nil {
+		warehouseRepository = warehouse.NewCachedRepository(warehouseRepository, r.opts.Cache)
+	}
+	r.warehouseService = warehouse.NewService(&warehouseRepository, r.exchangeService)
+
+	locationRepository := location.NewRepository(r.db)
+	r.locationService = location.NewService(&locationRepository, warehouseRepository)
+
+	priceRepository := price.NewRepository(r.db)
+	if r.opts.Cache != nil {
+		priceRepository = product.NewCachedPrices(priceRepository, storedProducts, r.opts.Cache)
+	}
+	r.priceService = price.NewService(&priceRepository, warehouseRepository, r.exchangeService)
+
+	costingRepository := costing.NewRepository(r.db)
+	r.costingService = costing.NewService(&costingRepository, warehouseRepository, r.exchangeService)
+
+	reservationRepository := reservation.NewRepository(r.db)
+	if r.opts.Cache != nil {
+		reservationRepository = product.NewCachedReservations(reservationRepository, storedProducts, r.opts.Cache)
+	}
+	r.reservationService = reservation.NewService(&reservationRepository, r.reorderService)
+
+	r.labelService = label.NewService(r.productService, r.locationService, r.opts.LabelTemplates)
+}
+
+func (r *router) buildHealthRoutes() {
+	healthHandler := handlers.NewHealth(r.db, r.opts.ShuttingDown)
+
+	r.eng.GET("/healthz", healthHandler.Live())
+	r.eng.GET("/readyz", healthHandler.Ready())
+}
+
+func (r *router) buildMetricsRoutes() {
+	r.eng.GET("/metrics", gin.WrapH(metrics.Handler()))
+}
+
+func (r *router) buildDocsRoutes() {
+	docsHandler := handlers.NewDocs(docs.Spec())
+
+	r.eng.GET("/openapi.json", docsHandler.Spec())
+	r.eng.GET("/docs", docsHandler.UI())
 }
 
 func (r *router) setGroup() {
-	r.rg = r.eng.Group("/api/v1")
+	r.rg = r.eng.Group("/api/v1", r.opts.API...)
+	r.rg.Use(r.opts.REST...)
 }
 
 func (r *router) buildProductsRoutes() {
-	productRepository := product.NewRepository(r.db)
-	productService := product.NewService(&productRepository)
-	productHandler := handlers.NewProduct(productService)
+	productHandler := handlers.NewProduct(r.productService, r.categoryService)
 	routerProduct := r.rg.Group("/products")
 
 	// Products routes
 	{
 		routerProduct.GET("/", productHandler.GetAll())
+		routerProduct.GET("/search", productHandler.Search())
 		routerProduct.POST("", productHandler.Create())
-		routerProduct.GET("/:id", productHandler.Get())
+		routerProduct.GET("/:id", middleware.ConditionalGET(r.opts.MaxAge), productHandler.Get())
 		routerProduct.DELETE("/:id", productHandler.Delete())
 		routerProduct.PATCH("/:id", productHandler.Update())
 		routerProduct.GET("/:id/withWarehouse", productHandler.GetWithWarehouse())
+		routerProduct.GET("/by-code/:code", productHandler.GetByCode())
+		routerProduct.PUT("/by-code/:code", productHandler.UpsertByCode())
 	}
+
+	// The colon is literal: the batch is a custom method of the collection.
+	batchHandler := handlers.NewProductBatch(r.productService, r.db)
+	r.rg.POST("/products\\:batch", batchHandler.Execute())
 }
 
 func (r *router) buildWarehouseRoutes() {
-	warehouseRepository := warehouse.NewRepository(r.db)
-	warehouseService := warehouse.NewService(&warehouseRepository)
-	warehouseHandler := handlers.NewWarehouse(warehouseService)
+	warehouseHandler := handlers.NewWarehouse(r.warehouseService)
 	routerWarehouse := r.rg.Group("/warehouses")
 
 	{
@@ -63,6 +248,168 @@ func (r *router) buildWarehouseRoutes() {
 		routerWarehouse.GET("/:id", warehouseHandler.Get())
 		routerWarehouse.DELETE("/:id", warehouseHandler.Delete())
 		routerWarehouse.PATCH("/:id", warehouseHandler.Update())
-		routerWarehouse.GET("/reportProducts", warehouseHandler.ReportProducts())
+		routerWarehouse.GET("/reportProducts", middleware.ConditionalGET(r.opts.MaxAge), warehouseHandler.ReportProducts())
+		routerWarehouse.GET("/:id/products", warehouseHandler.Products())
+	}
+}
+
+func (r *router) buildCategoryRoutes() {
+	categoryHandler := handlers.NewCategory(r.categoryService)
+	routerCategory := r.rg.Group("/categories")
+
+	{
+		routerCategory.GET("/", categoryHandler.GetAll())
+		routerCategory.POST("", categoryHandler.Create())
+		routerCategory.GET("/tree", categoryHandler.Tree())
+		routerCategory.GET("/report", categoryHandler.Report())
+		routerCategory.GET("/:id", categoryHandler.Get())
+		routerCategory.PATCH("/:id", categoryHandler.Update())
+		routerCategory.DELETE("/:id", categoryHandler.Delete())
 	}
 }
+
+func (r *router) buildReservationRoutes() {
+	reservationHandler := handlers.NewReservation(r.reservationService)
+
+	routerProduct := r.rg.Group("/products")
+	{
+		routerProduct.POST("/:id/reservations", reservationHandler.Create())
+		routerProduct.GET("/:id/reservations", reservationHandler.ListByProduct())
+		routerProduct.GET("/:id/availability", reservationHandler.Availability())
+	}
+
+	routerReservation := r.rg.Group("/reservations")
+	{
+		routerReservation.GET("/:id", reservationHandler.Get())
+		routerReservation.POST("/:id/confirm", reservationHandler.Confirm())
+		routerReservation.POST("/:id/release", reservationHandler.Release())
+	}
+}
+
+func (r *router) buildReorderRoutes() {
+	reorderHandler := handlers.NewReorder(r.reorderService)
+
+	routerProduct := r.rg.Group("/products")
+	{
+		routerProduct.GET("/low-stock", reorderHandler.LowStock())
+		routerProduct.GET("/:id/reorder", reorderHandler.GetProductPolicy())
+		routerProduct.PUT("/:id/reorder", reorderHandler.SetProductPolicy())
+	}
+
+	routerWarehouse := r.rg.Group("/warehouses")
+	{
+		routerWarehouse.GET("/:id/reorder", reorderHandler.GetWarehousePolicy())
+		routerWarehouse.PUT("/:id/reorder", reorderHandler.SetWarehousePolicy())
+	}
+}
+
+func (r *router) buildLotRoutes() {
+	lotHandler := handlers.NewLot(r.lotService)
+
+	routerProduct := r.rg.Group("/products")
+	{
+		routerProduct.GET("/:id/lots", lotHandler.ListByProduct())
+		routerProduct.POST("/:id/lots", lotHandler.Receive())
+		routerProduct.POST("/:id/lots/issue", lotHandler.Issue())
+	}
+
+	routerLot := r.rg.Group("/lots")
+	{
+		routerLot.GET("/expiring", lotHandler.Expiring())
+		routerLot.GET("/:id", lotHandler.Get())
+		routerLot.PUT("/:id/location", lotHandler.Move())
+	}
+}
+
+func (r *router) buildLocationRoutes() {
+	locationHandler := handlers.NewLocation(r.locationService)
+
+	routerWarehouse := r.rg.Group("/warehouses")
+	{
+		routerWarehouse.GET("/:id/locations", locationHandler.Tree())
+		routerWarehouse.POST("/:id/locations", locationHandler.Create())
+	}
+
+	routerLocation := r.rg.Group("/locations")
+	{
+		routerLocation.GET("/:id", locationHandler.Get())
+		routerLocation.PATCH("/:id", locationHandler.Update())
+		routerLocation.DELETE("/:id", locationHandler.Delete())
+	}
+}
+
+func (r *router) buildPriceRoutes() {
+	priceHandler := handlers.NewPrice(r.priceService)
+
+	routerProduct := r.rg.Group("/products")
+	{
+		routerProduct.GET("/:id/prices", priceHandler.History())
+		routerProduct.POST("/:id/prices", priceHandler.Schedule())
+	}
+
+	routerWarehouse := r.rg.Group("/warehouses")
+	{
+		routerWarehouse.GET("/:id/valuation", priceHandler.Valuation())
+	}
+
+	routerPrice := r.rg.Group("/prices")
+	{
+		routerPrice.DELETE("/:id", priceHandler.Cancel())
+	}
+}
+
+func (r *router) buildExchangeRoutes() {
+	exchangeHandler := handlers.NewExchange(r.exchangeService)
+	routerExchange := r.rg.Group("/exchange-rates")
+
+	{
+		routerExchange.GET("/", exchangeHandler.List())
+		routerExchange.PUT("/:currency", exchangeHandler.Set())
+		routerExchange.DELETE("/:currency", exchangeHandler.Delete())
+	}
+}
+
+func (r *router) buildCostingRoutes() {
+	costingHandler := handlers.NewCosting(r.costingService)
+
+	routerProduct := r.rg.Group("/products")
+	{
+		routerProduct.GET("/:id/cost", costingHandler.Product())
+	}
+
+	routerWarehouse := r.rg.Group("/warehouses")
+	{
+		routerWarehouse.GET("/reportValuation", costingHandler.ReportValuation())
+	}
+}
+
+func (r *router) buildLabelRoutes() {
+	labelHandler := handlers.NewLabel(r.labelService, r.categoryService)
+
+	routerProduct := r.rg.Group("/products")
+	{
+		routerProduct.GET("/:id/barcode", labelHandler.ProductBarcode())
+	}
+
+	routerLocation := r.rg.Group("/locations")
+	{
+		routerLocation.GET("/:id/barcode", labelHandler.LocationBarcode())
+	}
+
+	routerLabel := r.rg.Group("/labels")
+	{
+		routerLabel.GET("/templates", labelHandler.Templates())
+		routerLabel.GET("/products", labelHandler.ProductSheet())
+		routerLabel.GET("/locations", labelHandler.LocationSheet())
+	}
+}
+
+func (r *router) buildGraphQLRoutes() {
+	schema, err := gql.NewSchema(r.productService, r.warehouseService)
+	if err != nil {
+		panic(err)
+	}
+	graphQLHandler := handlers.NewGraphQL(schema, r.productService, r.warehouseService)
+
+	r.eng.Group("", r.opts.API...).POST("/graphql", graphQLHandler.Execute())
+}
@@ -0,0 +1,245 @@
+package routes
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"repository_class/cmd/server/docs"
+	"repository_class/cmd/server/middleware"
+	"repository_class/internal/reorder"
+	"repository_class/internal/testdb"
+	"repository_class/pkg/cache"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// undocumented lists the routes deliberately left out of the OpenAPI
+// document: the document itself and the page rendering it.
+var undocumented = map[string]bool{
+	"GET /openapi.json": true,
+	"GET /docs":         true,
+}
+
+func TestSpecMatchesRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	eng := gin.New()
+	NewRouter(eng, nil, reorder.LogNotifier{}, Options{BaseCurrency: "USD"}).MapRoutes()
+
+	documented := docs.Operations(docs.Spec())
+
+	registered := make(map[string]bool)
+	for _, route := range eng.Routes() {
+		key := docs.OperationKey(route.Method, docs.Path(route.Path))
+		if undocumented[key] {
+			continue
+		}
+		registered[key] = true
+		assert.Truef(t, documented[key], "route %s is not in the OpenAPI document", key)
+	}
+
+	for key := range documented {
+		assert.Truef(t, registered[key], "operation %s has no route", key)
+	}
+}
+
+// newEngine serves the routes over db for the tenant named by the
+// X-Tenant-ID header, acme when there is none.
+func newEngine(db *sql.DB, opts Options) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	eng := gin.New()
+	eng.ContextWithFallback = true
+	opts.BaseCurrency = "USD"
+	opts.API = append(opts.API, middleware.Tenant("acme"))
+	NewRouter(eng, db, reorder.LogNotifier{}, opts).MapRoutes()
+	return eng
+}
+
+func TestOtherTenantsAreNotFound(t *testing.T) {
+	db := testdb.Open(t)
+	warehouseID := testdb.Warehouse(t, db, "acme", 100)
+	productID := testdb.Product(t, db, "acme", warehouseID, "W-1")
+	eng := newEngine(db, Options{})
+
+	paths := []string{
+		fmt.Sprintf("/api/v1/warehouses/%d", warehouseID),
+		fmt.Sprintf("/api/v1/warehouses/reportProducts?id=%d", warehouseID),
+		fmt.Sprintf("/api/v1/products/%d", productID),
+	}
+	for _, path := range paths {
+		rec := httptest.NewRecorder()
+		eng.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, rec.Code, path)
+
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(middleware.TenantHeader, "globex")
+		rec = httptest.NewRecorder()
+		eng.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusNotFound, rec.Code, path)
+	}
+}
+
+func TestReceivedLotChangesProductETag(t *testing.T) {
+	db := testdb.Open(t)
+	productID := testdb.Product(t, db, "acme", testdb.Warehouse(t, db, "acme", 100), "ETAG-1")
+
+	eng := newEngine(db, Options{
+		Cache:  cache.New("test", cache.NewLRU(100), time.Minute),
+		MaxAge: time.Minute,
+	})
+
+	get := func() string {
+		rec := httptest.NewRecorder()
+		eng.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/products/%d", productID), nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		return rec.Header().Get("ETag")
+	}
+	before := get()
+	assert.Equal(t, before, get())
+
+	body := fmt.Sprintf(`{"quantity": 5, "unit_cost": 1, "expiration": %q}`, time.Now().Add(time.Hour).Format(time.RFC3339))
+	rec := httptest.NewRecorder()
+	eng.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/products/%d/lots", productID), strings.NewReader(body)))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	assert.NotEqual(t, before, get())
+}
+
+func TestStockIsOnlyAddedAsLots(t *testing.T) {
+	db := testdb.Open(t)
+	warehouseID := testdb.Warehouse(t, db, "acme", 100)
+	eng := newEngine(db, Options{})
+	expiration := time.Now().Add(time.Hour).Format(time.RFC3339)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		eng.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	product := `{"name": "widget", "code_value": "W-1", "expiration": %q, "id_warehouse": %d, "quantity": %d}`
+	rec := send(http.MethodPost, "/api/v1/products", fmt.Sprintf(product, expiration, warehouseID, 5))
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+	rec = send(http.MethodPut, "/api/v1/products/by-code/W-1", fmt.Sprintf(product, expiration, warehouseID, 5))
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+
+	rec = send(http.MethodPost, "/api/v1/products", fmt.Sprintf(product, expiration, warehouseID, 0))
+	assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var created struct {
+		Data struct{ ID int }
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	path := fmt.Sprintf("/api/v1/products/%d", created.Data.ID)
+
+	rec = send(http.MethodPost, path+"/lots", fmt.Sprintf(`{"quantity": 5, "unit_cost": 2, "expiration": %q}`, expiration))
+	assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	// A PATCH may write stock off, but not add stock without a cost.
+	rec = send(http.MethodPatch, path, `{"quantity": 8}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+	rec = send(http.MethodPatch, path, `{"quantity": 2}`)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), `"quantity":2`)
+}
+
+// batch posts the operations to POST /products:batch and returns the status
+// of each one.
+func batch(t *testing.T, eng *gin.Engine, body string) []int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	eng.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/products:batch", strings.NewReader(body)))
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var res struct {
+		Data []struct{ Status int }
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	statuses := make([]int, len(res.Data))
+	for i, r := range res.Data {
+		statuses[i] = r.Status
+	}
+	return statuses
+}
+
+func TestBatchRoute(t *testing.T) {
+	eng := newEngine(nil, Options{})
+
+	// The escaped colon is literal, so the batch is not taken for a product
+	// id and products/:id still matches.
+	rec := httptest.NewRecorder()
+	eng.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/products:batch", strings.NewReader(`{"operations": []}`)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "a batch holds 1 to")
+
+	rec = httptest.NewRecorder()
+	eng.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/products:other", strings.NewReader(`{}`)))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec = httptest.NewRecorder()
+	eng.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/v1/products/x", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestAtomicBatchRollsBack(t *testing.T) {
+	db := testdb.Open(t)
+	warehouseID := testdb.Warehouse(t, db, "acme", 100)
+	eng := newEngine(db, Options{})
+
+	create := fmt.Sprintf(`{"method": "create", "body": {"name": "widget", "code_value": "W-1", "id_warehouse": %d}}`, warehouseID)
+	statuses := batch(t, eng, fmt.Sprintf(`{"atomic": true, "operations": [%s, %s, {"method": "delete", "id": 1}]}`, create, create))
+	assert.Equal(t, []int{http.StatusFailedDependency, http.StatusConflict, http.StatusFailedDependency}, statuses)
+
+	// The product created by the first operation was rolled back.
+	rec := httptest.NewRecorder()
+	eng.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/products/by-code/W-1", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	statuses = batch(t, eng, fmt.Sprintf(`{"atomic": true, "operations": [%s]}`, create))
+	assert.Equal(t, []int{http.StatusCreated}, statuses)
+}
+
+func TestBatchRunsOperationsIndependently(t *testing.T) {
+	db := testdb.Open(t)
+	warehouseID := testdb.Warehouse(t, db, "acme", 100)
+	productID := testdb.Product(t, db, "acme", warehouseID, "W-1")
+	eng := newEngine(db, Options{})
+
+	statuses := batch(t, eng, fmt.Sprintf(`{"operations": [
+		{"method": "create", "body": {"name": "gadget", "code_value": "G-1", "id_warehouse": %d}},
+		{"method": "create", "body": {"name": "widget", "code_value": "W-1", "id_warehouse": %d}},
+		{"method": "update", "id": %d, "body": {"name": "renamed"}},
+		{"method": "delete", "id": %d},
+		{"method": "rename"}
+	]}`, warehouseID, warehouseID, productID, productID+1000))
+	assert.Equal(t, []int{http.StatusCreated, http.StatusConflict, http.StatusOK, http.StatusNotFound, http.StatusBadRequest}, statuses)
+
+	// The operations that succeeded were kept.
+	rec := httptest.NewRecorder()
+	eng.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/products/by-code/G-1", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = httptest.NewRecorder()
+	eng.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/products/%d", productID), nil))
+	assert.Contains(t, rec.Body.String(), `"name":"renamed"`)
+}
+
+func TestNotReadyWhileShuttingDown(t *testing.T) {
+	shuttingDown := new(atomic.Bool)
+	eng := newEngine(nil, Options{ShuttingDown: shuttingDown})
+	shuttingDown.Store(true)
+
+	rec := httptest.NewRecorder()
+	eng.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.JSONEq(t, `{"status": "shutting_down"}`, rec.Body.String())
+
+	// Liveness is unaffected.
+	rec = httptest.NewRecorder()
+	eng.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
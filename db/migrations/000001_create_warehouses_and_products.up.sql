@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS warehouses (
+    id        INT          NOT NULL AUTO_INCREMENT,
+    name      VARCHAR(255) NOT NULL,
+    adress    VARCHAR(255) NOT NULL,
+    telephone VARCHAR(50)  NOT NULL,
+    capacity  INT          NOT NULL DEFAULT 0,
+    PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS products (
+    id           INT            NOT NULL AUTO_INCREMENT,
+    name         VARCHAR(255)   NOT NULL,
+    quantity     INT            NOT NULL DEFAULT 0,
+    code_value   VARCHAR(255)   NOT NULL,
+    is_published BOOLEAN        NOT NULL DEFAULT FALSE,
+    expiration   DATETIME       NOT NULL,
+    price        DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    id_warehouse INT            NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY uq_products_code_value (code_value),
+    CONSTRAINT fk_products_warehouse FOREIGN KEY (id_warehouse) REFERENCES warehouses (id)
+);
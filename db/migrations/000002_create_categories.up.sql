@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS categories (
+    id        INT          NOT NULL AUTO_INCREMENT,
+    name      VARCHAR(255) NOT NULL,
+    parent_id INT          NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY uq_categories_parent_name (parent_id, name),
+    CONSTRAINT fk_categories_parent FOREIGN KEY (parent_id) REFERENCES categories (id)
+);
+
+ALTER TABLE products
+    ADD COLUMN category_id INT NULL,
+    ADD CONSTRAINT fk_products_category FOREIGN KEY (category_id) REFERENCES categories (id);
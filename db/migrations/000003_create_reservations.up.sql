@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS reservations (
+    id         INT         NOT NULL AUTO_INCREMENT,
+    product_id INT         NOT NULL,
+    quantity   INT         NOT NULL,
+    status     VARCHAR(16) NOT NULL DEFAULT 'active',
+    expires_at DATETIME    NOT NULL,
+    created_at DATETIME    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    KEY idx_reservations_product_status (product_id, status),
+    KEY idx_reservations_status_expires (status, expires_at),
+    CONSTRAINT fk_reservations_product FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE
+);
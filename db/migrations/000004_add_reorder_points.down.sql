@@ -0,0 +1,7 @@
+ALTER TABLE warehouses
+    DROP COLUMN default_reorder_quantity,
+    DROP COLUMN default_reorder_point;
+
+ALTER TABLE products
+    DROP COLUMN reorder_quantity,
+    DROP COLUMN reorder_point;
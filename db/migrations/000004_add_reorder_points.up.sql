@@ -0,0 +1,7 @@
+ALTER TABLE products
+    ADD COLUMN reorder_point    INT NULL,
+    ADD COLUMN reorder_quantity INT NULL;
+
+ALTER TABLE warehouses
+    ADD COLUMN default_reorder_point    INT NULL,
+    ADD COLUMN default_reorder_quantity INT NULL;
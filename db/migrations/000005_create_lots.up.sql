@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS lots (
+    id          INT          NOT NULL AUTO_INCREMENT,
+    product_id  INT          NOT NULL,
+    lot_code    VARCHAR(64)  NOT NULL,
+    quantity    INT          NOT NULL,
+    expiration  DATETIME     NOT NULL,
+    received_at DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE KEY uq_lots_product_code (product_id, lot_code),
+    KEY idx_lots_product_expiration (product_id, expiration),
+    KEY idx_lots_expiration (expiration),
+    CONSTRAINT fk_lots_product FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE
+);
+
+-- Existing stock becomes one lot per product carrying its current expiration.
+INSERT INTO lots (product_id, lot_code, quantity, expiration)
+SELECT id, 'initial', quantity, expiration FROM products WHERE quantity > 0;
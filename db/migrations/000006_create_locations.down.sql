@@ -0,0 +1,5 @@
+ALTER TABLE lots
+    DROP FOREIGN KEY fk_lots_location,
+    DROP COLUMN location_id;
+
+DROP TABLE IF EXISTS locations;
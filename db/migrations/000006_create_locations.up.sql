@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS locations (
+    id           INT         NOT NULL AUTO_INCREMENT,
+    warehouse_id INT         NOT NULL,
+    parent_id    INT         NULL,
+    kind         VARCHAR(8)  NOT NULL,
+    code         VARCHAR(32) NOT NULL,
+    capacity     INT         NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY uq_locations_parent_code (warehouse_id, parent_id, code),
+    CONSTRAINT fk_locations_warehouse FOREIGN KEY (warehouse_id) REFERENCES warehouses (id) ON DELETE CASCADE,
+    CONSTRAINT fk_locations_parent FOREIGN KEY (parent_id) REFERENCES locations (id)
+);
+
+ALTER TABLE lots
+    ADD COLUMN location_id INT NULL,
+    ADD CONSTRAINT fk_lots_location FOREIGN KEY (location_id) REFERENCES locations (id);
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS product_prices (
+    id           INT            NOT NULL AUTO_INCREMENT,
+    product_id   INT            NOT NULL,
+    price        DECIMAL(12, 2) NOT NULL,
+    effective_at DATETIME       NOT NULL,
+    applied      BOOLEAN        NOT NULL DEFAULT FALSE,
+    created_at   DATETIME       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    KEY idx_product_prices_product_effective (product_id, effective_at),
+    KEY idx_product_prices_applied_effective (applied, effective_at),
+    CONSTRAINT fk_product_prices_product FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE
+);
+
+-- The history starts with the price every product has today.
+INSERT INTO product_prices (product_id, price, effective_at, applied)
+SELECT id, price, CURRENT_TIMESTAMP, TRUE FROM products;
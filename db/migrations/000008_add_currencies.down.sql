@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS exchange_rates;
+ALTER TABLE product_prices DROP COLUMN currency;
+ALTER TABLE products DROP COLUMN currency;
@@ -0,0 +1,12 @@
+-- Prices stored so far carried no currency; they are taken to be in USD,
+-- the default base currency.
+ALTER TABLE products ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD' AFTER price;
+ALTER TABLE product_prices ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD' AFTER price;
+
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    currency   CHAR(3)         NOT NULL,
+    rate       DECIMAL(24, 10) NOT NULL,
+    source     VARCHAR(16)     NOT NULL DEFAULT 'manual',
+    updated_at DATETIME        NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (currency)
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS stock_movements;
+ALTER TABLE lots DROP COLUMN cost_currency;
+ALTER TABLE lots DROP COLUMN unit_cost;
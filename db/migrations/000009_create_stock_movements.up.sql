@@ -0,0 +1,22 @@
+-- Lots received so far carried no purchase cost; they are valued at zero in
+-- the currency of their product.
+ALTER TABLE lots ADD COLUMN unit_cost DECIMAL(12, 2) NOT NULL DEFAULT 0 AFTER quantity;
+ALTER TABLE lots ADD COLUMN cost_currency CHAR(3) NOT NULL DEFAULT 'USD' AFTER unit_cost;
+UPDATE lots l INNER JOIN products p ON p.id = l.product_id SET l.cost_currency = p.currency;
+
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id          INT         NOT NULL AUTO_INCREMENT,
+    product_id  INT         NOT NULL,
+    lot_id      INT         NOT NULL,
+    quantity    INT         NOT NULL,
+    occurred_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    PRIMARY KEY (id),
+    KEY idx_stock_movements_product (product_id, occurred_at),
+    CONSTRAINT fk_stock_movements_product FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE,
+    CONSTRAINT fk_stock_movements_lot FOREIGN KEY (lot_id) REFERENCES lots (id) ON DELETE CASCADE
+);
+
+-- Earlier movements were not recorded, so each lot opens the ledger with
+-- what it still holds, received when the lot was.
+INSERT INTO stock_movements (product_id, lot_id, quantity, occurred_at)
+SELECT product_id, id, quantity, received_at FROM lots WHERE quantity > 0;
@@ -0,0 +1,6 @@
+ALTER TABLE products ADD UNIQUE KEY uq_products_code_value (code_value);
+ALTER TABLE products DROP INDEX uq_products_tenant_code_value;
+ALTER TABLE products DROP COLUMN tenant_id;
+
+ALTER TABLE warehouses DROP INDEX idx_warehouses_tenant;
+ALTER TABLE warehouses DROP COLUMN tenant_id;
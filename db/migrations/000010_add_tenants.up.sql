@@ -0,0 +1,8 @@
+-- Data stored so far belongs to the default tenant, the DEFAULT_TENANT of a
+-- deployment that does not set it.
+ALTER TABLE warehouses ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default' AFTER id;
+ALTER TABLE warehouses ADD KEY idx_warehouses_tenant (tenant_id);
+
+ALTER TABLE products ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default' AFTER id;
+ALTER TABLE products ADD UNIQUE KEY uq_products_tenant_code_value (tenant_id, code_value);
+ALTER TABLE products DROP INDEX uq_products_code_value;
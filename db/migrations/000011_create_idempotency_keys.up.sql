@@ -0,0 +1,13 @@
+-- Responses of POST requests sent with an Idempotency-Key, replayed when the
+-- request is retried. A status of 0 marks a request still in flight.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    tenant_id    VARCHAR(64)  NOT NULL,
+    idem_key     VARCHAR(255) NOT NULL,
+    request_hash CHAR(64)     NOT NULL,
+    status       INT          NOT NULL DEFAULT 0,
+    content_type VARCHAR(255) NOT NULL DEFAULT '',
+    body         MEDIUMBLOB   NULL,
+    expires_at   DATETIME(6)  NOT NULL,
+    PRIMARY KEY (tenant_id, idem_key),
+    KEY idx_idempotency_keys_expires_at (expires_at)
+);
@@ -0,0 +1,3 @@
+-- Full-text index of the mysql search index. The ngram parser splits name
+-- and code_value in 2 character tokens, so parts of words and codes match.
+ALTER TABLE products ADD FULLTEXT INDEX ft_products_name_code (name, code_value) WITH PARSER ngram;
@@ -0,0 +1,2 @@
+ALTER TABLE warehouses DROP INDEX uq_warehouses_tenant_code;
+ALTER TABLE warehouses DROP COLUMN warehouse_code;
@@ -0,0 +1,4 @@
+-- Warehouse codes are unique per tenant. Warehouses stored so far have no
+-- code, and NULL codes never collide.
+ALTER TABLE warehouses ADD COLUMN warehouse_code VARCHAR(64) NULL AFTER tenant_id;
+ALTER TABLE warehouses ADD UNIQUE KEY uq_warehouses_tenant_code (tenant_id, warehouse_code);
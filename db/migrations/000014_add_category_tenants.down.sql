@@ -0,0 +1,3 @@
+ALTER TABLE categories ADD UNIQUE KEY uq_categories_parent_name (parent_id, name);
+ALTER TABLE categories DROP INDEX uq_categories_tenant_parent_name;
+ALTER TABLE categories DROP COLUMN tenant_id;
@@ -0,0 +1,8 @@
+-- Categories stored so far belong to the default tenant. Products of other
+-- tenants filed under them leave them, since a tenant only sees its own
+-- categories.
+ALTER TABLE categories ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default' AFTER id;
+ALTER TABLE categories ADD UNIQUE KEY uq_categories_tenant_parent_name (tenant_id, parent_id, name);
+ALTER TABLE categories DROP INDEX uq_categories_parent_name;
+
+UPDATE products p INNER JOIN categories c ON c.id = p.category_id SET p.category_id = NULL WHERE c.tenant_id <> p.tenant_id;
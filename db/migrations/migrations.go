@@ -0,0 +1,80 @@
+// Package migrations embeds the SQL migrations of the service. They follow
+// the golang-migrate naming scheme (NNNNNN_name.up.sql / .down.sql) and are
+// applied with its CLI, which records the applied version in the
+// schema_migrations table.
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// Errors
+var (
+	ErrDirty    = errors.New("database schema is dirty")
+	ErrOutdated = errors.New("database schema is behind the expected version")
+)
+
+// Latest returns the highest migration version shipped with the binary.
+func Latest() (uint, error) {
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil {
+		return 0, err
+	}
+
+	var latest uint
+	for _, e := range entries {
+		if !strings.HasSuffix(e.Name(), ".up.sql") {
+			continue
+		}
+		prefix, _, ok := strings.Cut(e.Name(), "_")
+		if !ok {
+			return 0, fmt.Errorf("migration %s: missing version prefix", e.Name())
+		}
+		v, err := strconv.ParseUint(prefix, 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("migration %s: %w", e.Name(), err)
+		}
+		if uint(v) > latest {
+			latest = uint(v)
+		}
+	}
+
+	return latest, nil
+}
+
+// Check reports whether the schema applied to db is at least the latest
+// embedded version and not left dirty by a failed migration.
+func Check(ctx context.Context, db *sql.DB) error {
+	latest, err := Latest()
+	if err != nil {
+		return err
+	}
+
+	var (
+		version uint
+		dirty   bool
+	)
+	row := db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1;")
+	if err := row.Scan(&version, &dirty); err != nil {
+		return fmt.Errorf("read schema version: %w", err)
+	}
+
+	if dirty {
+		return fmt.Errorf("%w at version %d", ErrDirty, version)
+	}
+	if version < latest {
+		return fmt.Errorf("%w: at %d, want %d", ErrOutdated, version, latest)
+	}
+
+	return nil
+}
@@ -0,0 +1,213 @@
+package category
+
+import (
+	"context"
+	"database/sql"
+
+	"repository_class/internal/domain"
+	"repository_class/pkg/instrument"
+	"repository_class/pkg/tenant"
+	"repository_class/pkg/txn"
+)
+
+// Repository encapsulates the storage of a Category. Every method only sees
+// the categories and products of the tenant in its context; Exists reports
+// false and the others fail with tenant.ErrMissing when there is none.
+type Repository interface {
+	GetAll(ctx context.Context) ([]domain.Category, error)
+	Get(ctx context.Context, id int) (domain.Category, error)
+	Exists(ctx context.Context, id int) bool
+	InUse(ctx context.Context, id int) (bool, error)
+	Save(ctx context.Context, c domain.Category) (int, error)
+	Update(ctx context.Context, c domain.Category) error
+	Delete(ctx context.Context, id int) error
+	Report(ctx context.Context, warehouseID *int) ([]domain.CategoryReport, error)
+}
+
+const repositoryName = "category"
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) GetAll(ctx context.Context) (categories []domain.Category, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := "SELECT id, name, parent_id FROM categories WHERE tenant_id=? ORDER BY id;"
+	ctx, done := instrument.Query(ctx, repositoryName, "GetAll", query)
+	defer func() { done(err) }()
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		c := domain.Category{}
+		if err := rows.Scan(&c.ID, &c.Name, &c.ParentID); err != nil {
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+
+	return categories, rows.Err()
+}
+
+func (r *repository) Get(ctx context.Context, id int) (c domain.Category, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return domain.Category{}, err
+	}
+	query := "SELECT id, name, parent_id FROM categories WHERE id=? AND tenant_id=?;"
+	ctx, done := instrument.Query(ctx, repositoryName, "Get", query)
+	defer func() { done(err) }()
+
+	row := r.db.QueryRowContext(ctx, query, id, tenantID)
+	err = row.Scan(&c.ID, &c.Name, &c.ParentID)
+	if err != nil {
+		return domain.Category{}, err
+	}
+
+	return c, nil
+}
+
+func (r *repository) Exists(ctx context.Context, id int) bool {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return false
+	}
+	query := "SELECT id FROM categories WHERE id=? AND tenant_id=?;"
+	ctx, done := instrument.Query(ctx, repositoryName, "Exists", query)
+
+	row := r.db.QueryRowContext(ctx, query, id, tenantID)
+	err = row.Scan(&id)
+	done(err)
+	return err == nil
+}
+
+// InUse reports whether products or subcategories still reference the
+// category.
+func (r *repository) InUse(ctx context.Context, id int) (inUse bool, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return false, err
+	}
+	query := "SELECT EXISTS(SELECT 1 FROM categories WHERE parent_id=? AND tenant_id=?) OR EXISTS(SELECT 1 FROM products WHERE category_id=? AND tenant_id=?);"
+	ctx, done := instrument.Query(ctx, repositoryName, "InUse", query)
+	defer func() { done(err) }()
+
+	row := r.db.QueryRowContext(ctx, query, id, tenantID, id, tenantID)
+	err = row.Scan(&inUse)
+	return inUse, err
+}
+
+func (r *repository) Save(ctx context.Context, c domain.Category) (_ int, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return 0, err
+	}
+	query := "INSERT INTO categories (tenant_id, name, parent_id) VALUES (?, ?, ?)"
+	ctx, done := instrument.Query(ctx, repositoryName, "Save", query)
+	defer func() { done(err) }()
+
+	res, err := r.db.ExecContext(ctx, query, tenantID, c.Name, c.ParentID)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+func (r *repository) Update(ctx context.Context, c domain.Category) (err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return err
+	}
+	query := "UPDATE categories SET name=?, parent_id=? WHERE id=? AND tenant_id=?"
+	ctx, done := instrument.Query(ctx, repositoryName, "Update", query)
+	defer func() { done(err) }()
+
+	_, err = r.db.ExecContext(ctx, query, c.Name, c.ParentID, c.ID, tenantID)
+	return err
+}
+
+func (r *repository) Delete(ctx context.Context, id int) (err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return err
+	}
+	query := "DELETE FROM categories WHERE id=? AND tenant_id=?"
+	ctx, done := instrument.Query(ctx, repositoryName, "Delete", query)
+	defer func() { done(err) }()
+
+	res, err := r.db.ExecContext(ctx, query, id, tenantID)
+	if err != nil {
+		// A product or subcategory may have been added since InUse.
+		if txn.IsReferenced(err) {
+			return ErrInUse
+		}
+		return err
+	}
+
+	affect, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affect < 1 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// Report aggregates the products of the tenant by their direct category,
+// optionally only those stored in one warehouse. Products without a category
+// are grouped under a nil CategoryID.
+func (r *repository) Report(ctx context.Context, warehouseID *int) (reports []domain.CategoryReport, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	args := []interface{}{tenantID}
+	query := "SELECT c.id, COALESCE(c.name, 'uncategorized'), count(p.id), COALESCE(SUM(p.quantity), 0) FROM products p " +
+		"LEFT JOIN categories c ON c.id = p.category_id WHERE p.tenant_id = ? "
+	if warehouseID != nil {
+		query += "AND p.id_warehouse = ? "
+		args = append(args, *warehouseID)
+	}
+	query += "GROUP BY c.id, c.name ORDER BY c.id;"
+
+	ctx, done := instrument.Query(ctx, repositoryName, "Report", query)
+	defer func() { done(err) }()
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		c := domain.CategoryReport{}
+		if err := rows.Scan(&c.CategoryID, &c.CategoryName, &c.ProductCount, &c.Units); err != nil {
+			return nil, err
+		}
+		reports = append(reports, c)
+	}
+
+	return reports, rows.Err()
+}
@@ -0,0 +1,81 @@
+package category
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"repository_class/internal/domain"
+	"repository_class/internal/testdb"
+	"repository_class/pkg/tenant"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenantIsolation(t *testing.T) {
+	db := testdb.Open(t)
+	rp := NewRepository(db)
+	acme := tenant.WithContext(context.Background(), "acme")
+	globex := tenant.WithContext(context.Background(), "globex")
+	warehouseID := testdb.Warehouse(t, db, "acme", 0)
+	productID := testdb.Product(t, db, "acme", warehouseID, "W-1")
+
+	id, err := rp.Save(acme, domain.Category{Name: "tools"})
+	assert.NoError(t, err)
+	_, err = db.Exec("UPDATE products SET category_id=? WHERE id=?;", id, productID)
+	assert.NoError(t, err)
+
+	_, err = rp.Get(globex, id)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	categories, err := rp.GetAll(globex)
+	assert.NoError(t, err)
+	assert.Empty(t, categories)
+	assert.False(t, rp.Exists(globex, id))
+	inUse, err := rp.InUse(globex, id)
+	assert.NoError(t, err)
+	assert.False(t, inUse)
+	reports, err := rp.Report(globex, &warehouseID)
+	assert.NoError(t, err)
+	assert.Empty(t, reports)
+
+	assert.NoError(t, rp.Update(globex, domain.Category{ID: id, Name: "stolen"}))
+	assert.ErrorIs(t, rp.Delete(globex, id), ErrNotFound)
+
+	// Each tenant names its own categories.
+	_, err = rp.Save(globex, domain.Category{Name: "tools"})
+	assert.NoError(t, err)
+
+	c, err := rp.Get(acme, id)
+	assert.NoError(t, err)
+	assert.Equal(t, "tools", c.Name)
+	inUse, err = rp.InUse(acme, id)
+	assert.NoError(t, err)
+	assert.True(t, inUse)
+	reports, err = rp.Report(acme, &warehouseID)
+	assert.NoError(t, err)
+	assert.Len(t, reports, 1)
+}
+
+func TestInUse(t *testing.T) {
+	db := testdb.Open(t)
+	rp := NewRepository(db)
+	ctx := tenant.WithContext(context.Background(), "acme")
+
+	root, err := rp.Save(ctx, domain.Category{Name: "tools"})
+	assert.NoError(t, err)
+	child, err := rp.Save(ctx, domain.Category{Name: "hammers", ParentID: &root})
+	assert.NoError(t, err)
+
+	inUse, err := rp.InUse(ctx, root)
+	assert.NoError(t, err)
+	assert.True(t, inUse)
+	inUse, err = rp.InUse(ctx, child)
+	assert.NoError(t, err)
+	assert.False(t, inUse)
+
+	// Delete refuses a category still referenced, whatever InUse said.
+	assert.ErrorIs(t, rp.Delete(ctx, root), ErrInUse)
+	assert.NoError(t, rp.Delete(ctx, child))
+	assert.NoError(t, rp.Delete(ctx, root))
+	assert.ErrorIs(t, rp.Delete(ctx, root), ErrNotFound)
+}
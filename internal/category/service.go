@@ -0,0 +1,223 @@
+package category
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"repository_class/internal/domain"
+
+	"go.opentelemetry.io/otel"
+)
+
+// Errors
+var (
+	ErrNotFound       = errors.New("category not found")
+	ErrParentNotFound = errors.New("parent category not found")
+	ErrCycle          = errors.New("category cannot be its own ancestor")
+	ErrInUse          = errors.New("category still has products or subcategories")
+	ErrInvalidStruct  = errors.New("invalid input structure for category")
+)
+
+type Service interface {
+	GetAll(ctx context.Context) ([]domain.Category, error)
+	Get(ctx context.Context, id int) (domain.Category, error)
+	// Tree returns the root categories with their subcategories nested.
+	Tree(ctx context.Context) ([]domain.CategoryNode, error)
+	// Descendants returns id followed by the ids of every category below it.
+	Descendants(ctx context.Context, id int) ([]int, error)
+	Create(ctx context.Context, c domain.Category) (domain.Category, error)
+	Update(ctx context.Context, c domain.Category, id int) (domain.Category, error)
+	Delete(ctx context.Context, id int) error
+	// Report aggregates products by category, in every warehouse when
+	// warehouseID is nil.
+	Report(ctx context.Context, warehouseID *int) ([]domain.CategoryReport, error)
+}
+
+var tracer = otel.Tracer("repository_class/internal/category")
+
+type service struct {
+	repo Repository
+}
+
+func NewService(repo *Repository) Service {
+	return &service{repo: *repo}
+}
+
+func (s *service) GetAll(ctx context.Context) ([]domain.Category, error) {
+	ctx, span := tracer.Start(ctx, "category.Service.GetAll")
+	defer span.End()
+
+	categories, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if categories == nil {
+		return []domain.Category{}, nil
+	}
+	return categories, nil
+}
+
+func (s *service) Get(ctx context.Context, id int) (domain.Category, error) {
+	ctx, span := tracer.Start(ctx, "category.Service.Get")
+	defer span.End()
+
+	c, err := s.repo.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Category{}, ErrNotFound
+		}
+		return domain.Category{}, err
+	}
+	return c, nil
+}
+
+func (s *service) Tree(ctx context.Context) ([]domain.CategoryNode, error) {
+	ctx, span := tracer.Start(ctx, "category.Service.Tree")
+	defer span.End()
+
+	categories, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[int][]domain.Category)
+	var roots []domain.Category
+	for _, c := range categories {
+		if c.ParentID == nil {
+			roots = append(roots, c)
+			continue
+		}
+		children[*c.ParentID] = append(children[*c.ParentID], c)
+	}
+
+	var build func(c domain.Category) domain.CategoryNode
+	build = func(c domain.Category) domain.CategoryNode {
+		node := domain.CategoryNode{Category: c, Children: []domain.CategoryNode{}}
+		for _, child := range children[c.ID] {
+			node.Children = append(node.Children, build(child))
+		}
+		return node
+	}
+
+	tree := []domain.CategoryNode{}
+	for _, root := range roots {
+		tree = append(tree, build(root))
+	}
+	return tree, nil
+}
+
+func (s *service) Descendants(ctx context.Context, id int) ([]int, error) {
+	ctx, span := tracer.Start(ctx, "category.Service.Descendants")
+	defer span.End()
+
+	categories, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[int][]int)
+	found := false
+	for _, c := range categories {
+		if c.ID == id {
+			found = true
+		}
+		if c.ParentID != nil {
+			children[*c.ParentID] = append(children[*c.ParentID], c.ID)
+		}
+	}
+	if !found {
+		return nil, ErrNotFound
+	}
+
+	ids := []int{id}
+	for i := 0; i < len(ids); i++ {
+		ids = append(ids, children[ids[i]]...)
+	}
+	return ids, nil
+}
+
+func (s *service) Create(ctx context.Context, c domain.Category) (domain.Category, error) {
+	ctx, span := tracer.Start(ctx, "category.Service.Create")
+	defer span.End()
+
+	if c.Name == "" {
+		return domain.Category{}, ErrInvalidStruct
+	}
+	if c.ParentID != nil && !s.repo.Exists(ctx, *c.ParentID) {
+		return domain.Category{}, ErrParentNotFound
+	}
+
+	id, err := s.repo.Save(ctx, c)
+	if err != nil {
+		return domain.Category{}, err
+	}
+	return s.repo.Get(ctx, id)
+}
+
+func (s *service) Update(ctx context.Context, c domain.Category, id int) (domain.Category, error) {
+	ctx, span := tracer.Start(ctx, "category.Service.Update")
+	defer span.End()
+
+	current, err := s.repo.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Category{}, ErrNotFound
+		}
+		return domain.Category{}, err
+	}
+
+	if c.Name != "" {
+		current.Name = c.Name
+	}
+	if c.ParentID != nil {
+		if !s.repo.Exists(ctx, *c.ParentID) {
+			return domain.Category{}, ErrParentNotFound
+		}
+		// Moving a category under itself or one of its descendants would
+		// detach that branch from the tree.
+		below, err := s.Descendants(ctx, id)
+		if err != nil {
+			return domain.Category{}, err
+		}
+		for _, d := range below {
+			if d == *c.ParentID {
+				return domain.Category{}, ErrCycle
+			}
+		}
+		current.ParentID = c.ParentID
+	}
+
+	if err := s.repo.Update(ctx, current); err != nil {
+		return domain.Category{}, err
+	}
+	return current, nil
+}
+
+func (s *service) Delete(ctx context.Context, id int) error {
+	ctx, span := tracer.Start(ctx, "category.Service.Delete")
+	defer span.End()
+
+	inUse, err := s.repo.InUse(ctx, id)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return ErrInUse
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *service) Report(ctx context.Context, warehouseID *int) ([]domain.CategoryReport, error) {
+	ctx, span := tracer.Start(ctx, "category.Service.Report")
+	defer span.End()
+
+	reports, err := s.repo.Report(ctx, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	if reports == nil {
+		return []domain.CategoryReport{}, nil
+	}
+	return reports, nil
+}
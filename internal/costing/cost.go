@@ -0,0 +1,78 @@
+package costing
+
+import (
+	"repository_class/internal/domain"
+
+	"github.com/shopspring/decimal"
+)
+
+// Value replays the movements of one product, in the order they happened,
+// and returns the units left with their cost under method. The unit costs
+// must all be in one currency. An issue of more than is on hand only takes
+// what is there.
+func Value(method string, movements []domain.StockMovement) (int, decimal.Decimal, error) {
+	switch method {
+	case domain.CostFIFO:
+		units, value := fifo(movements)
+		return units, value, nil
+	case domain.CostAverage:
+		units, value := average(movements)
+		return units, value, nil
+	}
+	return 0, decimal.Zero, ErrInvalidMethod
+}
+
+// layer is what is left of one receipt.
+type layer struct {
+	quantity int
+	unitCost decimal.Decimal
+}
+
+// fifo lets every issue consume the oldest receipts first, whichever lot it
+// physically came from, and values the layers left at their own cost.
+func fifo(movements []domain.StockMovement) (int, decimal.Decimal) {
+	var layers []layer
+	for _, m := range movements {
+		if m.Quantity > 0 {
+			layers = append(layers, layer{quantity: m.Quantity, unitCost: m.UnitCost})
+			continue
+		}
+		for out := -m.Quantity; out > 0 && len(layers) > 0; {
+			taken := min(out, layers[0].quantity)
+			layers[0].quantity -= taken
+			out -= taken
+			if layers[0].quantity == 0 {
+				layers = layers[1:]
+			}
+		}
+	}
+
+	units, value := 0, decimal.Zero
+	for _, l := range layers {
+		units += l.quantity
+		value = value.Add(l.unitCost.Mul(decimal.NewFromInt(int64(l.quantity))))
+	}
+	return units, value
+}
+
+// average keeps a moving weighted average: every receipt blends its cost
+// into the stock on hand and every issue leaves at the current average,
+// which it does not change.
+func average(movements []domain.StockMovement) (int, decimal.Decimal) {
+	units, value := 0, decimal.Zero
+	for _, m := range movements {
+		if m.Quantity > 0 {
+			units += m.Quantity
+			value = value.Add(m.UnitCost.Mul(decimal.NewFromInt(int64(m.Quantity))))
+			continue
+		}
+		out := min(-m.Quantity, units)
+		if out == units {
+			units, value = 0, decimal.Zero
+			continue
+		}
+		value = value.Sub(value.Mul(decimal.NewFromInt(int64(out))).Div(decimal.NewFromInt(int64(units))))
+		units -= out
+	}
+	return units, value
+}
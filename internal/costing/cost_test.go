@@ -0,0 +1,53 @@
+package costing
+
+import (
+	"testing"
+
+	"repository_class/internal/domain"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func movement(quantity int, unitCost string) domain.StockMovement {
+	return domain.StockMovement{Quantity: quantity, UnitCost: decimal.RequireFromString(unitCost)}
+}
+
+func TestValue(t *testing.T) {
+	movements := []domain.StockMovement{
+		movement(10, "1.00"),
+		movement(10, "2.00"),
+		movement(-15, "2.00"),
+		movement(5, "3.00"),
+	}
+
+	tests := []struct {
+		name      string
+		method    string
+		movements []domain.StockMovement
+		units     int
+		value     string
+	}{
+		{"fifo keeps the latest receipts", domain.CostFIFO, movements, 10, "25"},
+		{"average blends receipts", domain.CostAverage, movements, 10, "22.5"},
+		{"fifo without movements", domain.CostFIFO, nil, 0, "0"},
+		{"average after an issue", domain.CostAverage, movements[:3:3], 5, "7.5"},
+		{"fifo over issue", domain.CostFIFO, []domain.StockMovement{movement(2, "1.00"), movement(-5, "1.00")}, 0, "0"},
+		{"average over issue", domain.CostAverage, []domain.StockMovement{movement(2, "1.00"), movement(-5, "1.00"), movement(1, "4.00")}, 1, "4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			units, value, err := Value(tt.method, tt.movements)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.units, units)
+			assert.True(t, decimal.RequireFromString(tt.value).Equal(value), "value %s", value)
+		})
+	}
+}
+
+func TestValueInvalidMethod(t *testing.T) {
+	_, _, err := Value("lifo", nil)
+
+	assert.ErrorIs(t, err, ErrInvalidMethod)
+}
@@ -0,0 +1,136 @@
+package costing
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"repository_class/internal/domain"
+	"repository_class/pkg/instrument"
+	"repository_class/pkg/tenant"
+)
+
+// Repository reads the stock movement ledger the lots keep, with the
+// purchase cost of the lot each movement belongs to. Every method only sees
+// the products of the tenant in its context and fails with tenant.ErrMissing
+// when there is none.
+type Repository interface {
+	// Product returns the product with its stock not valued yet.
+	Product(ctx context.Context, id int) (domain.ProductCost, error)
+	// Products returns the products now in the warehouse, by id.
+	Products(ctx context.Context, warehouseID int) ([]domain.ProductCost, error)
+	// Movements returns the movements of the product up to at in the order
+	// they happened.
+	Movements(ctx context.Context, productID int, at time.Time) ([]domain.StockMovement, error)
+	// WarehouseMovements returns the movements up to at of the products now
+	// in the warehouse, by product and then in the order they happened.
+	WarehouseMovements(ctx context.Context, warehouseID int, at time.Time) ([]domain.StockMovement, error)
+}
+
+const repositoryName = "costing"
+
+// movementColumns selects a movement m with the cost of its lot l. Movements
+// of a product are written under the lock of its row, so their ids follow
+// the order they happened in.
+const movementColumns = "m.id, m.product_id, m.lot_id, m.quantity, l.unit_cost, l.cost_currency, m.occurred_at"
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) Product(ctx context.Context, id int) (p domain.ProductCost, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return domain.ProductCost{}, err
+	}
+	query := "SELECT id, name, code_value FROM products WHERE id=? AND tenant_id=?;"
+	ctx, done := instrument.Query(ctx, repositoryName, "Product", query)
+	defer func() { done(err) }()
+
+	if err = r.db.QueryRowContext(ctx, query, id, tenantID).Scan(&p.ProductID, &p.Name, &p.CodeValue); err != nil {
+		return domain.ProductCost{}, err
+	}
+
+	return p, nil
+}
+
+func (r *repository) Products(ctx context.Context, warehouseID int) (products []domain.ProductCost, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := "SELECT id, name, code_value FROM products WHERE id_warehouse=? AND tenant_id=? ORDER BY id;"
+	ctx, done := instrument.Query(ctx, repositoryName, "Products", query)
+	defer func() { done(err) }()
+
+	rows, err := r.db.QueryContext(ctx, query, warehouseID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		p := domain.ProductCost{}
+		if err := rows.Scan(&p.ProductID, &p.Name, &p.CodeValue); err != nil {
+			return nil, err
+		}
+		products = append(products, p)
+	}
+
+	return products, rows.Err()
+}
+
+func (r *repository) Movements(ctx context.Context, productID int, at time.Time) (_ []domain.StockMovement, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := "SELECT " + movementColumns + " FROM stock_movements m " +
+		"INNER JOIN lots l ON l.id = m.lot_id " +
+		"INNER JOIN products p ON p.id = m.product_id " +
+		"WHERE m.product_id=? AND p.tenant_id=? AND m.occurred_at<=? ORDER BY m.id;"
+	ctx, done := instrument.Query(ctx, repositoryName, "Movements", query)
+	defer func() { done(err) }()
+
+	return r.movements(ctx, query, productID, tenantID, at)
+}
+
+func (r *repository) WarehouseMovements(ctx context.Context, warehouseID int, at time.Time) (_ []domain.StockMovement, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := "SELECT " + movementColumns + " FROM stock_movements m " +
+		"INNER JOIN lots l ON l.id = m.lot_id " +
+		"INNER JOIN products p ON p.id = m.product_id " +
+		"WHERE p.id_warehouse=? AND p.tenant_id=? AND m.occurred_at<=? ORDER BY m.product_id, m.id;"
+	ctx, done := instrument.Query(ctx, repositoryName, "WarehouseMovements", query)
+	defer func() { done(err) }()
+
+	return r.movements(ctx, query, warehouseID, tenantID, at)
+}
+
+func (r *repository) movements(ctx context.Context, query string, args ...interface{}) ([]domain.StockMovement, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var movements []domain.StockMovement
+	for rows.Next() {
+		m := domain.StockMovement{}
+		if err := rows.Scan(&m.ID, &m.ProductID, &m.LotID, &m.Quantity, &m.UnitCost, &m.Currency, &m.OccurredAt); err != nil {
+			return nil, err
+		}
+		movements = append(movements, m)
+	}
+
+	return movements, rows.Err()
+}
@@ -0,0 +1,84 @@
+package costing
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"repository_class/internal/domain"
+	"repository_class/internal/testdb"
+	"repository_class/pkg/tenant"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMovementsValuation(t *testing.T) {
+	db := testdb.Open(t)
+	rp := NewRepository(db)
+	ctx := tenant.WithContext(context.Background(), "acme")
+	warehouseID := testdb.Warehouse(t, db, "acme", 0)
+	productID := testdb.Product(t, db, "acme", warehouseID, "W-1")
+	other := testdb.Product(t, db, "acme", warehouseID, "W-2")
+	expiration := time.Now().Add(24 * time.Hour)
+
+	cheap := testdb.Lot(t, db, domain.Lot{ProductID: productID, LotCode: "L-1", Quantity: 10, UnitCost: decimal.NewFromInt(1), Expiration: expiration})
+	testdb.Lot(t, db, domain.Lot{ProductID: productID, LotCode: "L-2", Quantity: 10, UnitCost: decimal.NewFromInt(2), Expiration: expiration})
+	testdb.Lot(t, db, domain.Lot{ProductID: other, LotCode: "L-3", Quantity: 4, UnitCost: decimal.NewFromInt(5), Expiration: expiration})
+	_, err := db.Exec("INSERT INTO stock_movements (product_id, lot_id, quantity) VALUES (?, ?, -15);", productID, cheap)
+	assert.NoError(t, err)
+	// A movement after the valuation date is left out.
+	_, err = db.Exec("INSERT INTO stock_movements (product_id, lot_id, quantity, occurred_at) VALUES (?, ?, -5, ?);",
+		productID, cheap, time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+
+	movements, err := rp.Movements(ctx, productID, time.Now())
+	assert.NoError(t, err)
+	if assert.Len(t, movements, 3) {
+		assert.Equal(t, []int{10, 10, -15}, []int{movements[0].Quantity, movements[1].Quantity, movements[2].Quantity})
+		assert.True(t, decimal.NewFromInt(2).Equal(movements[1].UnitCost))
+	}
+
+	// Each movement carries the cost of its lot, so FIFO keeps the five
+	// dearest units.
+	units, value, err := Value(domain.CostFIFO, movements)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, units)
+	assert.True(t, decimal.NewFromInt(10).Equal(value), "value %s", value)
+
+	movements, err = rp.WarehouseMovements(ctx, warehouseID, time.Now())
+	assert.NoError(t, err)
+	if assert.Len(t, movements, 4) {
+		assert.Equal(t, other, movements[3].ProductID)
+	}
+	products, err := rp.Products(ctx, warehouseID)
+	assert.NoError(t, err)
+	assert.Len(t, products, 2)
+}
+
+func TestTenantIsolation(t *testing.T) {
+	db := testdb.Open(t)
+	rp := NewRepository(db)
+	acme := tenant.WithContext(context.Background(), "acme")
+	globex := tenant.WithContext(context.Background(), "globex")
+	warehouseID := testdb.Warehouse(t, db, "acme", 0)
+	productID := testdb.Product(t, db, "acme", warehouseID, "W-1")
+	testdb.Lot(t, db, domain.Lot{ProductID: productID, LotCode: "L-1", Quantity: 5, Expiration: time.Now().Add(time.Hour)})
+
+	_, err := rp.Product(globex, productID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	products, err := rp.Products(globex, warehouseID)
+	assert.NoError(t, err)
+	assert.Empty(t, products)
+	movements, err := rp.Movements(globex, productID, time.Now())
+	assert.NoError(t, err)
+	assert.Empty(t, movements)
+	movements, err = rp.WarehouseMovements(globex, warehouseID, time.Now())
+	assert.NoError(t, err)
+	assert.Empty(t, movements)
+
+	movements, err = rp.Movements(acme, productID, time.Now())
+	assert.NoError(t, err)
+	assert.Len(t, movements, 1)
+}
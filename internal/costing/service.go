@@ -0,0 +1,169 @@
+package costing
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"repository_class/internal/domain"
+	"repository_class/internal/exchange"
+	"repository_class/internal/warehouse"
+
+	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel"
+)
+
+// Errors
+var (
+	ErrProductNotFound   = errors.New("product not found")
+	ErrWarehouseNotFound = errors.New("warehouse not found")
+	ErrInvalidMethod     = errors.New("costing method must be fifo or average")
+)
+
+type Service interface {
+	// Product values the stock of a product on hand at the date at its
+	// purchase cost under method, FIFO when it is empty. Costs are converted
+	// into currency with the current rates, the base currency when it is
+	// empty.
+	Product(ctx context.Context, productID int, method string, at time.Time, currency string) (domain.ProductCost, error)
+	// Warehouse builds the report of a warehouse with the stock of each of
+	// its products valued as Product does.
+	Warehouse(ctx context.Context, warehouseID int, method string, at time.Time, currency string) (domain.WarehouseCostReport, error)
+}
+
+var tracer = otel.Tracer("repository_class/internal/costing")
+
+type service struct {
+	repo       Repository
+	warehouses warehouse.Repository
+	rates      exchange.Service
+}
+
+func NewService(repo *Repository, warehouses warehouse.Repository, rates exchange.Service) Service {
+	return &service{repo: *repo, warehouses: warehouses, rates: rates}
+}
+
+func (s *service) Product(ctx context.Context, productID int, method string, at time.Time, currency string) (domain.ProductCost, error) {
+	ctx, span := tracer.Start(ctx, "costing.Service.Product")
+	defer span.End()
+
+	method, target, rates, err := s.prepare(ctx, method, currency)
+	if err != nil {
+		return domain.ProductCost{}, err
+	}
+
+	p, err := s.repo.Product(ctx, productID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ProductCost{}, ErrProductNotFound
+		}
+		return domain.ProductCost{}, err
+	}
+	movements, err := s.repo.Movements(ctx, productID, at)
+	if err != nil {
+		return domain.ProductCost{}, err
+	}
+	return cost(p, method, movements, rates, target)
+}
+
+func (s *service) Warehouse(ctx context.Context, warehouseID int, method string, at time.Time, currency string) (domain.WarehouseCostReport, error) {
+	ctx, span := tracer.Start(ctx, "costing.Service.Warehouse")
+	defer span.End()
+
+	method, target, rates, err := s.prepare(ctx, method, currency)
+	if err != nil {
+		return domain.WarehouseCostReport{}, err
+	}
+
+	w, err := s.warehouses.Get(ctx, warehouseID)
+	if err != nil {
+		return domain.WarehouseCostReport{}, err
+	}
+	if w.ID == 0 {
+		return domain.WarehouseCostReport{}, ErrWarehouseNotFound
+	}
+
+	products, err := s.repo.Products(ctx, warehouseID)
+	if err != nil {
+		return domain.WarehouseCostReport{}, err
+	}
+	movements, err := s.repo.WarehouseMovements(ctx, warehouseID, at)
+	if err != nil {
+		return domain.WarehouseCostReport{}, err
+	}
+	byProduct := make(map[int][]domain.StockMovement)
+	for _, m := range movements {
+		byProduct[m.ProductID] = append(byProduct[m.ProductID], m)
+	}
+
+	report := domain.WarehouseCostReport{
+		WarehouseReport: domain.WarehouseReport{WarehouseName: w.Name, ProductCount: len(products)},
+		WarehouseID:     warehouseID,
+		Method:          method,
+		At:              at,
+		Currency:        target,
+		Products:        []domain.ProductCost{},
+	}
+	for _, p := range products {
+		p, err := cost(p, method, byProduct[p.ProductID], rates, target)
+		if err != nil {
+			return domain.WarehouseCostReport{}, err
+		}
+		report.Units += p.Quantity
+		report.Value = report.Value.Add(p.Value)
+		report.Products = append(report.Products, p)
+	}
+	return report, nil
+}
+
+// prepare checks the method and the target currency, applying their
+// defaults, and takes the rates to convert costs with.
+func (s *service) prepare(ctx context.Context, method, currency string) (string, string, exchange.Rates, error) {
+	switch method {
+	case "":
+		method = domain.CostFIFO
+	case domain.CostFIFO, domain.CostAverage:
+	default:
+		return "", "", exchange.Rates{}, ErrInvalidMethod
+	}
+
+	target := s.rates.Base()
+	if currency != "" {
+		code, err := exchange.ParseCurrency(currency)
+		if err != nil {
+			return "", "", exchange.Rates{}, err
+		}
+		target = code
+	}
+	rates, err := s.rates.Rates(ctx)
+	if err != nil {
+		return "", "", exchange.Rates{}, err
+	}
+	return method, target, rates, nil
+}
+
+// cost values the movements of p in the target currency. Unit costs are
+// converted before they are replayed, since receipts of one product may be
+// bought in different currencies.
+func cost(p domain.ProductCost, method string, movements []domain.StockMovement, rates exchange.Rates, target string) (domain.ProductCost, error) {
+	for i, m := range movements {
+		unitCost, err := rates.Convert(m.UnitCost, m.Currency, target)
+		if err != nil {
+			return domain.ProductCost{}, err
+		}
+		movements[i].UnitCost, movements[i].Currency = unitCost, target
+	}
+
+	units, value, err := Value(method, movements)
+	if err != nil {
+		return domain.ProductCost{}, err
+	}
+	p.Quantity = units
+	p.Value = exchange.Round(value, target)
+	p.Currency = target
+	if units > 0 {
+		p.UnitCost = exchange.Round(value.Div(decimal.NewFromInt(int64(units))), target)
+	}
+	return p, nil
+}
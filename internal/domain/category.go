@@ -0,0 +1,23 @@
+package domain
+
+// Category groups products. Categories form a tree through ParentID; a nil
+// ParentID marks a root category.
+type Category struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	ParentID *int   `json:"parent_id"`
+}
+
+// CategoryNode is a category with its subcategories, used to render the tree.
+type CategoryNode struct {
+	Category
+	Children []CategoryNode `json:"children"`
+}
+
+// CategoryReport aggregates the products of one category.
+type CategoryReport struct {
+	CategoryID   *int   `json:"category_id"`
+	CategoryName string `json:"category_name"`
+	ProductCount int    `json:"product_count"`
+	Units        int    `json:"units"`
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Costing methods
+const (
+	// CostFIFO values the stock on hand at the cost of the latest receipts,
+	// as issues are taken to consume the oldest ones first.
+	CostFIFO = "fifo"
+	// CostAverage values the stock on hand at its moving weighted average
+	// cost, updated on every receipt.
+	CostAverage = "average"
+)
+
+// StockMovement is one change in the stock of a product: positive when a
+// lot is received, negative when stock is issued or written off from it.
+// UnitCost and Currency are the purchase cost of the lot.
+type StockMovement struct {
+	ID         int
+	ProductID  int
+	LotID      int
+	Quantity   int
+	UnitCost   decimal.Decimal
+	Currency   string
+	OccurredAt time.Time
+}
+
+// ProductCost is the stock of a product on hand at a date valued at cost.
+// UnitCost is Value spread over Quantity, zero when nothing is on hand.
+type ProductCost struct {
+	ProductID int             `json:"product_id"`
+	Name      string          `json:"name"`
+	CodeValue string          `json:"code_value"`
+	Quantity  int             `json:"quantity"`
+	UnitCost  decimal.Decimal `json:"unit_cost"`
+	Value     decimal.Decimal `json:"value"`
+	Currency  string          `json:"currency"`
+}
+
+// WarehouseCostReport extends the warehouse report with the stock of every
+// product of the warehouse valued at cost with Method.
+type WarehouseCostReport struct {
+	WarehouseReport
+	WarehouseID int             `json:"warehouse_id"`
+	Method      string          `json:"method"`
+	At          time.Time       `json:"at"`
+	Currency    string          `json:"currency"`
+	Units       int             `json:"units"`
+	Value       decimal.Decimal `json:"value"`
+	Products    []ProductCost   `json:"products"`
+}
@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// IdempotentRequest is a POST request sent with an Idempotency-Key and,
+// once it completed, its response. A zero Status means it is in flight.
+type IdempotentRequest struct {
+	Key         string
+	RequestHash string
+	Status      int
+	ContentType string
+	Body        []byte
+	ExpiresAt   time.Time
+}
+
+// InFlight reports whether the request has not completed yet.
+func (r IdempotentRequest) InFlight() bool {
+	return r.Status == 0
+}
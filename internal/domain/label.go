@@ -0,0 +1,41 @@
+package domain
+
+// LabelTemplate lays labels out on a page of a label sheet. Lengths are in
+// millimetres, from the top left corner of the page; labels fill a row left
+// to right, then the next row.
+type LabelTemplate struct {
+	Name        string  `json:"name"`
+	PageWidth   float64 `json:"page_width"`
+	PageHeight  float64 `json:"page_height"`
+	Columns     int     `json:"columns"`
+	Rows        int     `json:"rows"`
+	MarginTop   float64 `json:"margin_top"`
+	MarginLeft  float64 `json:"margin_left"`
+	LabelWidth  float64 `json:"label_width"`
+	LabelHeight float64 `json:"label_height"`
+	// GapX and GapY separate adjacent labels.
+	GapX float64 `json:"gap_x"`
+	GapY float64 `json:"gap_y"`
+	// Padding is the blank border kept inside each label.
+	Padding float64 `json:"padding"`
+	// FontSize is the size of the text, in points.
+	FontSize float64 `json:"font_size"`
+	// Symbology is the barcode printed when the request names none:
+	// code128, ean13 or qr.
+	Symbology string `json:"symbology"`
+}
+
+// Label is what one label shows: a title, an optional caption, and Code as a
+// barcode with the code printed under it.
+type Label struct {
+	Title   string
+	Caption string
+	Code    string
+}
+
+// LabelSheet selects the template of a label sheet by name, and the
+// symbology of its barcodes when it is not the template's.
+type LabelSheet struct {
+	Template  string
+	Symbology string
+}
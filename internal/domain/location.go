@@ -0,0 +1,34 @@
+package domain
+
+// Location kinds, from the outermost to the innermost. A location's parent
+// is of the kind just before its own; zones have no parent. Stock is only
+// placed in bins.
+const (
+	LocationZone  = "zone"
+	LocationAisle = "aisle"
+	LocationShelf = "shelf"
+	LocationBin   = "bin"
+)
+
+// LocationKinds lists the kinds in nesting order.
+var LocationKinds = []string{LocationZone, LocationAisle, LocationShelf, LocationBin}
+
+// Location is a place inside a warehouse. A nil Capacity leaves the location
+// unbounded; its content still counts against its ancestors and the
+// warehouse.
+type Location struct {
+	ID          int    `json:"id"`
+	WarehouseID int    `json:"warehouse_id"`
+	ParentID    *int   `json:"parent_id"`
+	Kind        string `json:"kind"`
+	Code        string `json:"code"`
+	Capacity    *int   `json:"capacity"`
+}
+
+// LocationNode is a location with the units stored in it and below it, and
+// its sublocations.
+type LocationNode struct {
+	Location
+	Units    int            `json:"units"`
+	Children []LocationNode `json:"children"`
+}
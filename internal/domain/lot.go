@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Lot is one delivery of a product with its own quantity and expiration.
+// The quantity and expiration of a Product are derived from its lots: the
+// sum of their quantities and the earliest expiration still in stock.
+// LocationID is the bin holding the lot, nil when it is not placed.
+// UnitCost is what one unit was bought for, in CostCurrency.
+type Lot struct {
+	ID           int             `json:"id"`
+	ProductID    int             `json:"product_id"`
+	LotCode      string          `json:"lot_code"`
+	Quantity     int             `json:"quantity"`
+	UnitCost     decimal.Decimal `json:"unit_cost"`
+	CostCurrency string          `json:"cost_currency"`
+	Expiration   time.Time       `json:"expiration"`
+	ReceivedAt   time.Time       `json:"received_at"`
+	LocationID   *int            `json:"location_id"`
+}
+
+// LotAllocation is the part of an issue taken from one lot.
+type LotAllocation struct {
+	LotID      int       `json:"lot_id"`
+	LotCode    string    `json:"lot_code"`
+	Quantity   int       `json:"quantity"`
+	Expiration time.Time `json:"expiration"`
+}
+
+// ExpiringLot is a lot in stock that expires before a given time, with the
+// product it belongs to.
+type ExpiringLot struct {
+	Lot
+	ProductName string `json:"product_name"`
+	CodeValue   string `json:"code_value"`
+	WarehouseID int    `json:"warehouse_id"`
+}
@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func init() {
+	// Amounts keep the JSON number type they had as float64, written from
+	// their exact decimal digits instead of a binary approximation.
+	decimal.MarshalJSONWithoutQuotes = true
+}
+
+// ExchangeRate is what one unit of the base currency buys in Currency.
+// Source is manual for rates set through the API and file for rates loaded
+// from the rates file.
+type ExchangeRate struct {
+	Currency  string          `json:"currency"`
+	Rate      decimal.Decimal `json:"rate"`
+	Source    string          `json:"source"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// Exchange rate sources.
+const (
+	RateSourceManual = "manual"
+	RateSourceFile   = "file"
+)
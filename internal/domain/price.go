@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// PriceChange is one entry in the price history of a product. Applied is
+// false while a future-dated change waits for its EffectiveAt.
+type PriceChange struct {
+	ID          int             `json:"id"`
+	ProductID   int             `json:"product_id"`
+	Price       decimal.Decimal `json:"price"`
+	Currency    string          `json:"currency"`
+	EffectiveAt time.Time       `json:"effective_at"`
+	Applied     bool            `json:"applied"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// ProductValuation is the stock of one product priced at a date. Price and
+// Value are in Currency.
+type ProductValuation struct {
+	ProductID int             `json:"product_id"`
+	Name      string          `json:"name"`
+	CodeValue string          `json:"code_value"`
+	Quantity  int             `json:"quantity"`
+	Price     decimal.Decimal `json:"price"`
+	Currency  string          `json:"currency"`
+	Value     decimal.Decimal `json:"value"`
+}
+
+// StockValuation values the stock of a warehouse with the prices in effect
+// at At, converted into Currency. Quantities are the current ones; only the
+// prices are historical.
+type StockValuation struct {
+	WarehouseID int                `json:"warehouse_id"`
+	At          time.Time          `json:"at"`
+	Currency    string             `json:"currency"`
+	Units       int                `json:"units"`
+	Value       decimal.Decimal    `json:"value"`
+	Products    []ProductValuation `json:"products"`
+}
@@ -1,19 +1,69 @@
 package domain
 
-import "time"
+import (
+	"time"
 
+	"github.com/shopspring/decimal"
+)
+
+// Product is a stocked item. Price is an exact amount in Currency, an
+// ISO-4217 code. The validate tags hold for every product that is stored.
 type Product struct {
-	ID          int       `json:"id"`
-	Name        string    `json:"name"`
-	Quantity    int       `json:"quantity"`
-	CodeValue   string    `json:"code_value"`
-	IsPublished bool      `json:"is_published"`
-	Expiration  time.Time `json:"expiration"`
-	Price       float64   `json:"price"`
-	IdWarehouse int       `json:"id_warehouse"`
+	ID          int             `json:"id"`
+	Name        string          `json:"name" validate:"required"`
+	Quantity    int             `json:"quantity"`
+	CodeValue   string          `json:"code_value" validate:"required"`
+	IsPublished bool            `json:"is_published"`
+	Expiration  time.Time       `json:"expiration"`
+	Price       decimal.Decimal `json:"price"`
+	Currency    string          `json:"currency"`
+	IdWarehouse int             `json:"id_warehouse"`
+	CategoryID  *int            `json:"category_id"`
 }
 
 type ProductWithWarehouse struct {
 	Product
 	Warehouse
 }
+
+// ProductFilter narrows a product listing. Zero values leave the listing
+// unfiltered; a zero Limit returns every match. A Currency converts the
+// listed prices into it.
+type ProductFilter struct {
+	IDs          []int
+	WarehouseIDs []int
+	CategoryIDs  []int
+	Published    *bool
+	Limit        int
+	Offset       int
+	Currency     string
+}
+
+// ProductSearch is a full-text query over the name and code_value of the
+// products of a tenant. The last word of Query also matches as a prefix, so
+// it can be sent while the user types.
+type ProductSearch struct {
+	Query string
+	Limit int
+}
+
+// ProductMatch is a product found by a search. A higher Score is a better
+// match; scores only compare within one search.
+type ProductMatch struct {
+	Product
+	Score float64 `json:"score"`
+}
+
+// SearchHit is a product matching a search, as returned by a search index.
+type SearchHit struct {
+	ID    int
+	Score float64
+}
+
+// ProductDocument is what a search index keeps of a product.
+type ProductDocument struct {
+	TenantID  string
+	ID        int
+	Name      string
+	CodeValue string
+}
@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+// ReorderPolicy is when and how much of a product to reorder. A product
+// without its own values falls back to the defaults of its warehouse; a nil
+// ReorderPoint there too means the product is not watched.
+type ReorderPolicy struct {
+	ReorderPoint    *int `json:"reorder_point"`
+	ReorderQuantity *int `json:"reorder_quantity"`
+}
+
+// LowStockItem is a product whose quantity is at or below its effective
+// reorder point.
+type LowStockItem struct {
+	ProductID       int    `json:"product_id"`
+	Name            string `json:"name"`
+	CodeValue       string `json:"code_value"`
+	WarehouseID     int    `json:"warehouse_id"`
+	Quantity        int    `json:"quantity"`
+	ReorderPoint    int    `json:"reorder_point"`
+	ReorderQuantity int    `json:"reorder_quantity"`
+}
+
+// ReorderAlert is emitted when an update takes a product from above its
+// reorder point to at or below it.
+type ReorderAlert struct {
+	LowStockItem
+	PreviousQuantity int       `json:"previous_quantity"`
+	At               time.Time `json:"at"`
+}
@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// Reservation statuses. A reservation starts active and ends in exactly one
+// of the other states.
+const (
+	ReservationActive    = "active"
+	ReservationConfirmed = "confirmed"
+	ReservationReleased  = "released"
+	ReservationExpired   = "expired"
+)
+
+// Reservation holds stock of a product for an order until it is confirmed,
+// released or its ExpiresAt passes.
+type Reservation struct {
+	ID        int       `json:"id"`
+	ProductID int       `json:"product_id"`
+	Quantity  int       `json:"quantity"`
+	Status    string    `json:"status"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// StockAvailability is the stock of a product that can still be reserved:
+// Quantity minus the units held by active reservations.
+type StockAvailability struct {
+	ProductID int `json:"product_id"`
+	Quantity  int `json:"quantity"`
+	Reserved  int `json:"reserved"`
+	Available int `json:"available"`
+}
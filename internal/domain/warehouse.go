@@ -1,14 +1,74 @@
 package domain
 
+import "github.com/shopspring/decimal"
+
+// Warehouse is a site of a tenant. WarehouseCode is unique per tenant and
+// may be empty.
 type Warehouse struct {
-	ID        int    `json:"id"`
-	Name      string `json:"name"`
-	Address   string `json:"adress"`
-	Telephone string `json:"telephone"`
-	Capacity  int    `json:"capacity"`
+	ID            int    `json:"id"`
+	WarehouseCode string `json:"warehouse_code"`
+	Name          string `json:"name"`
+	Address       string `json:"adress"`
+	Telephone     string `json:"telephone"`
+	Capacity      int    `json:"capacity"`
+}
+
+// WarehouseFilter narrows a warehouse listing. A zero Limit returns every
+// match.
+type WarehouseFilter struct {
+	IDs    []int
+	Limit  int
+	Offset int
 }
 
 type WarehouseReport struct {
 	WarehouseName string `json:"warehouse_name"`
 	ProductCount  int    `json:"product_count"`
 }
+
+// WarehouseStock summarises what a warehouse currently holds.
+type WarehouseStock struct {
+	TenantID      string `json:"tenant_id"`
+	WarehouseID   int    `json:"warehouse_id"`
+	WarehouseName string `json:"warehouse_name"`
+	Capacity      int    `json:"capacity"`
+	ProductCount  int    `json:"product_count"`
+	Units         int    `json:"units"`
+}
+
+// WarehouseProductsFilter narrows and orders the products of a warehouse.
+// Sort is one of the product JSON field names accepted by the repository.
+type WarehouseProductsFilter struct {
+	Published *bool
+	Expired   *bool
+	Sort      string
+	Desc      bool
+	Limit     int
+	Offset    int
+	// Currency converts the listed prices and the stock value into it; the
+	// stock value is in the base currency when it is empty.
+	Currency string
+}
+
+// ProductTotals aggregates every product matching a filter, not only the
+// returned page. Expired counts the products holding at least one expired
+// lot and ExpiredUnits the units in those lots. StockValue sums the products
+// of every currency converted into Currency.
+type ProductTotals struct {
+	Products     int             `json:"products"`
+	Units        int             `json:"units"`
+	Published    int             `json:"published"`
+	Expired      int             `json:"expired"`
+	ExpiredUnits int             `json:"expired_units"`
+	StockValue   decimal.Decimal `json:"stock_value"`
+	Currency     string          `json:"currency"`
+}
+
+// WarehouseProducts is one page of the products stored in a warehouse.
+type WarehouseProducts struct {
+	WarehouseID int           `json:"warehouse_id"`
+	Products    []Product     `json:"products"`
+	Totals      ProductTotals `json:"totals"`
+	Limit       int           `json:"limit"`
+	Offset      int           `json:"offset"`
+}
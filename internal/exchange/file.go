@@ -0,0 +1,45 @@
+package exchange
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+
+	"repository_class/internal/domain"
+
+	"github.com/shopspring/decimal"
+)
+
+// ParseFile reads exchange rates from a CSV file with one currency,rate pair
+// per line, the rate being what one unit of the base currency buys. Lines
+// starting with # are comments.
+func ParseFile(r io.Reader) ([]domain.ExchangeRate, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+
+	var rates []domain.ExchangeRate
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		code, err := ParseCurrency(record[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		rate, err := decimal.NewFromString(record[1])
+		if err != nil || !rate.IsPositive() {
+			return nil, fmt.Errorf("line %d: %w: %q", line, ErrInvalidRate, record[1])
+		}
+		rates = append(rates, domain.ExchangeRate{Currency: code, Rate: rate, Source: domain.RateSourceFile})
+	}
+	return rates, nil
+}
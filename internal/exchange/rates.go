@@ -0,0 +1,87 @@
+package exchange
+
+import (
+	"fmt"
+	"strings"
+
+	"repository_class/internal/domain"
+
+	"github.com/shopspring/decimal"
+	"golang.org/x/text/currency"
+)
+
+// ParseCurrency checks that code is an ISO-4217 currency and returns it in
+// upper case.
+func ParseCurrency(code string) (string, error) {
+	unit, err := currency.ParseISO(strings.ToUpper(code))
+	if err != nil {
+		return "", fmt.Errorf("%w: %q", ErrUnknownCurrency, code)
+	}
+	return unit.String(), nil
+}
+
+// Rates is a snapshot of the exchange rates. A request reads it once and
+// converts all of its amounts with it, so they agree with each other.
+type Rates struct {
+	base  string
+	rates map[string]decimal.Decimal
+}
+
+// NewRates builds a snapshot of rates quoted against base.
+func NewRates(base string, rates []domain.ExchangeRate) Rates {
+	r := Rates{base: base, rates: make(map[string]decimal.Decimal, len(rates))}
+	for _, rate := range rates {
+		r.rates[rate.Currency] = rate.Rate
+	}
+	return r
+}
+
+// Base returns the currency the rates are quoted against.
+func (r Rates) Base() string {
+	return r.base
+}
+
+// Convert converts amount between two currencies through the base currency
+// and rounds the result to the minor unit of to. An amount already in to is
+// returned unchanged.
+func (r Rates) Convert(amount decimal.Decimal, from, to string) (decimal.Decimal, error) {
+	if from == to {
+		return amount, nil
+	}
+	fromRate, err := r.rate(from)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+	toRate, err := r.rate(to)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+	return amount.Mul(toRate).Div(fromRate).Round(minorUnits(to)), nil
+}
+
+func (r Rates) rate(code string) (decimal.Decimal, error) {
+	if code == r.base {
+		return decimal.NewFromInt(1), nil
+	}
+	rate, ok := r.rates[code]
+	if !ok {
+		return decimal.Decimal{}, fmt.Errorf("%w: %s", ErrNoRate, code)
+	}
+	return rate, nil
+}
+
+// Round rounds amount to the minor unit of the currency.
+func Round(amount decimal.Decimal, code string) decimal.Decimal {
+	return amount.Round(minorUnits(code))
+}
+
+// minorUnits returns the number of decimals amounts in the currency are
+// written with, two when the currency is unknown.
+func minorUnits(code string) int32 {
+	unit, err := currency.ParseISO(code)
+	if err != nil {
+		return 2
+	}
+	scale, _ := currency.Standard.Rounding(unit)
+	return int32(scale)
+}
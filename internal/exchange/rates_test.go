@@ -0,0 +1,71 @@
+package exchange
+
+import (
+	"strings"
+	"testing"
+
+	"repository_class/internal/domain"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvert(t *testing.T) {
+	rates := NewRates("USD", []domain.ExchangeRate{
+		{Currency: "EUR", Rate: decimal.RequireFromString("0.9")},
+		{Currency: "JPY", Rate: decimal.RequireFromString("150")},
+	})
+
+	cases := []struct {
+		name     string
+		amount   string
+		from, to string
+		expected string
+	}{
+		{"same currency", "10.005", "EUR", "EUR", "10.005"},
+		{"from base", "10", "USD", "EUR", "9"},
+		{"to base", "9", "EUR", "USD", "10"},
+		{"cross rate", "0.9", "EUR", "JPY", "150"},
+		{"rounds to minor units", "1", "EUR", "USD", "1.11"},
+		{"no decimals for yen", "1.99", "USD", "JPY", "299"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := rates.Convert(decimal.RequireFromString(tc.amount), tc.from, tc.to)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, got.String())
+		})
+	}
+}
+
+func TestConvertWithoutRate(t *testing.T) {
+	rates := NewRates("USD", nil)
+
+	_, err := rates.Convert(decimal.NewFromInt(1), "USD", "GBP")
+
+	assert.ErrorIs(t, err, ErrNoRate)
+}
+
+func TestParseCurrency(t *testing.T) {
+	code, err := ParseCurrency("eur")
+	assert.NoError(t, err)
+	assert.Equal(t, "EUR", code)
+
+	_, err = ParseCurrency("EURO")
+	assert.ErrorIs(t, err, ErrUnknownCurrency)
+}
+
+func TestParseFile(t *testing.T) {
+	rates, err := ParseFile(strings.NewReader("# rates against USD\nEUR, 0.92\n\njpy,151.5\n"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.ExchangeRate{
+		{Currency: "EUR", Rate: decimal.RequireFromString("0.92"), Source: domain.RateSourceFile},
+		{Currency: "JPY", Rate: decimal.RequireFromString("151.5"), Source: domain.RateSourceFile},
+	}, rates)
+
+	_, err = ParseFile(strings.NewReader("EUR,0\n"))
+	assert.ErrorIs(t, err, ErrInvalidRate)
+}
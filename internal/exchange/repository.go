@@ -0,0 +1,137 @@
+package exchange
+
+import (
+	"context"
+	"database/sql"
+
+	"repository_class/internal/domain"
+	"repository_class/pkg/instrument"
+)
+
+// Repository encapsulates the storage of the exchange rates, one per
+// currency.
+type Repository interface {
+	List(ctx context.Context) ([]domain.ExchangeRate, error)
+	Get(ctx context.Context, code string) (domain.ExchangeRate, error)
+	// Set creates or replaces the rate of a currency.
+	Set(ctx context.Context, rate domain.ExchangeRate) error
+	// ReplaceFromFile swaps every file sourced rate for rates. Rates set by
+	// hand for currencies not in rates are kept.
+	ReplaceFromFile(ctx context.Context, rates []domain.ExchangeRate) error
+	Delete(ctx context.Context, code string) error
+}
+
+const repositoryName = "exchange"
+
+const rateColumns = "currency, rate, source, updated_at"
+
+const upsertRate = "INSERT INTO exchange_rates (currency, rate, source) VALUES (?, ?, ?) " +
+	"ON DUPLICATE KEY UPDATE rate=VALUES(rate), source=VALUES(source);"
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanRate(s scanner) (domain.ExchangeRate, error) {
+	rate := domain.ExchangeRate{}
+	err := s.Scan(&rate.Currency, &rate.Rate, &rate.Source, &rate.UpdatedAt)
+	return rate, err
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) List(ctx context.Context) (rates []domain.ExchangeRate, err error) {
+	query := "SELECT " + rateColumns + " FROM exchange_rates ORDER BY currency;"
+	ctx, done := instrument.Query(ctx, repositoryName, "List", query)
+	defer func() { done(err) }()
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		rate, err := scanRate(rows)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+
+	return rates, rows.Err()
+}
+
+func (r *repository) Get(ctx context.Context, code string) (rate domain.ExchangeRate, err error) {
+	query := "SELECT " + rateColumns + " FROM exchange_rates WHERE currency=?;"
+	ctx, done := instrument.Query(ctx, repositoryName, "Get", query)
+	defer func() { done(err) }()
+
+	rate, err = scanRate(r.db.QueryRowContext(ctx, query, code))
+	if err != nil {
+		return domain.ExchangeRate{}, err
+	}
+
+	return rate, nil
+}
+
+func (r *repository) Set(ctx context.Context, rate domain.ExchangeRate) (err error) {
+	ctx, done := instrument.Query(ctx, repositoryName, "Set", upsertRate)
+	defer func() { done(err) }()
+
+	_, err = r.db.ExecContext(ctx, upsertRate, rate.Currency, rate.Rate, rate.Source)
+	return err
+}
+
+func (r *repository) ReplaceFromFile(ctx context.Context, rates []domain.ExchangeRate) (err error) {
+	clear := "DELETE FROM exchange_rates WHERE source='file';"
+	ctx, done := instrument.Query(ctx, repositoryName, "ReplaceFromFile", clear+" "+upsertRate)
+	defer func() { done(err) }()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, clear); err != nil {
+		return err
+	}
+	for _, rate := range rates {
+		if _, err = tx.ExecContext(ctx, upsertRate, rate.Currency, rate.Rate, domain.RateSourceFile); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *repository) Delete(ctx context.Context, code string) (err error) {
+	query := "DELETE FROM exchange_rates WHERE currency=?;"
+	ctx, done := instrument.Query(ctx, repositoryName, "Delete", query)
+	defer func() { done(err) }()
+
+	res, err := r.db.ExecContext(ctx, query, code)
+	if err != nil {
+		return err
+	}
+
+	affect, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affect < 1 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"repository_class/internal/domain"
+
+	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel"
+)
+
+// Errors
+var (
+	ErrNotFound        = errors.New("exchange rate not found")
+	ErrUnknownCurrency = errors.New("unknown ISO-4217 currency")
+	ErrNoRate          = errors.New("no exchange rate for currency")
+	ErrInvalidRate     = errors.New("exchange rate must be a positive decimal")
+	ErrBaseCurrency    = errors.New("the base currency has a fixed rate of 1")
+)
+
+type Service interface {
+	// Base returns the currency rates are quoted against. Prices without a
+	// currency and stock values are in it by default.
+	Base() string
+	List(ctx context.Context) ([]domain.ExchangeRate, error)
+	// Set stores the rate of a currency by hand.
+	Set(ctx context.Context, code string, rate decimal.Decimal) (domain.ExchangeRate, error)
+	Delete(ctx context.Context, code string) error
+	// LoadFile replaces the rates loaded from a file before with those in
+	// the file at path and returns how many it read.
+	LoadFile(ctx context.Context, path string) (int, error)
+	// Rates returns a snapshot of every rate to convert amounts with.
+	Rates(ctx context.Context) (Rates, error)
+}
+
+var tracer = otel.Tracer("repository_class/internal/exchange")
+
+type service struct {
+	repo Repository
+	base string
+}
+
+func NewService(repo *Repository, base string) Service {
+	return &service{repo: *repo, base: base}
+}
+
+func (s *service) Base() string {
+	return s.base
+}
+
+func (s *service) List(ctx context.Context) ([]domain.ExchangeRate, error) {
+	ctx, span := tracer.Start(ctx, "exchange.Service.List")
+	defer span.End()
+
+	rates, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if rates == nil {
+		return []domain.ExchangeRate{}, nil
+	}
+	return rates, nil
+}
+
+func (s *service) Set(ctx context.Context, code string, rate decimal.Decimal) (domain.ExchangeRate, error) {
+	ctx, span := tracer.Start(ctx, "exchange.Service.Set")
+	defer span.End()
+
+	code, err := ParseCurrency(code)
+	if err != nil {
+		return domain.ExchangeRate{}, err
+	}
+	if code == s.base {
+		return domain.ExchangeRate{}, ErrBaseCurrency
+	}
+	if !rate.IsPositive() {
+		return domain.ExchangeRate{}, ErrInvalidRate
+	}
+
+	if err := s.repo.Set(ctx, domain.ExchangeRate{Currency: code, Rate: rate, Source: domain.RateSourceManual}); err != nil {
+		return domain.ExchangeRate{}, err
+	}
+	return s.repo.Get(ctx, code)
+}
+
+func (s *service) Delete(ctx context.Context, code string) error {
+	ctx, span := tracer.Start(ctx, "exchange.Service.Delete")
+	defer span.End()
+
+	code, err := ParseCurrency(code)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, code)
+}
+
+func (s *service) LoadFile(ctx context.Context, path string) (int, error) {
+	ctx, span := tracer.Start(ctx, "exchange.Service.LoadFile")
+	defer span.End()
+
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	rates, err := ParseFile(f)
+	if err != nil {
+		return 0, err
+	}
+	for _, rate := range rates {
+		if rate.Currency == s.base {
+			return 0, ErrBaseCurrency
+		}
+	}
+
+	if err := s.repo.ReplaceFromFile(ctx, rates); err != nil {
+		return 0, err
+	}
+	return len(rates), nil
+}
+
+func (s *service) Rates(ctx context.Context) (Rates, error) {
+	ctx, span := tracer.Start(ctx, "exchange.Service.Rates")
+	defer span.End()
+
+	rates, err := s.repo.List(ctx)
+	if err != nil {
+		return Rates{}, err
+	}
+	return NewRates(s.base, rates), nil
+}
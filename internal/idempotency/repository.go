@@ -0,0 +1,121 @@
+// Package idempotency stores the responses of POST requests sent with an
+// Idempotency-Key, so a retried request gets the first response back
+// instead of running again.
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"repository_class/internal/domain"
+	"repository_class/pkg/instrument"
+	"repository_class/pkg/tenant"
+)
+
+// Repository encapsulates the stored requests. Every method but
+// DeleteExpired only sees the keys of the tenant in its context and fails
+// with tenant.ErrMissing when there is none.
+type Repository interface {
+	// Begin claims key for a request until lockedUntil and reports true.
+	// When the key is already claimed and has not expired it returns the
+	// request holding it and false.
+	Begin(ctx context.Context, key, requestHash string, now, lockedUntil time.Time) (domain.IdempotentRequest, bool, error)
+	// Complete stores the response of the request holding key, kept until
+	// expiresAt.
+	Complete(ctx context.Context, r domain.IdempotentRequest) error
+	// Release frees key, so the request can be sent again.
+	Release(ctx context.Context, key string) error
+	// DeleteExpired drops the expired keys of every tenant and returns how
+	// many it dropped.
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+const repositoryName = "idempotency"
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) Begin(ctx context.Context, key, requestHash string, now, lockedUntil time.Time) (req domain.IdempotentRequest, claimed bool, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return domain.IdempotentRequest{}, false, err
+	}
+	expired := "DELETE FROM idempotency_keys WHERE tenant_id=? AND idem_key=? AND expires_at<=?;"
+	claim := "INSERT IGNORE INTO idempotency_keys (tenant_id, idem_key, request_hash, expires_at) VALUES (?, ?, ?, ?);"
+	held := "SELECT idem_key, request_hash, status, content_type, body, expires_at FROM idempotency_keys WHERE tenant_id=? AND idem_key=?;"
+	ctx, done := instrument.Query(ctx, repositoryName, "Begin", expired+" "+claim+" "+held)
+	defer func() { done(err) }()
+
+	if _, err = r.db.ExecContext(ctx, expired, tenantID, key, now); err != nil {
+		return domain.IdempotentRequest{}, false, err
+	}
+	// The primary key makes the claim atomic: of concurrent requests with
+	// the same key only one inserts a row.
+	res, err := r.db.ExecContext(ctx, claim, tenantID, key, requestHash, lockedUntil)
+	if err != nil {
+		return domain.IdempotentRequest{}, false, err
+	}
+	affect, err := res.RowsAffected()
+	if err != nil {
+		return domain.IdempotentRequest{}, false, err
+	}
+	if affect == 1 {
+		return domain.IdempotentRequest{Key: key, RequestHash: requestHash, ExpiresAt: lockedUntil}, true, nil
+	}
+
+	var body []byte
+	err = r.db.QueryRowContext(ctx, held, tenantID, key).
+		Scan(&req.Key, &req.RequestHash, &req.Status, &req.ContentType, &body, &req.ExpiresAt)
+	if err != nil {
+		return domain.IdempotentRequest{}, false, err
+	}
+	req.Body = body
+
+	return req, false, nil
+}
+
+func (r *repository) Complete(ctx context.Context, req domain.IdempotentRequest) (err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return err
+	}
+	query := "UPDATE idempotency_keys SET status=?, content_type=?, body=?, expires_at=? WHERE tenant_id=? AND idem_key=? AND request_hash=?;"
+	ctx, done := instrument.Query(ctx, repositoryName, "Complete", query)
+	defer func() { done(err) }()
+
+	_, err = r.db.ExecContext(ctx, query, req.Status, req.ContentType, req.Body, req.ExpiresAt, tenantID, req.Key, req.RequestHash)
+	return err
+}
+
+func (r *repository) Release(ctx context.Context, key string) (err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return err
+	}
+	query := "DELETE FROM idempotency_keys WHERE tenant_id=? AND idem_key=? AND status=0;"
+	ctx, done := instrument.Query(ctx, repositoryName, "Release", query)
+	defer func() { done(err) }()
+
+	_, err = r.db.ExecContext(ctx, query, tenantID, key)
+	return err
+}
+
+func (r *repository) DeleteExpired(ctx context.Context, now time.Time) (_ int64, err error) {
+	query := "DELETE FROM idempotency_keys WHERE expires_at<=?;"
+	ctx, done := instrument.Query(ctx, repositoryName, "DeleteExpired", query)
+	defer func() { done(err) }()
+
+	res, err := r.db.ExecContext(ctx, query, now)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,94 @@
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"repository_class/internal/domain"
+
+	"go.opentelemetry.io/otel"
+)
+
+// Errors
+var (
+	ErrInFlight  = errors.New("a request with this Idempotency-Key is still in flight")
+	ErrKeyReused = errors.New("the Idempotency-Key was already used with a different request")
+)
+
+// lockFor is how long a request holds its key before it completes. A
+// request whose instance died frees the key after it.
+const lockFor = time.Minute
+
+type Service interface {
+	// Begin claims key for the request hashed to requestHash and reports
+	// true, or returns the completed request that claimed it first and
+	// false. It fails with ErrInFlight while that request is running and
+	// with ErrKeyReused when it was a different request.
+	Begin(ctx context.Context, key, requestHash string) (domain.IdempotentRequest, bool, error)
+	// Complete stores the response of a request claimed with Begin.
+	Complete(ctx context.Context, r domain.IdempotentRequest) error
+	// Release frees the key of a request claimed with Begin whose response
+	// is not worth keeping, so it can be retried.
+	Release(ctx context.Context, key string) error
+	// DeleteExpired drops the keys whose time ran out and returns how many
+	// it dropped.
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+var tracer = otel.Tracer("repository_class/internal/idempotency")
+
+type service struct {
+	repo Repository
+	ttl  time.Duration
+}
+
+// NewService returns a Service keeping responses for ttl.
+func NewService(repo *Repository, ttl time.Duration) Service {
+	return &service{repo: *repo, ttl: ttl}
+}
+
+func (s *service) Begin(ctx context.Context, key, requestHash string) (domain.IdempotentRequest, bool, error) {
+	ctx, span := tracer.Start(ctx, "idempotency.Service.Begin")
+	defer span.End()
+
+	now := time.Now()
+	r, claimed, err := s.repo.Begin(ctx, key, requestHash, now, now.Add(lockFor))
+	switch {
+	// The key was released between the claim and the read.
+	case errors.Is(err, sql.ErrNoRows):
+		return domain.IdempotentRequest{}, false, ErrInFlight
+	case err != nil:
+		return domain.IdempotentRequest{}, false, err
+	case claimed:
+		return r, true, nil
+	case r.RequestHash != requestHash:
+		return domain.IdempotentRequest{}, false, ErrKeyReused
+	case r.InFlight():
+		return domain.IdempotentRequest{}, false, ErrInFlight
+	}
+	return r, false, nil
+}
+
+func (s *service) Complete(ctx context.Context, r domain.IdempotentRequest) error {
+	ctx, span := tracer.Start(ctx, "idempotency.Service.Complete")
+	defer span.End()
+
+	r.ExpiresAt = time.Now().Add(s.ttl)
+	return s.repo.Complete(ctx, r)
+}
+
+func (s *service) Release(ctx context.Context, key string) error {
+	ctx, span := tracer.Start(ctx, "idempotency.Service.Release")
+	defer span.End()
+
+	return s.repo.Release(ctx, key)
+}
+
+func (s *service) DeleteExpired(ctx context.Context) (int64, error) {
+	ctx, span := tracer.Start(ctx, "idempotency.Service.DeleteExpired")
+	defer span.End()
+
+	return s.repo.DeleteExpired(ctx, time.Now())
+}
@@ -0,0 +1,159 @@
+package label
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"regexp"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/ean"
+	"github.com/boombuler/barcode/qr"
+)
+
+// Symbologies a code can be encoded in.
+const (
+	Code128 = "code128"
+	EAN13   = "ean13"
+	QR      = "qr"
+)
+
+// Symbologies lists the supported symbologies.
+var Symbologies = []string{Code128, EAN13, QR}
+
+// ean13Pattern matches the digits of an EAN-13, with or without its check
+// digit.
+var ean13Pattern = regexp.MustCompile(`^[0-9]{12,13}$`)
+
+// Symbol is an encoded code: a grid of modules, the narrowest bars of a
+// linear barcode or the squares of a QR code. Linear symbols have one row.
+type Symbol struct {
+	modules [][]bool
+	// quiet is the blank margin the symbol needs on each side, in modules.
+	quiet int
+}
+
+// Encode encodes content in symbology. It fails with ErrUnknownSymbology or,
+// when content does not fit the symbology, ErrInvalidContent: Code128 takes
+// ASCII, EAN-13 takes 12 digits or 13 with a valid check digit.
+func Encode(symbology, content string) (Symbol, error) {
+	var (
+		bc    barcode.Barcode
+		err   error
+		quiet = 10
+	)
+	switch symbology {
+	case Code128:
+		bc, err = code128.Encode(content)
+	case EAN13:
+		if !ean13Pattern.MatchString(content) {
+			return Symbol{}, fmt.Errorf("%w: %q is not 12 or 13 digits", ErrInvalidContent, content)
+		}
+		bc, err = ean.Encode(content)
+	case QR:
+		bc, err = qr.Encode(content, qr.M, qr.Auto)
+		quiet = 4
+	default:
+		return Symbol{}, ErrUnknownSymbology
+	}
+	if err != nil {
+		return Symbol{}, fmt.Errorf("%w: %q as %s: %v", ErrInvalidContent, content, symbology, err)
+	}
+
+	b := bc.Bounds()
+	modules := make([][]bool, b.Dy())
+	for y := range modules {
+		modules[y] = make([]bool, b.Dx())
+		for x := range modules[y] {
+			r, _, _, _ := bc.At(b.Min.X+x, b.Min.Y+y).RGBA()
+			modules[y][x] = r < 0x8000
+		}
+	}
+	return Symbol{modules: modules, quiet: quiet}, nil
+}
+
+// Linear reports whether s is a one row barcode, drawn at any height.
+func (s Symbol) Linear() bool {
+	return len(s.modules) == 1
+}
+
+// Size returns the columns and rows of s, quiet zone included.
+func (s Symbol) Size() (cols, rows int) {
+	cols = len(s.modules[0]) + 2*s.quiet
+	if s.Linear() {
+		return cols, 1
+	}
+	return cols, len(s.modules) + 2*s.quiet
+}
+
+// runs calls f for each horizontal run of n dark modules starting at column
+// col of row, quiet zone included.
+func (s Symbol) runs(f func(row, col, n int)) {
+	for y, line := range s.modules {
+		row := y
+		if !s.Linear() {
+			row += s.quiet
+		}
+		for x := 0; x < len(line); {
+			if !line[x] {
+				x++
+				continue
+			}
+			start := x
+			for x < len(line) && line[x] {
+				x++
+			}
+			f(row, start+s.quiet, x-start)
+		}
+	}
+}
+
+// PNG draws s with modules of scale pixels. Linear symbols are height pixels
+// high; QR codes are square.
+func (s Symbol) PNG(w io.Writer, scale, height int) error {
+	cols, rows := s.Size()
+	moduleHeight := scale
+	if s.Linear() {
+		moduleHeight = height
+	}
+	img := image.NewGray(image.Rect(0, 0, cols*scale, rows*moduleHeight))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	s.runs(func(row, col, n int) {
+		for y := row * moduleHeight; y < (row+1)*moduleHeight; y++ {
+			line := img.Pix[y*img.Stride:]
+			for x := col * scale; x < (col+n)*scale; x++ {
+				line[x] = 0
+			}
+		}
+	})
+	return png.Encode(w, img)
+}
+
+// SVG draws s as PNG does, as one path in a viewBox counted in modules.
+func (s Symbol) SVG(w io.Writer, scale, height int) error {
+	cols, rows := s.Size()
+	pxHeight := rows * scale
+	if s.Linear() {
+		pxHeight = height
+	}
+	if _, err := fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" `+
+		`preserveAspectRatio="none" shape-rendering="crispEdges"><rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`,
+		cols*scale, pxHeight, cols, rows, cols, rows); err != nil {
+		return err
+	}
+	var err error
+	s.runs(func(row, col, n int) {
+		if err == nil {
+			_, err = fmt.Fprintf(w, "M%d %dh%dv1h-%dz", col, row, n, n)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, `"/></svg>`)
+	return err
+}
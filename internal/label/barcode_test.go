@@ -0,0 +1,68 @@
+package label
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncode(t *testing.T) {
+	_, err := Encode(EAN13, "590123412345")
+	assert.NoError(t, err)
+	_, err = Encode(EAN13, "5901234123457")
+	assert.NoError(t, err)
+	// Wrong check digit, letters, wrong length.
+	for _, code := range []string{"5901234123458", "59012341234A", "12345"} {
+		_, err = Encode(EAN13, code)
+		assert.ErrorIs(t, err, ErrInvalidContent, code)
+	}
+	_, err = Encode(Code128, "")
+	assert.ErrorIs(t, err, ErrInvalidContent)
+	_, err = Encode("upc", "123")
+	assert.ErrorIs(t, err, ErrUnknownSymbology)
+
+	s, err := Encode(Code128, "BLT-100")
+	assert.NoError(t, err)
+	assert.True(t, s.Linear())
+
+	s, err = Encode(QR, "BLT-100")
+	assert.NoError(t, err)
+	assert.False(t, s.Linear())
+	cols, rows := s.Size()
+	assert.Equal(t, cols, rows)
+}
+
+func TestSymbolImages(t *testing.T) {
+	s, err := Encode(Code128, "BLT-100")
+	assert.NoError(t, err)
+	cols, _ := s.Size()
+
+	var buf bytes.Buffer
+	assert.NoError(t, s.PNG(&buf, 2, 50))
+	img, err := png.Decode(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, cols*2, img.Bounds().Dx())
+	assert.Equal(t, 50, img.Bounds().Dy())
+	// The quiet zone is blank, the first bar dark.
+	r, _, _, _ := img.At(0, 0).RGBA()
+	assert.Equal(t, uint32(0xffff), r)
+	r, _, _, _ = img.At(2*s.quiet, 0).RGBA()
+	assert.Equal(t, uint32(0), r)
+
+	buf.Reset()
+	assert.NoError(t, s.SVG(&buf, 2, 50))
+	assert.True(t, strings.HasPrefix(buf.String(), "<svg "))
+	assert.True(t, strings.HasSuffix(buf.String(), "</svg>"))
+}
+
+func TestBuiltinTemplates(t *testing.T) {
+	for _, tmpl := range builtinTemplates {
+		assert.NoError(t, validateTemplate(tmpl), tmpl.Name)
+	}
+	tmpl := builtinTemplates[0]
+	tmpl.Columns = 4
+	assert.ErrorIs(t, validateTemplate(tmpl), ErrInvalidTemplate)
+}
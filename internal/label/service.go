@@ -0,0 +1,161 @@
+// Package label renders the codes of products and bins as barcodes and QR
+// codes, one at a time as images or many at once as PDF label sheets.
+package label
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	"repository_class/internal/domain"
+	"repository_class/internal/location"
+	"repository_class/internal/product"
+
+	"go.opentelemetry.io/otel"
+)
+
+// Errors
+var (
+	ErrUnknownSymbology = errors.New("symbology must be one of code128, ean13 or qr")
+	ErrInvalidContent   = errors.New("code cannot be encoded in the symbology")
+	ErrUnknownTemplate  = errors.New("label template not found")
+	ErrInvalidTemplate  = errors.New("invalid label template")
+	ErrNoLabels         = errors.New("nothing to print labels for")
+	ErrTooManyLabels    = errors.New("too many labels for one sheet; narrow the selection")
+)
+
+// MaxSheetLabels bounds the labels of one sheet.
+const MaxSheetLabels = 1000
+
+type Service interface {
+	// Templates lists the label templates by name.
+	Templates(ctx context.Context) []domain.LabelTemplate
+	// ProductBarcode encodes the code_value of product id.
+	ProductBarcode(ctx context.Context, id int, symbology string) (Symbol, error)
+	// LocationBarcode encodes the code of location id.
+	LocationBarcode(ctx context.Context, id int, symbology string) (Symbol, error)
+	// ProductSheet writes a PDF of the labels of the products matching f:
+	// their name, price and code.
+	ProductSheet(ctx context.Context, w io.Writer, f domain.ProductFilter, sheet domain.LabelSheet) error
+	// LocationSheet writes a PDF of the labels of the locations of kind in
+	// the warehouse: their path from the zone down and their code.
+	LocationSheet(ctx context.Context, w io.Writer, warehouseID int, kind string, sheet domain.LabelSheet) error
+}
+
+var tracer = otel.Tracer("repository_class/internal/label")
+
+type service struct {
+	products  product.Service
+	locations location.Service
+	templates []domain.LabelTemplate
+}
+
+func NewService(products product.Service, locations location.Service, templates []domain.LabelTemplate) Service {
+	return &service{products: products, locations: locations, templates: Templates(templates)}
+}
+
+func (s *service) Templates(ctx context.Context) []domain.LabelTemplate {
+	_, span := tracer.Start(ctx, "label.Service.Templates")
+	defer span.End()
+
+	return s.templates
+}
+
+func (s *service) ProductBarcode(ctx context.Context, id int, symbology string) (Symbol, error) {
+	ctx, span := tracer.Start(ctx, "label.Service.ProductBarcode")
+	defer span.End()
+
+	p, err := s.products.Get(ctx, id)
+	if err != nil {
+		return Symbol{}, err
+	}
+	return Encode(symbology, p.CodeValue)
+}
+
+func (s *service) LocationBarcode(ctx context.Context, id int, symbology string) (Symbol, error) {
+	ctx, span := tracer.Start(ctx, "label.Service.LocationBarcode")
+	defer span.End()
+
+	l, err := s.locations.Get(ctx, id)
+	if err != nil {
+		return Symbol{}, err
+	}
+	return Encode(symbology, l.Code)
+}
+
+func (s *service) ProductSheet(ctx context.Context, w io.Writer, f domain.ProductFilter, sheet domain.LabelSheet) error {
+	ctx, span := tracer.Start(ctx, "label.Service.ProductSheet")
+	defer span.End()
+
+	t, symbology, err := s.sheet(sheet)
+	if err != nil {
+		return err
+	}
+	f.Limit, f.Offset = MaxSheetLabels+1, 0
+	products, err := s.products.List(ctx, f)
+	if err != nil {
+		return err
+	}
+
+	labels := make([]domain.Label, len(products))
+	for i, p := range products {
+		labels[i] = domain.Label{Title: p.Name, Caption: p.Price.String() + " " + p.Currency, Code: p.CodeValue}
+	}
+	return writeSheet(w, t, symbology, labels)
+}
+
+func (s *service) LocationSheet(ctx context.Context, w io.Writer, warehouseID int, kind string, sheet domain.LabelSheet) error {
+	ctx, span := tracer.Start(ctx, "label.Service.LocationSheet")
+	defer span.End()
+
+	t, symbology, err := s.sheet(sheet)
+	if err != nil {
+		return err
+	}
+	tree, err := s.locations.Tree(ctx, warehouseID)
+	if err != nil {
+		return err
+	}
+
+	var labels []domain.Label
+	var walk func(nodes []domain.LocationNode, path []string)
+	walk = func(nodes []domain.LocationNode, path []string) {
+		for _, n := range nodes {
+			path := append(path[:len(path):len(path)], n.Code)
+			if n.Kind == kind {
+				labels = append(labels, domain.Label{Title: strings.Join(path, " / "), Code: n.Code})
+			}
+			walk(n.Children, path)
+		}
+	}
+	walk(tree, nil)
+	return writeSheet(w, t, symbology, labels)
+}
+
+// sheet returns the template sheet names and the symbology of its barcodes.
+func (s *service) sheet(sheet domain.LabelSheet) (domain.LabelTemplate, string, error) {
+	name := sheet.Template
+	if name == "" {
+		name = DefaultTemplate
+	}
+	for _, t := range s.templates {
+		if t.Name == name {
+			if sheet.Symbology != "" {
+				return t, sheet.Symbology, nil
+			}
+			return t, t.Symbology, nil
+		}
+	}
+	return domain.LabelTemplate{}, "", ErrUnknownTemplate
+}
+
+func writeSheet(w io.Writer, t domain.LabelTemplate, symbology string, labels []domain.Label) error {
+	switch {
+	case len(labels) == 0:
+		return ErrNoLabels
+	case len(labels) > MaxSheetLabels:
+		return ErrTooManyLabels
+	}
+	return WriteSheet(w, t, symbology, labels)
+}
@@ -0,0 +1,106 @@
+package label
+
+import (
+	"fmt"
+	"io"
+
+	"repository_class/internal/domain"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// pointsToMM converts a font size to the height of its line, with some
+// leading.
+const pointsToMM = 0.3528 * 1.25
+
+// WriteSheet writes labels as a PDF laid out by t, with barcodes in
+// symbology. Each label shows its title in bold, its caption, the barcode
+// and the code under it; text too long for the label is cut short. It fails
+// with ErrInvalidContent before writing anything when a code cannot be
+// encoded.
+func WriteSheet(w io.Writer, t domain.LabelTemplate, symbology string, labels []domain.Label) error {
+	symbols := make([]Symbol, len(labels))
+	for i, l := range labels {
+		var err error
+		if symbols[i], err = Encode(symbology, l.Code); err != nil {
+			return err
+		}
+	}
+
+	pdf := fpdf.NewCustom(&fpdf.InitType{
+		OrientationStr: "P",
+		UnitStr:        "mm",
+		Size:           fpdf.SizeType{Wd: t.PageWidth, Ht: t.PageHeight},
+	})
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.SetCreator("inventory", true)
+	pdf.SetFillColor(0, 0, 0)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	perPage := t.Columns * t.Rows
+	for i, l := range labels {
+		if i%perPage == 0 {
+			pdf.AddPage()
+		}
+		col, row := i%perPage%t.Columns, i%perPage/t.Columns
+		x := t.MarginLeft + float64(col)*(t.LabelWidth+t.GapX) + t.Padding
+		y := t.MarginTop + float64(row)*(t.LabelHeight+t.GapY) + t.Padding
+		drawLabel(pdf, tr, t, x, y, l, symbols[i])
+	}
+	if err := pdf.Error(); err != nil {
+		return fmt.Errorf("render label sheet: %w", err)
+	}
+	return pdf.Output(w)
+}
+
+// drawLabel draws l in the label whose padded area starts at x, y.
+func drawLabel(pdf *fpdf.Fpdf, tr func(string) string, t domain.LabelTemplate, x, y float64, l domain.Label, s Symbol) {
+	width := t.LabelWidth - 2*t.Padding
+	bottom := y + t.LabelHeight - 2*t.Padding
+	line := t.FontSize * pointsToMM
+
+	text := func(style, str, align string) {
+		pdf.SetFont("Helvetica", style, t.FontSize)
+		pdf.SetXY(x, y)
+		pdf.CellFormat(width, line, fit(pdf, tr, str, width), "", 0, align, false, 0, "")
+		y += line
+	}
+	text("B", l.Title, "L")
+	if l.Caption != "" {
+		text("", l.Caption, "L")
+	}
+	codeY := bottom - line
+
+	// The barcode takes the room left between the text lines.
+	height := codeY - y
+	if height > 0 {
+		cols, rows := s.Size()
+		moduleWidth, moduleHeight := width/float64(cols), height
+		if !s.Linear() {
+			moduleWidth = min(moduleWidth, height/float64(rows))
+			moduleHeight = moduleWidth
+		}
+		left := x + (width-moduleWidth*float64(cols))/2
+		top := y
+		s.runs(func(row, col, n int) {
+			pdf.Rect(left+float64(col)*moduleWidth, top+float64(row)*moduleHeight, float64(n)*moduleWidth, moduleHeight, "F")
+		})
+	}
+
+	y = codeY
+	text("", l.Code, "C")
+}
+
+// fit cuts s short with an ellipsis so it is at most width wide in the
+// current font, and translates it to the encoding of the font.
+func fit(pdf *fpdf.Fpdf, tr func(string) string, s string, width float64) string {
+	if pdf.GetStringWidth(tr(s)) <= width {
+		return tr(s)
+	}
+	r := []rune(s)
+	for len(r) > 0 && pdf.GetStringWidth(tr(string(r)+"...")) > width {
+		r = r[:len(r)-1]
+	}
+	return tr(string(r) + "...")
+}
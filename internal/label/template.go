@@ -0,0 +1,103 @@
+package label
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"repository_class/internal/domain"
+)
+
+// DefaultTemplate is the template of sheets that name none.
+const DefaultTemplate = "a4-3x8"
+
+// builtinTemplates are the sheets most label printers and office label
+// stock come in.
+var builtinTemplates = []domain.LabelTemplate{
+	{
+		// A4 sheet of 24 labels, 70 x 37 mm.
+		Name: "a4-3x8", PageWidth: 210, PageHeight: 297, Columns: 3, Rows: 8,
+		MarginTop: 0.5, LabelWidth: 70, LabelHeight: 37,
+		Padding: 3, FontSize: 9, Symbology: Code128,
+	},
+	{
+		// US Letter sheet of 30 address labels, 2.625 x 1 in (Avery 5160).
+		Name: "letter-3x10", PageWidth: 215.9, PageHeight: 279.4, Columns: 3, Rows: 10,
+		MarginTop: 12.7, MarginLeft: 4.8, LabelWidth: 66.7, LabelHeight: 25.4, GapX: 3.2,
+		Padding: 1.5, FontSize: 7, Symbology: Code128,
+	},
+	{
+		// Thermal roll of 50 x 25 mm labels, one per page.
+		Name: "thermal-50x25", PageWidth: 50, PageHeight: 25, Columns: 1, Rows: 1,
+		LabelWidth: 50, LabelHeight: 25,
+		Padding: 1.5, FontSize: 6, Symbology: Code128,
+	},
+	{
+		// Thermal roll of 4 x 6 in shelf and bin labels, one per page.
+		Name: "thermal-4x6", PageWidth: 101.6, PageHeight: 152.4, Columns: 1, Rows: 1,
+		LabelWidth: 101.6, LabelHeight: 152.4,
+		Padding: 6, FontSize: 18, Symbology: QR,
+	},
+}
+
+// Templates returns the built in templates with extra added, an extra
+// template replacing the built in one of the same name, sorted by name.
+func Templates(extra []domain.LabelTemplate) []domain.LabelTemplate {
+	byName := make(map[string]domain.LabelTemplate)
+	for _, t := range append(slices.Clone(builtinTemplates), extra...) {
+		byName[t.Name] = t
+	}
+	templates := make([]domain.LabelTemplate, 0, len(byName))
+	for _, t := range byName {
+		templates = append(templates, t)
+	}
+	slices.SortFunc(templates, func(a, b domain.LabelTemplate) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return templates
+}
+
+// LoadTemplates reads a JSON array of templates from path and checks that
+// each fits its page.
+func LoadTemplates(path string) ([]domain.LabelTemplate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var templates []domain.LabelTemplate
+	if err := json.Unmarshal(data, &templates); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for _, t := range templates {
+		if err := validateTemplate(t); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return templates, nil
+}
+
+func validateTemplate(t domain.LabelTemplate) error {
+	invalid := func(reason string) error {
+		return fmt.Errorf("%w %q: %s", ErrInvalidTemplate, t.Name, reason)
+	}
+	switch {
+	case t.Name == "":
+		return invalid("missing name")
+	case t.Columns < 1 || t.Rows < 1:
+		return invalid("needs at least one column and one row")
+	case t.LabelWidth <= 0 || t.LabelHeight <= 0 || t.FontSize <= 0:
+		return invalid("label width, label height and font size must be positive")
+	case t.MarginTop < 0 || t.MarginLeft < 0 || t.GapX < 0 || t.GapY < 0 || t.Padding < 0:
+		return invalid("margins, gaps and padding cannot be negative")
+	case t.MarginLeft+float64(t.Columns)*t.LabelWidth+float64(t.Columns-1)*t.GapX > t.PageWidth,
+		t.MarginTop+float64(t.Rows)*t.LabelHeight+float64(t.Rows-1)*t.GapY > t.PageHeight:
+		return invalid("labels do not fit the page")
+	case 2*t.Padding >= t.LabelWidth || 2*t.Padding >= t.LabelHeight:
+		return invalid("padding leaves no room on the label")
+	case !slices.Contains(Symbologies, t.Symbology):
+		return invalid("symbology must be one of code128, ean13 or qr")
+	}
+	return nil
+}
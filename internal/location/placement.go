@@ -0,0 +1,165 @@
+package location
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"repository_class/internal/domain"
+	"repository_class/pkg/tenant"
+	"repository_class/pkg/txn"
+)
+
+// CheckPlacement reports whether quantity units of the product fit in the
+// bin, in every location above it and in its warehouse. It runs inside the
+// caller's transaction and locks the warehouse row, so placements in one
+// warehouse are checked one at a time. The units of lot excludeLotID, the
+// lot being moved, are not counted; pass 0 for new stock. The bin and the
+// product must belong to the tenant in ctx.
+func CheckPlacement(ctx context.Context, tx txn.Executor, binID, productID, quantity, excludeLotID int) error {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return err
+	}
+
+	var (
+		warehouseID int
+		kind        string
+	)
+	err = tx.QueryRowContext(ctx, "SELECT loc.warehouse_id, loc.kind"+locationsOfTenant+" AND loc.id=?;", tenantID, binID).Scan(&warehouseID, &kind)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+	if kind != domain.LocationBin {
+		return ErrNotBin
+	}
+
+	var productWarehouse int
+	err = tx.QueryRowContext(ctx, "SELECT id_warehouse FROM products WHERE id=? AND tenant_id=?;", productID, tenantID).Scan(&productWarehouse)
+	if err != nil {
+		return err
+	}
+	if productWarehouse != warehouseID {
+		return ErrWrongWarehouse
+	}
+
+	var capacity int
+	err = tx.QueryRowContext(ctx, "SELECT capacity FROM warehouses WHERE id=? AND tenant_id=? FOR UPDATE;", warehouseID, tenantID).Scan(&capacity)
+	if err != nil {
+		return err
+	}
+
+	var stored int
+	err = tx.QueryRowContext(ctx, "SELECT COALESCE(SUM(l.quantity), 0) FROM lots l "+
+		"INNER JOIN products p ON p.id = l.product_id WHERE p.id_warehouse=? AND p.tenant_id=? AND l.id<>?;", warehouseID, tenantID, excludeLotID).Scan(&stored)
+	if err != nil {
+		return err
+	}
+	// The moved lot is already in the warehouse, so only new stock counts
+	// against its capacity a second time.
+	if capacity > 0 && stored+quantity > capacity {
+		return ErrCapacityExceeded
+	}
+
+	locations, err := queryLocations(ctx, tx, tenantID, warehouseID)
+	if err != nil {
+		return err
+	}
+	direct, err := queryUnits(ctx, tx, tenantID, warehouseID, excludeLotID)
+	if err != nil {
+		return err
+	}
+	units := rollUp(locations, direct)
+
+	byID := make(map[int]domain.Location, len(locations))
+	for _, l := range locations {
+		byID[l.ID] = l
+	}
+	for id := &binID; id != nil; id = byID[*id].ParentID {
+		l := byID[*id]
+		if l.Capacity != nil && units[l.ID]+quantity > *l.Capacity {
+			return ErrCapacityExceeded
+		}
+	}
+
+	return nil
+}
+
+// querier is satisfied by *sql.DB and *sql.Tx.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+const locationColumns = "loc.id, loc.warehouse_id, loc.parent_id, loc.kind, loc.code, loc.capacity"
+
+// locationsOfTenant selects the locations loc of the warehouses w of a
+// tenant, bound first.
+const locationsOfTenant = " FROM locations loc INNER JOIN warehouses w ON w.id = loc.warehouse_id WHERE w.tenant_id=?"
+
+const listQuery = "SELECT " + locationColumns + locationsOfTenant + " AND loc.warehouse_id=? ORDER BY loc.id;"
+
+// unitsQuery sums the units stored directly in each location of a warehouse
+// of a tenant, leaving out one lot.
+const unitsQuery = "SELECT l.location_id, SUM(l.quantity) FROM lots l " +
+	"INNER JOIN locations loc ON loc.id = l.location_id " +
+	"INNER JOIN warehouses w ON w.id = loc.warehouse_id " +
+	"WHERE w.tenant_id=? AND loc.warehouse_id=? AND l.id<>? GROUP BY l.location_id;"
+
+func queryLocations(ctx context.Context, q querier, tenantID string, warehouseID int) (locations []domain.Location, err error) {
+	rows, err := q.QueryContext(ctx, listQuery, tenantID, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		l := domain.Location{}
+		if err := rows.Scan(&l.ID, &l.WarehouseID, &l.ParentID, &l.Kind, &l.Code, &l.Capacity); err != nil {
+			return nil, err
+		}
+		locations = append(locations, l)
+	}
+
+	return locations, rows.Err()
+}
+
+// queryUnits returns the units stored directly in each location of the
+// warehouse, leaving out lot excludeLotID.
+func queryUnits(ctx context.Context, q querier, tenantID string, warehouseID, excludeLotID int) (map[int]int, error) {
+	rows, err := q.QueryContext(ctx, unitsQuery, tenantID, warehouseID, excludeLotID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	units := make(map[int]int)
+	for rows.Next() {
+		var id, n int
+		if err := rows.Scan(&id, &n); err != nil {
+			return nil, err
+		}
+		units[id] = n
+	}
+
+	return units, rows.Err()
+}
+
+// rollUp adds the units stored directly in each location to every location
+// above it, returning the units held in and below each location.
+func rollUp(locations []domain.Location, direct map[int]int) map[int]int {
+	parent := make(map[int]*int, len(locations))
+	for _, l := range locations {
+		parent[l.ID] = l.ParentID
+	}
+
+	total := make(map[int]int, len(locations))
+	for id, n := range direct {
+		for at := &id; at != nil; at = parent[*at] {
+			total[*at] += n
+		}
+	}
+	return total
+}
@@ -0,0 +1,26 @@
+package location
+
+import (
+	"testing"
+
+	"repository_class/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRollUp(t *testing.T) {
+	zone, aisle, shelf := 1, 2, 3
+	locations := []domain.Location{
+		{ID: zone, Kind: domain.LocationZone},
+		{ID: aisle, ParentID: &zone, Kind: domain.LocationAisle},
+		{ID: shelf, ParentID: &aisle, Kind: domain.LocationShelf},
+		{ID: 4, ParentID: &shelf, Kind: domain.LocationBin},
+		{ID: 5, ParentID: &shelf, Kind: domain.LocationBin},
+		{ID: 6, Kind: domain.LocationZone},
+	}
+
+	units := rollUp(locations, map[int]int{4: 10, 5: 5})
+
+	assert.Equal(t, map[int]int{zone: 15, aisle: 15, shelf: 15, 4: 10, 5: 5}, units)
+	assert.Zero(t, units[6])
+}
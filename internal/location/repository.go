@@ -0,0 +1,167 @@
+package location
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"repository_class/internal/domain"
+	"repository_class/pkg/instrument"
+	"repository_class/pkg/tenant"
+)
+
+// Repository encapsulates the storage of a Location. Every method only sees
+// the locations of the warehouses of the tenant in its context and fails
+// with tenant.ErrMissing when there is none.
+type Repository interface {
+	ListByWarehouse(ctx context.Context, warehouseID int) ([]domain.Location, error)
+	// Units returns the units stored directly in each location of the
+	// warehouse.
+	Units(ctx context.Context, warehouseID int) (map[int]int, error)
+	Get(ctx context.Context, id int) (domain.Location, error)
+	InUse(ctx context.Context, id int) (bool, error)
+	Save(ctx context.Context, l domain.Location) (int, error)
+	Update(ctx context.Context, l domain.Location) error
+	Delete(ctx context.Context, id int) error
+}
+
+const repositoryName = "location"
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) ListByWarehouse(ctx context.Context, warehouseID int) (locations []domain.Location, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, done := instrument.Query(ctx, repositoryName, "ListByWarehouse", listQuery)
+	defer func() { done(err) }()
+
+	return queryLocations(ctx, r.db, tenantID, warehouseID)
+}
+
+func (r *repository) Units(ctx context.Context, warehouseID int) (units map[int]int, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, done := instrument.Query(ctx, repositoryName, "Units", unitsQuery)
+	defer func() { done(err) }()
+
+	return queryUnits(ctx, r.db, tenantID, warehouseID, 0)
+}
+
+func (r *repository) Get(ctx context.Context, id int) (l domain.Location, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return domain.Location{}, err
+	}
+	query := "SELECT " + locationColumns + locationsOfTenant + " AND loc.id=?;"
+	ctx, done := instrument.Query(ctx, repositoryName, "Get", query)
+	defer func() { done(err) }()
+
+	row := r.db.QueryRowContext(ctx, query, tenantID, id)
+	err = row.Scan(&l.ID, &l.WarehouseID, &l.ParentID, &l.Kind, &l.Code, &l.Capacity)
+	if err != nil {
+		return domain.Location{}, err
+	}
+
+	return l, nil
+}
+
+// InUse fails with ErrNotFound when the tenant has no location id.
+func (r *repository) InUse(ctx context.Context, id int) (used bool, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return false, err
+	}
+	query := "SELECT EXISTS(SELECT 1 FROM locations WHERE parent_id=loc.id) OR EXISTS(SELECT 1 FROM lots WHERE location_id=loc.id AND quantity>0)" +
+		locationsOfTenant + " AND loc.id=?;"
+	ctx, done := instrument.Query(ctx, repositoryName, "InUse", query)
+	defer func() { done(err) }()
+
+	err = r.db.QueryRowContext(ctx, query, tenantID, id).Scan(&used)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, ErrNotFound
+	}
+	return used, err
+}
+
+// Save fails with ErrWarehouseNotFound when the tenant has no warehouse
+// l.WarehouseID.
+func (r *repository) Save(ctx context.Context, l domain.Location) (_ int, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return 0, err
+	}
+	query := "INSERT INTO locations (warehouse_id, parent_id, kind, code, capacity) " +
+		"SELECT id, ?, ?, ?, ? FROM warehouses WHERE id=? AND tenant_id=?;"
+	ctx, done := instrument.Query(ctx, repositoryName, "Save", query)
+	defer func() { done(err) }()
+
+	res, err := r.db.ExecContext(ctx, query, l.ParentID, l.Kind, l.Code, l.Capacity, l.WarehouseID, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	affect, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if affect < 1 {
+		return 0, ErrWarehouseNotFound
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+func (r *repository) Update(ctx context.Context, l domain.Location) (err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return err
+	}
+	query := "UPDATE locations loc INNER JOIN warehouses w ON w.id = loc.warehouse_id SET loc.code=?, loc.capacity=? WHERE loc.id=? AND w.tenant_id=?;"
+	ctx, done := instrument.Query(ctx, repositoryName, "Update", query)
+	defer func() { done(err) }()
+
+	_, err = r.db.ExecContext(ctx, query, l.Code, l.Capacity, l.ID, tenantID)
+	return err
+}
+
+func (r *repository) Delete(ctx context.Context, id int) (err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return err
+	}
+	query := "DELETE loc FROM locations loc INNER JOIN warehouses w ON w.id = loc.warehouse_id WHERE loc.id=? AND w.tenant_id=?;"
+	ctx, done := instrument.Query(ctx, repositoryName, "Delete", query)
+	defer func() { done(err) }()
+
+	res, err := r.db.ExecContext(ctx, query, id, tenantID)
+	if err != nil {
+		return err
+	}
+
+	affect, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affect < 1 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package location
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"repository_class/internal/domain"
+	"repository_class/internal/testdb"
+	"repository_class/pkg/tenant"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckPlacement(t *testing.T) {
+	db := testdb.Open(t)
+	rp := NewRepository(db)
+	ctx := tenant.WithContext(context.Background(), "acme")
+	warehouseID := testdb.Warehouse(t, db, "acme", 50)
+	productID := testdb.Product(t, db, "acme", warehouseID, "W-1")
+
+	save := func(parentID *int, kind string, capacity *int) int {
+		id, err := rp.Save(ctx, domain.Location{WarehouseID: warehouseID, ParentID: parentID, Kind: kind, Code: kind, Capacity: capacity})
+		assert.NoError(t, err)
+		return id
+	}
+	shelfCapacity, binCapacity := 12, 10
+	zone := save(nil, domain.LocationZone, nil)
+	aisle := save(&zone, domain.LocationAisle, nil)
+	shelf := save(&aisle, domain.LocationShelf, &shelfCapacity)
+	bin := save(&shelf, domain.LocationBin, &binCapacity)
+	sibling := save(&shelf, domain.LocationBin, nil)
+
+	assert.NoError(t, CheckPlacement(ctx, db, bin, productID, 10, 0))
+	assert.ErrorIs(t, CheckPlacement(ctx, db, bin, productID, 11, 0), ErrCapacityExceeded)
+	assert.ErrorIs(t, CheckPlacement(ctx, db, shelf, productID, 1, 0), ErrNotBin)
+
+	// Units in one bin count against the shelf above its sibling.
+	lotID := testdb.Lot(t, db, domain.Lot{ProductID: productID, LotCode: "L-1", Quantity: 8, Expiration: time.Now().Add(time.Hour)})
+	_, err := db.Exec("UPDATE lots SET location_id=? WHERE id=?;", bin, lotID)
+	assert.NoError(t, err)
+	assert.NoError(t, CheckPlacement(ctx, db, sibling, productID, 4, 0))
+	assert.ErrorIs(t, CheckPlacement(ctx, db, sibling, productID, 5, 0), ErrCapacityExceeded)
+	// A moved lot does not count against itself.
+	assert.NoError(t, CheckPlacement(ctx, db, sibling, productID, 8, lotID))
+
+	// The warehouse caps the stock in every bin.
+	testdb.Lot(t, db, domain.Lot{ProductID: productID, LotCode: "L-2", Quantity: 40, Expiration: time.Now().Add(time.Hour)})
+	assert.ErrorIs(t, CheckPlacement(ctx, db, sibling, productID, 3, 0), ErrCapacityExceeded)
+
+	other := testdb.Warehouse(t, db, "acme", 0)
+	otherZone, err := rp.Save(ctx, domain.Location{WarehouseID: other, Kind: domain.LocationZone, Code: "Z"})
+	assert.NoError(t, err)
+	otherBin, err := rp.Save(ctx, domain.Location{WarehouseID: other, ParentID: &otherZone, Kind: domain.LocationBin, Code: "B"})
+	assert.NoError(t, err)
+	assert.ErrorIs(t, CheckPlacement(ctx, db, otherBin, productID, 1, 0), ErrWrongWarehouse)
+
+	units, err := rp.Units(ctx, warehouseID)
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]int{bin: 8}, units)
+}
+
+func TestTenantIsolation(t *testing.T) {
+	db := testdb.Open(t)
+	rp := NewRepository(db)
+	acme := tenant.WithContext(context.Background(), "acme")
+	globex := tenant.WithContext(context.Background(), "globex")
+	warehouseID := testdb.Warehouse(t, db, "acme", 0)
+
+	id, err := rp.Save(acme, domain.Location{WarehouseID: warehouseID, Kind: domain.LocationZone, Code: "Z-1"})
+	assert.NoError(t, err)
+
+	_, err = rp.Get(globex, id)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	locations, err := rp.ListByWarehouse(globex, warehouseID)
+	assert.NoError(t, err)
+	assert.Empty(t, locations)
+	units, err := rp.Units(globex, warehouseID)
+	assert.NoError(t, err)
+	assert.Empty(t, units)
+	_, err = rp.InUse(globex, id)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = rp.Save(globex, domain.Location{WarehouseID: warehouseID, Kind: domain.LocationZone, Code: "Z-2"})
+	assert.ErrorIs(t, err, ErrWarehouseNotFound)
+	assert.ErrorIs(t, CheckPlacement(globex, db, id, 1, 1, 0), ErrNotFound)
+	assert.NoError(t, rp.Update(globex, domain.Location{ID: id, Code: "stolen"}))
+	assert.ErrorIs(t, rp.Delete(globex, id), ErrNotFound)
+
+	l, err := rp.Get(acme, id)
+	assert.NoError(t, err)
+	assert.Equal(t, "Z-1", l.Code)
+}
@@ -0,0 +1,228 @@
+package location
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"repository_class/internal/domain"
+	"repository_class/internal/warehouse"
+
+	"go.opentelemetry.io/otel"
+)
+
+// Errors
+var (
+	ErrNotFound          = errors.New("location not found")
+	ErrWarehouseNotFound = errors.New("warehouse not found")
+	ErrInvalidKind       = errors.New("location kind does not fit under its parent")
+	ErrInvalidStruct     = errors.New("invalid input structure for location")
+	ErrInUse             = errors.New("location still has sublocations or stock")
+	ErrNotBin            = errors.New("stock can only be placed in a bin")
+	ErrWrongWarehouse    = errors.New("bin belongs to another warehouse than the product")
+	ErrCapacityExceeded  = errors.New("placement exceeds the capacity of the bin, a location above it or the warehouse")
+)
+
+type Service interface {
+	// Tree returns the zones of the warehouse with their sublocations
+	// nested and the units stored in and below each location.
+	Tree(ctx context.Context, warehouseID int) ([]domain.LocationNode, error)
+	// Get returns the location with the units in and below it.
+	Get(ctx context.Context, id int) (domain.LocationNode, error)
+	Create(ctx context.Context, l domain.Location) (domain.Location, error)
+	// Update changes the code and capacity of a location; it cannot move.
+	Update(ctx context.Context, l domain.Location, id int) (domain.Location, error)
+	Delete(ctx context.Context, id int) error
+}
+
+var tracer = otel.Tracer("repository_class/internal/location")
+
+type service struct {
+	repo       Repository
+	warehouses warehouse.Repository
+}
+
+func NewService(repo *Repository, warehouses warehouse.Repository) Service {
+	return &service{repo: *repo, warehouses: warehouses}
+}
+
+// parentKind returns the kind a location of the given kind must sit under,
+// "" for zones, and false for an unknown kind.
+func parentKind(kind string) (string, bool) {
+	for i, k := range domain.LocationKinds {
+		if k == kind {
+			if i == 0 {
+				return "", true
+			}
+			return domain.LocationKinds[i-1], true
+		}
+	}
+	return "", false
+}
+
+func (s *service) Tree(ctx context.Context, warehouseID int) ([]domain.LocationNode, error) {
+	ctx, span := tracer.Start(ctx, "location.Service.Tree")
+	defer span.End()
+
+	w, err := s.warehouses.Get(ctx, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	if w.ID == 0 {
+		return nil, ErrWarehouseNotFound
+	}
+
+	locations, err := s.repo.ListByWarehouse(ctx, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	direct, err := s.repo.Units(ctx, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	units := rollUp(locations, direct)
+
+	children := make(map[int][]domain.Location)
+	var roots []domain.Location
+	for _, l := range locations {
+		if l.ParentID == nil {
+			roots = append(roots, l)
+			continue
+		}
+		children[*l.ParentID] = append(children[*l.ParentID], l)
+	}
+
+	var build func(l domain.Location) domain.LocationNode
+	build = func(l domain.Location) domain.LocationNode {
+		node := domain.LocationNode{Location: l, Units: units[l.ID], Children: []domain.LocationNode{}}
+		for _, child := range children[l.ID] {
+			node.Children = append(node.Children, build(child))
+		}
+		return node
+	}
+
+	tree := []domain.LocationNode{}
+	for _, root := range roots {
+		tree = append(tree, build(root))
+	}
+	return tree, nil
+}
+
+func (s *service) Get(ctx context.Context, id int) (domain.LocationNode, error) {
+	ctx, span := tracer.Start(ctx, "location.Service.Get")
+	defer span.End()
+
+	l, err := s.repo.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.LocationNode{}, ErrNotFound
+		}
+		return domain.LocationNode{}, err
+	}
+
+	locations, err := s.repo.ListByWarehouse(ctx, l.WarehouseID)
+	if err != nil {
+		return domain.LocationNode{}, err
+	}
+	direct, err := s.repo.Units(ctx, l.WarehouseID)
+	if err != nil {
+		return domain.LocationNode{}, err
+	}
+
+	units := rollUp(locations, direct)
+
+	// Only the direct sublocations are listed; Tree renders the full depth.
+	node := domain.LocationNode{Location: l, Units: units[l.ID], Children: []domain.LocationNode{}}
+	for _, child := range locations {
+		if child.ParentID != nil && *child.ParentID == id {
+			node.Children = append(node.Children, domain.LocationNode{Location: child, Units: units[child.ID], Children: []domain.LocationNode{}})
+		}
+	}
+	return node, nil
+}
+
+func (s *service) Create(ctx context.Context, l domain.Location) (domain.Location, error) {
+	ctx, span := tracer.Start(ctx, "location.Service.Create")
+	defer span.End()
+
+	if l.Code == "" || (l.Capacity != nil && *l.Capacity < 0) {
+		return domain.Location{}, ErrInvalidStruct
+	}
+	want, ok := parentKind(l.Kind)
+	if !ok {
+		return domain.Location{}, ErrInvalidKind
+	}
+
+	w, err := s.warehouses.Get(ctx, l.WarehouseID)
+	if err != nil {
+		return domain.Location{}, err
+	}
+	if w.ID == 0 {
+		return domain.Location{}, ErrWarehouseNotFound
+	}
+
+	if l.ParentID == nil {
+		if want != "" {
+			return domain.Location{}, ErrInvalidKind
+		}
+	} else {
+		parent, err := s.repo.Get(ctx, *l.ParentID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.Location{}, ErrNotFound
+			}
+			return domain.Location{}, err
+		}
+		if parent.WarehouseID != l.WarehouseID || parent.Kind != want {
+			return domain.Location{}, ErrInvalidKind
+		}
+	}
+
+	id, err := s.repo.Save(ctx, l)
+	if err != nil {
+		return domain.Location{}, err
+	}
+	l.ID = id
+	return l, nil
+}
+
+func (s *service) Update(ctx context.Context, l domain.Location, id int) (domain.Location, error) {
+	ctx, span := tracer.Start(ctx, "location.Service.Update")
+	defer span.End()
+
+	current, err := s.repo.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Location{}, ErrNotFound
+		}
+		return domain.Location{}, err
+	}
+	if l.Capacity != nil && *l.Capacity < 0 {
+		return domain.Location{}, ErrInvalidStruct
+	}
+
+	if l.Code != "" {
+		current.Code = l.Code
+	}
+	if l.Capacity != nil {
+		current.Capacity = l.Capacity
+	}
+	if err := s.repo.Update(ctx, current); err != nil {
+		return domain.Location{}, err
+	}
+	return current, nil
+}
+
+func (s *service) Delete(ctx context.Context, id int) error {
+	ctx, span := tracer.Start(ctx, "location.Service.Delete")
+	defer span.End()
+
+	used, err := s.repo.InUse(ctx, id)
+	if err != nil {
+		return err
+	}
+	if used {
+		return ErrInUse
+	}
+	return s.repo.Delete(ctx, id)
+}
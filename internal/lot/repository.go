@@ -0,0 +1,292 @@
+package lot
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"repository_class/internal/domain"
+	"repository_class/internal/location"
+	"repository_class/pkg/instrument"
+	"repository_class/pkg/tenant"
+	"repository_class/pkg/txn"
+)
+
+// Repository encapsulates the storage of a Lot. Every write keeps the
+// quantity and expiration of the product in step with its lots. Methods
+// only see the lots of the products of the tenant in their context, failing
+// with tenant.ErrMissing when there is none, and join the transaction of a
+// txn.Run in their context.
+type Repository interface {
+	Get(ctx context.Context, id int) (domain.Lot, error)
+	ListByProduct(ctx context.Context, productID int) ([]domain.Lot, error)
+	// Receive stores a new lot and returns its id with the product quantity
+	// before it. A lot with a LocationID must fit in that bin, and one
+	// without a CostCurrency is costed in the currency of its product.
+	Receive(ctx context.Context, l domain.Lot) (id int, before int, err error)
+	// Move places the lot in the bin, which must have room for it.
+	Move(ctx context.Context, id, binID int) error
+	// Issue takes stock first-expired-first-out from the lots not expired at
+	// now and returns the allocations with the product quantity before it.
+	Issue(ctx context.Context, productID, quantity int, now time.Time) ([]domain.LotAllocation, int, error)
+	// WriteOff removes stock from any lot, earliest expiration first, and
+	// returns the allocations with the product quantity before it. Neither
+	// takes unexpired units that active reservations hold.
+	WriteOff(ctx context.Context, productID, quantity int) ([]domain.LotAllocation, int, error)
+	Expiring(ctx context.Context, before time.Time, warehouseID *int) ([]domain.ExpiringLot, error)
+}
+
+const repositoryName = "lot"
+
+const lotColumns = "l.id, l.product_id, l.lot_code, l.quantity, l.unit_cost, l.cost_currency, l.expiration, l.received_at, l.location_id"
+
+// lotsOfTenant selects the lots l of the products p of a tenant, bound
+// first.
+const lotsOfTenant = " FROM lots l INNER JOIN products p ON p.id = l.product_id WHERE p.tenant_id=?"
+
+const lockProduct = "SELECT quantity FROM products WHERE id=? AND tenant_id=? FOR UPDATE;"
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanLot(s scanner) (domain.Lot, error) {
+	l := domain.Lot{}
+	err := s.Scan(&l.ID, &l.ProductID, &l.LotCode, &l.Quantity, &l.UnitCost, &l.CostCurrency, &l.Expiration, &l.ReceivedAt, &l.LocationID)
+	return l, err
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) Get(ctx context.Context, id int) (l domain.Lot, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return domain.Lot{}, err
+	}
+	query := "SELECT " + lotColumns + lotsOfTenant + " AND l.id=?;"
+	ctx, done := instrument.Query(ctx, repositoryName, "Get", query)
+	defer func() { done(err) }()
+
+	l, err = scanLot(txn.From(ctx, r.db).QueryRowContext(ctx, query, tenantID, id))
+	if err != nil {
+		return domain.Lot{}, err
+	}
+
+	return l, nil
+}
+
+func (r *repository) ListByProduct(ctx context.Context, productID int) (lots []domain.Lot, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := "SELECT " + lotColumns + lotsOfTenant + " AND l.product_id=? ORDER BY l.expiration, l.received_at, l.id;"
+	ctx, done := instrument.Query(ctx, repositoryName, "ListByProduct", query)
+	defer func() { done(err) }()
+
+	rows, err := txn.From(ctx, r.db).QueryContext(ctx, query, tenantID, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		l, err := scanLot(rows)
+		if err != nil {
+			return nil, err
+		}
+		lots = append(lots, l)
+	}
+
+	return lots, rows.Err()
+}
+
+func (r *repository) Receive(ctx context.Context, l domain.Lot) (id int, before int, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	lock := "SELECT quantity, currency FROM products WHERE id=? AND tenant_id=? FOR UPDATE;"
+	insert := "INSERT INTO lots (product_id, lot_code, quantity, unit_cost, cost_currency, expiration, received_at, location_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?);"
+	ctx, done := instrument.Query(ctx, repositoryName, "Receive", lock+" "+insert)
+	defer func() { done(err) }()
+
+	tx, err := txn.Begin(ctx, r.db)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	var currency string
+	if err = tx.QueryRowContext(ctx, lock, l.ProductID, tenantID).Scan(&before, &currency); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, 0, ErrProductNotFound
+		}
+		return 0, 0, err
+	}
+	if l.CostCurrency == "" {
+		l.CostCurrency = currency
+	}
+
+	if l.LocationID != nil {
+		if err = location.CheckPlacement(ctx, tx, *l.LocationID, l.ProductID, l.Quantity, 0); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	res, err := tx.ExecContext(ctx, insert, l.ProductID, l.LotCode, l.Quantity, l.UnitCost, l.CostCurrency, l.Expiration, l.ReceivedAt, l.LocationID)
+	if err != nil {
+		return 0, 0, err
+	}
+	lastID, err := res.LastInsertId()
+	if err != nil {
+		return 0, 0, err
+	}
+	if err = record(ctx, tx, l.ProductID, int(lastID), l.Quantity); err != nil {
+		return 0, 0, err
+	}
+	if err = Sync(ctx, tx, l.ProductID); err != nil {
+		return 0, 0, err
+	}
+
+	return int(lastID), before, tx.Commit()
+}
+
+func (r *repository) Move(ctx context.Context, id, binID int) (err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return err
+	}
+	lock := "SELECT l.product_id, l.quantity" + lotsOfTenant + " AND l.id=? FOR UPDATE;"
+	move := "UPDATE lots SET location_id=? WHERE id=?;"
+	ctx, done := instrument.Query(ctx, repositoryName, "Move", lock+" "+move)
+	defer func() { done(err) }()
+
+	tx, err := txn.Begin(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var productID, quantity int
+	if err = tx.QueryRowContext(ctx, lock, tenantID, id).Scan(&productID, &quantity); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+	if err = location.CheckPlacement(ctx, tx, binID, productID, quantity, id); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, move, binID, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *repository) Issue(ctx context.Context, productID, quantity int, now time.Time) (allocations []domain.LotAllocation, before int, err error) {
+	ctx, done := instrument.Query(ctx, repositoryName, "Issue", lockProduct+" "+AvailableQuery)
+	defer func() { done(err) }()
+
+	return r.take(ctx, productID, now, func(tx txn.Executor) ([]domain.LotAllocation, error) {
+		return Issue(ctx, tx, productID, quantity, now)
+	})
+}
+
+func (r *repository) WriteOff(ctx context.Context, productID, quantity int) (allocations []domain.LotAllocation, before int, err error) {
+	ctx, done := instrument.Query(ctx, repositoryName, "WriteOff", lockProduct+" "+AvailableQuery)
+	defer func() { done(err) }()
+
+	return r.take(ctx, productID, time.Now(), func(tx txn.Executor) ([]domain.LotAllocation, error) {
+		return WriteOff(ctx, tx, productID, quantity)
+	})
+}
+
+// take runs fn in a transaction holding the lock on the product row, the
+// same lock Reserve takes. It fails with ErrInsufficientStock, changing
+// nothing, when fn takes from the lots not expired at now units that active
+// reservations hold.
+func (r *repository) take(ctx context.Context, productID int, now time.Time, fn func(tx txn.Executor) ([]domain.LotAllocation, error)) ([]domain.LotAllocation, int, error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	tx, err := txn.Begin(ctx, r.db)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	var before int
+	if err := tx.QueryRowContext(ctx, lockProduct, productID, tenantID).Scan(&before); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, 0, ErrProductNotFound
+		}
+		return nil, 0, err
+	}
+
+	available, err := Available(ctx, tx, productID, now)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	allocations, err := fn(tx)
+	if err != nil {
+		return nil, 0, err
+	}
+	// Units of expired lots are never held, so only unexpired ones count.
+	usable := 0
+	for _, a := range allocations {
+		if a.Expiration.After(now) {
+			usable += a.Quantity
+		}
+	}
+	if usable > max(available, 0) {
+		return nil, 0, ErrInsufficientStock
+	}
+
+	return allocations, before, tx.Commit()
+}
+
+func (r *repository) Expiring(ctx context.Context, before time.Time, warehouseID *int) (lots []domain.ExpiringLot, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	args := []interface{}{tenantID, before}
+	query := "SELECT " + lotColumns + ", p.name, p.code_value, p.id_warehouse" + lotsOfTenant + " AND l.quantity>0 AND l.expiration<?"
+	if warehouseID != nil {
+		query += " AND p.id_warehouse=?"
+		args = append(args, *warehouseID)
+	}
+	query += " ORDER BY l.expiration, l.id;"
+	ctx, done := instrument.Query(ctx, repositoryName, "Expiring", query)
+	defer func() { done(err) }()
+
+	rows, err := txn.From(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		l := domain.ExpiringLot{}
+		if err := rows.Scan(&l.ID, &l.ProductID, &l.LotCode, &l.Quantity, &l.UnitCost, &l.CostCurrency, &l.Expiration, &l.ReceivedAt, &l.LocationID,
+			&l.ProductName, &l.CodeValue, &l.WarehouseID); err != nil {
+			return nil, err
+		}
+		lots = append(lots, l)
+	}
+
+	return lots, rows.Err()
+}
@@ -0,0 +1,107 @@
+package lot
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"repository_class/internal/domain"
+	"repository_class/internal/testdb"
+	"repository_class/pkg/tenant"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenantIsolation(t *testing.T) {
+	db := testdb.Open(t)
+	rp := NewRepository(db)
+	acme := tenant.WithContext(context.Background(), "acme")
+	globex := tenant.WithContext(context.Background(), "globex")
+	productID := testdb.Product(t, db, "acme", testdb.Warehouse(t, db, "acme", 0), "W-1")
+
+	id, _, err := rp.Receive(acme, domain.Lot{ProductID: productID, LotCode: "L-1", Quantity: 5,
+		UnitCost: decimal.NewFromInt(1), Expiration: time.Now().Add(time.Hour)})
+	assert.NoError(t, err)
+
+	_, err = rp.Get(globex, id)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	lots, err := rp.ListByProduct(globex, productID)
+	assert.NoError(t, err)
+	assert.Empty(t, lots)
+	expiring, err := rp.Expiring(globex, time.Now().Add(2*time.Hour), nil)
+	assert.NoError(t, err)
+	assert.Empty(t, expiring)
+
+	_, _, err = rp.Receive(globex, domain.Lot{ProductID: productID, LotCode: "L-2", Quantity: 5, Expiration: time.Now().Add(time.Hour)})
+	assert.ErrorIs(t, err, ErrProductNotFound)
+	_, _, err = rp.Issue(globex, productID, 1, time.Now())
+	assert.ErrorIs(t, err, ErrProductNotFound)
+	_, _, err = rp.WriteOff(globex, productID, 1)
+	assert.ErrorIs(t, err, ErrProductNotFound)
+	assert.ErrorIs(t, rp.Move(globex, id, 1), ErrNotFound)
+
+	l, err := rp.Get(acme, id)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, l.Quantity)
+}
+
+func TestIssueFirstExpiredFirstOut(t *testing.T) {
+	db := testdb.Open(t)
+	rp := NewRepository(db)
+	ctx := tenant.WithContext(context.Background(), "acme")
+	productID := testdb.Product(t, db, "acme", testdb.Warehouse(t, db, "acme", 0), "W-1")
+	now := time.Now()
+	expired := testdb.Lot(t, db, domain.Lot{ProductID: productID, LotCode: "EXPIRED", Quantity: 3, Expiration: now.Add(-time.Hour)})
+	later := testdb.Lot(t, db, domain.Lot{ProductID: productID, LotCode: "LATER", Quantity: 3, Expiration: now.Add(2 * time.Hour)})
+	sooner := testdb.Lot(t, db, domain.Lot{ProductID: productID, LotCode: "SOONER", Quantity: 3, Expiration: now.Add(time.Hour)})
+
+	allocations, _, err := rp.Issue(ctx, productID, 4, now)
+	assert.NoError(t, err)
+	if assert.Len(t, allocations, 2) {
+		assert.Equal(t, sooner, allocations[0].LotID)
+		assert.Equal(t, 3, allocations[0].Quantity)
+		assert.Equal(t, later, allocations[1].LotID)
+		assert.Equal(t, 1, allocations[1].Quantity)
+	}
+
+	// Expired stock is never issued, only written off.
+	_, _, err = rp.Issue(ctx, productID, 3, now)
+	assert.ErrorIs(t, err, ErrInsufficientStock)
+	allocations, _, err = rp.WriteOff(ctx, productID, 1)
+	assert.NoError(t, err)
+	if assert.Len(t, allocations, 1) {
+		assert.Equal(t, expired, allocations[0].LotID)
+	}
+}
+
+func TestSync(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	productID := testdb.Product(t, db, "acme", testdb.Warehouse(t, db, "acme", 0), "W-1")
+	sooner := time.Now().Add(time.Hour).Truncate(time.Second)
+	testdb.Lot(t, db, domain.Lot{ProductID: productID, LotCode: "L-1", Quantity: 2, Expiration: sooner.Add(time.Hour)})
+	testdb.Lot(t, db, domain.Lot{ProductID: productID, LotCode: "L-2", Quantity: 3, Expiration: sooner})
+	testdb.Lot(t, db, domain.Lot{ProductID: productID, LotCode: "EMPTY", Quantity: 0, Expiration: sooner.Add(-time.Minute)})
+
+	product := func() (quantity int, expiration time.Time) {
+		err := db.QueryRow("SELECT quantity, expiration FROM products WHERE id=?;", productID).Scan(&quantity, &expiration)
+		assert.NoError(t, err)
+		return quantity, expiration
+	}
+
+	// The product row holds the quantity of all its lots and the earliest
+	// expiration of those not empty.
+	assert.NoError(t, Sync(ctx, db, productID))
+	quantity, expiration := product()
+	assert.Equal(t, 5, quantity)
+	assert.True(t, sooner.Equal(expiration), expiration)
+
+	_, err := db.Exec("UPDATE lots SET quantity=0 WHERE product_id=?;", productID)
+	assert.NoError(t, err)
+	assert.NoError(t, Sync(ctx, db, productID))
+	quantity, expiration = product()
+	assert.Equal(t, 0, quantity)
+	assert.True(t, sooner.Equal(expiration), expiration)
+}
@@ -0,0 +1,169 @@
+package lot
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"repository_class/internal/domain"
+	"repository_class/internal/exchange"
+	"repository_class/internal/reorder"
+
+	"go.opentelemetry.io/otel"
+)
+
+// Errors
+var (
+	ErrNotFound          = errors.New("lot not found")
+	ErrProductNotFound   = errors.New("product not found")
+	ErrInsufficientStock = errors.New("not enough unexpired stock in lots")
+	ErrInvalidQuantity   = errors.New("lot quantity must be positive")
+	ErrInvalidExpiration = errors.New("lot expiration is required")
+	ErrInvalidCost       = errors.New("lot unit cost must not be negative")
+)
+
+type Service interface {
+	Get(ctx context.Context, id int) (domain.Lot, error)
+	ListByProduct(ctx context.Context, productID int) ([]domain.Lot, error)
+	// Receive adds a lot to its product. The lot code defaults to the
+	// received time, the received time to now and the cost currency to the
+	// currency of the product.
+	Receive(ctx context.Context, l domain.Lot) (domain.Lot, error)
+	// Move places a lot in a bin.
+	Move(ctx context.Context, id, binID int) (domain.Lot, error)
+	// Issue takes stock first-expired-first-out, skipping expired lots.
+	Issue(ctx context.Context, productID, quantity int) ([]domain.LotAllocation, error)
+	// WriteOff removes stock for a correction, expired lots first.
+	WriteOff(ctx context.Context, productID, quantity int) ([]domain.LotAllocation, error)
+	// Expiring lists the lots in stock that expire within the given window,
+	// already expired ones included.
+	Expiring(ctx context.Context, within time.Duration, warehouseID *int) ([]domain.ExpiringLot, error)
+}
+
+var tracer = otel.Tracer("repository_class/internal/lot")
+
+type service struct {
+	repo  Repository
+	stock reorder.Service
+}
+
+func NewService(repo *Repository, stock reorder.Service) Service {
+	return &service{repo: *repo, stock: stock}
+}
+
+func (s *service) Get(ctx context.Context, id int) (domain.Lot, error) {
+	ctx, span := tracer.Start(ctx, "lot.Service.Get")
+	defer span.End()
+
+	l, err := s.repo.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Lot{}, ErrNotFound
+		}
+		return domain.Lot{}, err
+	}
+	return l, nil
+}
+
+func (s *service) ListByProduct(ctx context.Context, productID int) ([]domain.Lot, error) {
+	ctx, span := tracer.Start(ctx, "lot.Service.ListByProduct")
+	defer span.End()
+
+	lots, err := s.repo.ListByProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if lots == nil {
+		return []domain.Lot{}, nil
+	}
+	return lots, nil
+}
+
+func (s *service) Receive(ctx context.Context, l domain.Lot) (domain.Lot, error) {
+	ctx, span := tracer.Start(ctx, "lot.Service.Receive")
+	defer span.End()
+
+	if l.Quantity <= 0 {
+		return domain.Lot{}, ErrInvalidQuantity
+	}
+	if l.Expiration.IsZero() {
+		return domain.Lot{}, ErrInvalidExpiration
+	}
+	if l.UnitCost.IsNegative() {
+		return domain.Lot{}, ErrInvalidCost
+	}
+	if l.CostCurrency != "" {
+		code, err := exchange.ParseCurrency(l.CostCurrency)
+		if err != nil {
+			return domain.Lot{}, err
+		}
+		l.CostCurrency = code
+	}
+	if l.ReceivedAt.IsZero() {
+		l.ReceivedAt = time.Now()
+	}
+	if l.LotCode == "" {
+		l.LotCode = l.ReceivedAt.UTC().Format("20060102-150405.000000")
+	}
+
+	id, _, err := s.repo.Receive(ctx, l)
+	if err != nil {
+		return domain.Lot{}, err
+	}
+	return s.Get(ctx, id)
+}
+
+func (s *service) Move(ctx context.Context, id, binID int) (domain.Lot, error) {
+	ctx, span := tracer.Start(ctx, "lot.Service.Move")
+	defer span.End()
+
+	if err := s.repo.Move(ctx, id, binID); err != nil {
+		return domain.Lot{}, err
+	}
+	return s.Get(ctx, id)
+}
+
+func (s *service) Issue(ctx context.Context, productID, quantity int) ([]domain.LotAllocation, error) {
+	ctx, span := tracer.Start(ctx, "lot.Service.Issue")
+	defer span.End()
+
+	if quantity <= 0 {
+		return nil, ErrInvalidQuantity
+	}
+	allocations, before, err := s.repo.Issue(ctx, productID, quantity, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	s.stock.QuantityChanged(ctx, productID, before)
+	return allocations, nil
+}
+
+func (s *service) WriteOff(ctx context.Context, productID, quantity int) ([]domain.LotAllocation, error) {
+	ctx, span := tracer.Start(ctx, "lot.Service.WriteOff")
+	defer span.End()
+
+	if quantity <= 0 {
+		return nil, ErrInvalidQuantity
+	}
+	allocations, before, err := s.repo.WriteOff(ctx, productID, quantity)
+	if err != nil {
+		return nil, err
+	}
+	s.stock.QuantityChanged(ctx, productID, before)
+	return allocations, nil
+}
+
+func (s *service) Expiring(ctx context.Context, within time.Duration, warehouseID *int) ([]domain.ExpiringLot, error) {
+	ctx, span := tracer.Start(ctx, "lot.Service.Expiring")
+	defer span.End()
+
+	lots, err := s.repo.Expiring(ctx, time.Now().Add(within), warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	if lots == nil {
+		return []domain.ExpiringLot{}, nil
+	}
+	return lots, nil
+}
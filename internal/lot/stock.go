@@ -0,0 +1,110 @@
+package lot
+
+import (
+	"context"
+	"time"
+
+	"repository_class/internal/domain"
+	"repository_class/pkg/txn"
+)
+
+// The functions in this file change stock inside a transaction owned by the
+// caller, so other repositories can issue stock atomically with their own
+// writes. Callers lock the product row first.
+
+// AvailableQuery is the query Available runs. It subtracts the units held by
+// active, unexpired reservations from the units in unexpired lots.
+const AvailableQuery = "SELECT (SELECT COALESCE(SUM(quantity), 0) FROM lots WHERE product_id=? AND expiration>?) - " +
+	"(SELECT COALESCE(SUM(quantity), 0) FROM reservations WHERE product_id=? AND status='active' AND expires_at>?);"
+
+// Available returns how many units of the product can be taken at now
+// without touching the stock its reservations hold, whether or not the
+// worker has marked the expired ones yet. Stock can only be held in lots
+// that have not expired.
+func Available(ctx context.Context, tx txn.Executor, productID int, now time.Time) (int, error) {
+	var available int
+	err := tx.QueryRowContext(ctx, AvailableQuery, productID, now, productID, now).Scan(&available)
+	return available, err
+}
+
+// Issue takes quantity units of the product from its lots that are not
+// expired at now, first-expired-first-out. It returns ErrInsufficientStock,
+// changing nothing, when those lots hold less than quantity.
+func Issue(ctx context.Context, tx txn.Executor, productID, quantity int, now time.Time) ([]domain.LotAllocation, error) {
+	return allocate(ctx, tx, productID, quantity, &now)
+}
+
+// WriteOff removes quantity units of the product from its lots, expired ones
+// included, earliest expiration first. It is used for stock corrections.
+func WriteOff(ctx context.Context, tx txn.Executor, productID, quantity int) ([]domain.LotAllocation, error) {
+	return allocate(ctx, tx, productID, quantity, nil)
+}
+
+func allocate(ctx context.Context, tx txn.Executor, productID, quantity int, usableAt *time.Time) ([]domain.LotAllocation, error) {
+	query := "SELECT id, lot_code, quantity, expiration FROM lots WHERE product_id=? AND quantity>0"
+	args := []interface{}{productID}
+	if usableAt != nil {
+		query += " AND expiration>?"
+		args = append(args, *usableAt)
+	}
+	query += " ORDER BY expiration, received_at, id FOR UPDATE;"
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		allocations []domain.LotAllocation
+		remaining   = quantity
+	)
+	for remaining > 0 && rows.Next() {
+		var (
+			a         domain.LotAllocation
+			available int
+		)
+		if err := rows.Scan(&a.LotID, &a.LotCode, &available, &a.Expiration); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		a.Quantity = min(available, remaining)
+		remaining -= a.Quantity
+		allocations = append(allocations, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if remaining > 0 {
+		return nil, ErrInsufficientStock
+	}
+
+	for _, a := range allocations {
+		if _, err := tx.ExecContext(ctx, "UPDATE lots SET quantity=quantity-? WHERE id=?;", a.Quantity, a.LotID); err != nil {
+			return nil, err
+		}
+		if err := record(ctx, tx, productID, a.LotID, -a.Quantity); err != nil {
+			return nil, err
+		}
+	}
+
+	return allocations, Sync(ctx, tx, productID)
+}
+
+// record appends a movement of quantity units of a lot, negative when they
+// leave it, to the ledger stock is valued at cost from.
+func record(ctx context.Context, tx txn.Executor, productID, lotID, quantity int) error {
+	_, err := tx.ExecContext(ctx, "INSERT INTO stock_movements (product_id, lot_id, quantity) VALUES (?, ?, ?);", productID, lotID, quantity)
+	return err
+}
+
+// Sync stores on the product row the quantity and expiration derived from
+// its lots. A product whose lots are all empty keeps its last expiration.
+func Sync(ctx context.Context, tx txn.Executor, productID int) error {
+	query := "UPDATE products SET " +
+		"quantity=(SELECT COALESCE(SUM(l.quantity), 0) FROM lots l WHERE l.product_id=?), " +
+		"expiration=COALESCE((SELECT MIN(l.expiration) FROM lots l WHERE l.product_id=? AND l.quantity>0), expiration) " +
+		"WHERE id=?;"
+	_, err := tx.ExecContext(ctx, query, productID, productID, productID)
+	return err
+}
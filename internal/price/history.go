@@ -0,0 +1,19 @@
+package price
+
+import (
+	"context"
+	"time"
+
+	"repository_class/pkg/txn"
+
+	"github.com/shopspring/decimal"
+)
+
+// Record appends a price the product takes effect at to its history, inside
+// a transaction owned by the caller. The product repository calls it for
+// every price it writes, so the history cannot miss a change.
+func Record(ctx context.Context, tx txn.Executor, productID int, price decimal.Decimal, currency string, at time.Time) error {
+	_, err := tx.ExecContext(ctx, "INSERT INTO product_prices (product_id, price, currency, effective_at, applied) VALUES (?, ?, ?, ?, TRUE);",
+		productID, price, currency, at)
+	return err
+}
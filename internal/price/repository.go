@@ -0,0 +1,355 @@
+package price
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"repository_class/internal/domain"
+	"repository_class/pkg/instrument"
+	"repository_class/pkg/tenant"
+
+	"github.com/shopspring/decimal"
+)
+
+// Repository encapsulates the price history of products. The price of a
+// product at a date is the latest change effective at or before it. Every
+// method but DueTenants only sees the prices of the products of the tenant in
+// its context and fails with tenant.ErrMissing when there is none.
+type Repository interface {
+	Get(ctx context.Context, id int) (domain.PriceChange, error)
+	// History returns the changes of the product by effective date,
+	// scheduled ones included.
+	History(ctx context.Context, productID int) ([]domain.PriceChange, error)
+	// Schedule stores a change, in the currency of the product when it has
+	// none. One effective at or before now is applied to the product right
+	// away.
+	Schedule(ctx context.Context, c domain.PriceChange, now time.Time) (int, error)
+	// Cancel deletes a change that has not been applied yet.
+	Cancel(ctx context.Context, id int) error
+	// ApplyDue applies the scheduled changes effective at or before now and
+	// returns how many it applied.
+	ApplyDue(ctx context.Context, now time.Time) (int64, error)
+	// DueProducts returns the products with changes effective at or before
+	// now that are not applied yet.
+	DueProducts(ctx context.Context, now time.Time) ([]int, error)
+	// DueTenants returns the tenants with changes effective at or before now
+	// that are not applied yet, across every tenant.
+	DueTenants(ctx context.Context, now time.Time) ([]string, error)
+	// Valuation prices the products of the warehouse at the date, each in
+	// the currency of its price then; products without a price by then are
+	// left out.
+	Valuation(ctx context.Context, warehouseID int, at time.Time) ([]domain.ProductValuation, error)
+}
+
+const repositoryName = "price"
+
+const priceColumns = "h.id, h.product_id, h.price, h.currency, h.effective_at, h.applied, h.created_at"
+
+// pricesOfTenant selects the changes h of the products p of a tenant, bound
+// first.
+const pricesOfTenant = " FROM product_prices h INNER JOIN products p ON p.id = h.product_id WHERE p.tenant_id=?"
+
+// priceAt selects the id of the change of product p in effect at the bound
+// date.
+const priceAt = "SELECT h.id FROM product_prices h WHERE h.product_id = p.id AND h.effective_at<=? " +
+	"ORDER BY h.effective_at DESC, h.id DESC LIMIT 1"
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPrice(s scanner) (domain.PriceChange, error) {
+	c := domain.PriceChange{}
+	err := s.Scan(&c.ID, &c.ProductID, &c.Price, &c.Currency, &c.EffectiveAt, &c.Applied, &c.CreatedAt)
+	return c, err
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) Get(ctx context.Context, id int) (c domain.PriceChange, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return domain.PriceChange{}, err
+	}
+	query := "SELECT " + priceColumns + pricesOfTenant + " AND h.id=?;"
+	ctx, done := instrument.Query(ctx, repositoryName, "Get", query)
+	defer func() { done(err) }()
+
+	c, err = scanPrice(r.db.QueryRowContext(ctx, query, tenantID, id))
+	if err != nil {
+		return domain.PriceChange{}, err
+	}
+
+	return c, nil
+}
+
+func (r *repository) History(ctx context.Context, productID int) (changes []domain.PriceChange, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := "SELECT " + priceColumns + pricesOfTenant + " AND h.product_id=? ORDER BY h.effective_at, h.id;"
+	ctx, done := instrument.Query(ctx, repositoryName, "History", query)
+	defer func() { done(err) }()
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		c, err := scanPrice(rows)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+
+	return changes, rows.Err()
+}
+
+func (r *repository) Schedule(ctx context.Context, c domain.PriceChange, now time.Time) (_ int, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return 0, err
+	}
+	lock := "SELECT currency FROM products WHERE id=? AND tenant_id=? FOR UPDATE;"
+	insert := "INSERT INTO product_prices (product_id, price, currency, effective_at, applied) VALUES (?, ?, ?, ?, ?);"
+	ctx, done := instrument.Query(ctx, repositoryName, "Schedule", lock+" "+insert+" "+latestQuery+" "+applyQuery)
+	defer func() { done(err) }()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var currency string
+	if err = tx.QueryRowContext(ctx, lock, c.ProductID, tenantID).Scan(&currency); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrProductNotFound
+		}
+		return 0, err
+	}
+	if c.Currency != "" {
+		currency = c.Currency
+	}
+
+	due := !c.EffectiveAt.After(now)
+	res, err := tx.ExecContext(ctx, insert, c.ProductID, c.Price, currency, c.EffectiveAt, due)
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	if due {
+		if err = r.sync(ctx, tx, tenantID, c.ProductID, now); err != nil {
+			return 0, err
+		}
+	}
+
+	return int(id), tx.Commit()
+}
+
+func (r *repository) Cancel(ctx context.Context, id int) (err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return err
+	}
+	query := "DELETE h FROM product_prices h INNER JOIN products p ON p.id = h.product_id " +
+		"WHERE h.id=? AND h.applied=FALSE AND p.tenant_id=?;"
+	ctx, done := instrument.Query(ctx, repositoryName, "Cancel", query)
+	defer func() { done(err) }()
+
+	res, err := r.db.ExecContext(ctx, query, id, tenantID)
+	if err != nil {
+		return err
+	}
+
+	affect, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affect < 1 {
+		return ErrApplied
+	}
+
+	return nil
+}
+
+func (r *repository) ApplyDue(ctx context.Context, now time.Time) (_ int64, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return 0, err
+	}
+	due := "SELECT DISTINCT h.product_id" + pricesOfTenant + " AND h.applied=FALSE AND h.effective_at<=?;"
+	lock := "SELECT id FROM products WHERE id IN (...) AND tenant_id=? FOR UPDATE;"
+	mark := "UPDATE product_prices SET applied=TRUE WHERE applied=FALSE AND effective_at<=? AND product_id IN (...);"
+	ctx, done := instrument.Query(ctx, repositoryName, "ApplyDue", due+" "+lock+" "+latestQuery+" "+applyQuery+" "+mark)
+	defer func() { done(err) }()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, due, tenantID, now)
+	if err != nil {
+		return 0, err
+	}
+	var args []interface{}
+	for rows.Next() {
+		var productID int
+		if err = rows.Scan(&productID); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		args = append(args, productID)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(args) == 0 {
+		return 0, nil
+	}
+
+	// Products are locked before their prices are touched, in the same
+	// order as a product update, so the two cannot deadlock.
+	in := strings.TrimSuffix(strings.Repeat("?,", len(args)), ",")
+	lockRows, err := tx.QueryContext(ctx, strings.Replace(lock, "...", in, 1), append(args, tenantID)...)
+	if err != nil {
+		return 0, err
+	}
+	lockRows.Close()
+
+	for _, productID := range args {
+		if err = r.sync(ctx, tx, tenantID, productID.(int), now); err != nil {
+			return 0, err
+		}
+	}
+	res, err := tx.ExecContext(ctx, strings.Replace(mark, "...", in, 1), append([]interface{}{now}, args...)...)
+	if err != nil {
+		return 0, err
+	}
+	applied, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return applied, tx.Commit()
+}
+
+func (r *repository) DueProducts(ctx context.Context, now time.Time) (products []int, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := "SELECT DISTINCT h.product_id" + pricesOfTenant + " AND h.applied=FALSE AND h.effective_at<=? ORDER BY h.product_id;"
+	ctx, done := instrument.Query(ctx, repositoryName, "DueProducts", query)
+	defer func() { done(err) }()
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var productID int
+		if err := rows.Scan(&productID); err != nil {
+			return nil, err
+		}
+		products = append(products, productID)
+	}
+
+	return products, rows.Err()
+}
+
+// DueTenants is the one query of the repository that reads across tenants:
+// the price scheduler uses it to know whose changes to apply.
+func (r *repository) DueTenants(ctx context.Context, now time.Time) (tenants []string, err error) {
+	query := "SELECT DISTINCT p.tenant_id FROM product_prices h INNER JOIN products p ON p.id = h.product_id " +
+		"WHERE h.applied=FALSE AND h.effective_at<=? ORDER BY p.tenant_id;"
+	ctx, done := instrument.Query(ctx, repositoryName, "DueTenants", query)
+	defer func() { done(err) }()
+
+	rows, err := r.db.QueryContext(ctx, query, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tenantID string
+		if err := rows.Scan(&tenantID); err != nil {
+			return nil, err
+		}
+		tenants = append(tenants, tenantID)
+	}
+
+	return tenants, rows.Err()
+}
+
+const (
+	latestQuery = "SELECT price, currency FROM product_prices WHERE product_id=? AND effective_at<=? ORDER BY effective_at DESC, id DESC LIMIT 1;"
+	applyQuery  = "UPDATE products SET price=?, currency=? WHERE id=? AND tenant_id=?;"
+)
+
+// sync sets the price of a product to the latest change effective at now,
+// so a change applied late never overrides a newer one.
+func (r *repository) sync(ctx context.Context, tx *sql.Tx, tenantID string, productID int, now time.Time) error {
+	var (
+		price    decimal.Decimal
+		currency string
+	)
+	if err := tx.QueryRowContext(ctx, latestQuery, productID, now).Scan(&price, &currency); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, applyQuery, price, currency, productID, tenantID)
+	return err
+}
+
+func (r *repository) Valuation(ctx context.Context, warehouseID int, at time.Time) (products []domain.ProductValuation, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := "SELECT p.id, p.name, p.code_value, p.quantity, pp.price, pp.currency FROM products p " +
+		"INNER JOIN product_prices pp ON pp.id = (" + priceAt + ") " +
+		"WHERE p.id_warehouse=? AND p.tenant_id=? ORDER BY p.id;"
+	ctx, done := instrument.Query(ctx, repositoryName, "Valuation", query)
+	defer func() { done(err) }()
+
+	rows, err := r.db.QueryContext(ctx, query, at, warehouseID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		v := domain.ProductValuation{}
+		if err := rows.Scan(&v.ProductID, &v.Name, &v.CodeValue, &v.Quantity, &v.Price, &v.Currency); err != nil {
+			return nil, err
+		}
+		products = append(products, v)
+	}
+
+	return products, rows.Err()
+}
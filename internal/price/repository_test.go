@@ -0,0 +1,134 @@
+package price
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"repository_class/internal/domain"
+	"repository_class/internal/testdb"
+	"repository_class/pkg/tenant"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedule(t *testing.T) {
+	db := testdb.Open(t)
+	rp := NewRepository(db)
+	ctx := tenant.WithContext(context.Background(), "acme")
+	warehouseID := testdb.Warehouse(t, db, "acme", 0)
+	productID := testdb.Product(t, db, "acme", warehouseID, "W-1")
+	now := time.Now().Truncate(time.Second)
+
+	price := func() decimal.Decimal {
+		var p decimal.Decimal
+		assert.NoError(t, db.QueryRow("SELECT price FROM products WHERE id=?;", productID).Scan(&p))
+		return p
+	}
+
+	// A change already effective is applied right away, a later one waits.
+	current, err := rp.Schedule(ctx, domain.PriceChange{ProductID: productID, Price: decimal.NewFromInt(10), EffectiveAt: now.Add(-time.Hour)}, now)
+	assert.NoError(t, err)
+	next, err := rp.Schedule(ctx, domain.PriceChange{ProductID: productID, Price: decimal.NewFromInt(12), EffectiveAt: now.Add(time.Hour)}, now)
+	assert.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(10).Equal(price()))
+
+	history, err := rp.History(ctx, productID)
+	assert.NoError(t, err)
+	if assert.Len(t, history, 2) {
+		assert.Equal(t, current, history[0].ID)
+		assert.True(t, history[0].Applied)
+		assert.Equal(t, next, history[1].ID)
+		assert.False(t, history[1].Applied)
+	}
+
+	// Valuation reads the price in effect at the date, applied or not.
+	valuation, err := rp.Valuation(ctx, warehouseID, now)
+	assert.NoError(t, err)
+	if assert.Len(t, valuation, 1) {
+		assert.True(t, decimal.NewFromInt(10).Equal(valuation[0].Price))
+	}
+	valuation, err = rp.Valuation(ctx, warehouseID, now.Add(2*time.Hour))
+	assert.NoError(t, err)
+	if assert.Len(t, valuation, 1) {
+		assert.True(t, decimal.NewFromInt(12).Equal(valuation[0].Price))
+	}
+	valuation, err = rp.Valuation(ctx, warehouseID, now.Add(-2*time.Hour))
+	assert.NoError(t, err)
+	assert.Empty(t, valuation)
+
+	products, err := rp.DueProducts(ctx, now.Add(2*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, []int{productID}, products)
+	n, err := rp.ApplyDue(ctx, now.Add(2*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	assert.True(t, decimal.NewFromInt(12).Equal(price()))
+
+	// Applied changes are history and cannot be cancelled.
+	assert.ErrorIs(t, rp.Cancel(ctx, next), ErrApplied)
+}
+
+func TestCancel(t *testing.T) {
+	db := testdb.Open(t)
+	rp := NewRepository(db)
+	ctx := tenant.WithContext(context.Background(), "acme")
+	productID := testdb.Product(t, db, "acme", testdb.Warehouse(t, db, "acme", 0), "W-1")
+	now := time.Now().Truncate(time.Second)
+
+	id, err := rp.Schedule(ctx, domain.PriceChange{ProductID: productID, Price: decimal.NewFromInt(12), EffectiveAt: now.Add(time.Hour)}, now)
+	assert.NoError(t, err)
+	assert.NoError(t, rp.Cancel(ctx, id))
+
+	_, err = rp.Get(ctx, id)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	n, err := rp.ApplyDue(ctx, now.Add(2*time.Hour))
+	assert.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func TestTenantIsolation(t *testing.T) {
+	db := testdb.Open(t)
+	rp := NewRepository(db)
+	acme := tenant.WithContext(context.Background(), "acme")
+	globex := tenant.WithContext(context.Background(), "globex")
+	warehouseID := testdb.Warehouse(t, db, "acme", 0)
+	productID := testdb.Product(t, db, "acme", warehouseID, "W-1")
+	now := time.Now()
+
+	first, err := rp.Schedule(acme, domain.PriceChange{ProductID: productID, Price: decimal.NewFromInt(10), EffectiveAt: now}, now)
+	assert.NoError(t, err)
+	id, err := rp.Schedule(acme, domain.PriceChange{ProductID: productID, Price: decimal.NewFromInt(12), EffectiveAt: now.Add(time.Hour)}, now)
+	assert.NoError(t, err)
+
+	_, err = rp.Get(globex, first)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	history, err := rp.History(globex, productID)
+	assert.NoError(t, err)
+	assert.Empty(t, history)
+	valuation, err := rp.Valuation(globex, warehouseID, now)
+	assert.NoError(t, err)
+	assert.Empty(t, valuation)
+
+	_, err = rp.Schedule(globex, domain.PriceChange{ProductID: productID, Price: decimal.NewFromInt(1), EffectiveAt: now}, now)
+	assert.ErrorIs(t, err, ErrProductNotFound)
+	assert.ErrorIs(t, rp.Cancel(globex, id), ErrApplied)
+
+	// The change of acme is due, but only acme applies it.
+	later := now.Add(2 * time.Hour)
+	tenants, err := rp.DueTenants(context.Background(), later)
+	assert.NoError(t, err)
+	assert.Contains(t, tenants, "acme")
+	n, err := rp.ApplyDue(globex, later)
+	assert.NoError(t, err)
+	assert.Zero(t, n)
+	n, err = rp.ApplyDue(acme, later)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	c, err := rp.Get(acme, id)
+	assert.NoError(t, err)
+	assert.True(t, c.Applied)
+}
@@ -0,0 +1,179 @@
+package price
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"repository_class/internal/domain"
+	"repository_class/internal/exchange"
+	"repository_class/internal/warehouse"
+	"repository_class/pkg/tenant"
+
+	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel"
+)
+
+// Errors
+var (
+	ErrNotFound          = errors.New("price change not found")
+	ErrProductNotFound   = errors.New("product not found")
+	ErrWarehouseNotFound = errors.New("warehouse not found")
+	ErrInvalidPrice      = errors.New("price must not be negative")
+	ErrApplied           = errors.New("price change was already applied")
+)
+
+type Service interface {
+	// History returns the price timeline of a product, oldest first. Changes
+	// scheduled for the future are included with Applied false.
+	History(ctx context.Context, productID int) ([]domain.PriceChange, error)
+	// Schedule changes the price of a product at c.EffectiveAt, now when it
+	// is zero. A c.Currency also moves the product to that currency.
+	Schedule(ctx context.Context, c domain.PriceChange) (domain.PriceChange, error)
+	// Cancel drops a scheduled change before it is applied.
+	Cancel(ctx context.Context, id int) error
+	// ApplyDue applies the scheduled changes of every tenant whose date has
+	// come and returns how many it applied.
+	ApplyDue(ctx context.Context) (int64, error)
+	// Valuation values the stock of a warehouse at the prices in effect at
+	// the given date, converted into currency with the current rates. An
+	// empty currency values it in the base currency.
+	Valuation(ctx context.Context, warehouseID int, at time.Time, currency string) (domain.StockValuation, error)
+}
+
+var tracer = otel.Tracer("repository_class/internal/price")
+
+type service struct {
+	repo       Repository
+	warehouses warehouse.Repository
+	rates      exchange.Service
+}
+
+func NewService(repo *Repository, warehouses warehouse.Repository, rates exchange.Service) Service {
+	return &service{repo: *repo, warehouses: warehouses, rates: rates}
+}
+
+func (s *service) History(ctx context.Context, productID int) ([]domain.PriceChange, error) {
+	ctx, span := tracer.Start(ctx, "price.Service.History")
+	defer span.End()
+
+	changes, err := s.repo.History(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	// Every product gets a first entry when it is created, so an empty
+	// history means there is no such product.
+	if len(changes) == 0 {
+		return nil, ErrProductNotFound
+	}
+	return changes, nil
+}
+
+func (s *service) Schedule(ctx context.Context, c domain.PriceChange) (domain.PriceChange, error) {
+	ctx, span := tracer.Start(ctx, "price.Service.Schedule")
+	defer span.End()
+
+	if c.Price.IsNegative() {
+		return domain.PriceChange{}, ErrInvalidPrice
+	}
+	if c.Currency != "" {
+		code, err := exchange.ParseCurrency(c.Currency)
+		if err != nil {
+			return domain.PriceChange{}, err
+		}
+		c.Currency = code
+	}
+	now := time.Now()
+	if c.EffectiveAt.IsZero() {
+		c.EffectiveAt = now
+	}
+
+	id, err := s.repo.Schedule(ctx, c, now)
+	if err != nil {
+		return domain.PriceChange{}, err
+	}
+	return s.repo.Get(ctx, id)
+}
+
+func (s *service) Cancel(ctx context.Context, id int) error {
+	ctx, span := tracer.Start(ctx, "price.Service.Cancel")
+	defer span.End()
+
+	if _, err := s.repo.Get(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return s.repo.Cancel(ctx, id)
+}
+
+func (s *service) ApplyDue(ctx context.Context) (int64, error) {
+	ctx, span := tracer.Start(ctx, "price.Service.ApplyDue")
+	defer span.End()
+
+	now := time.Now()
+	tenants, err := s.repo.DueTenants(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+	// Each tenant is applied on its own, so the changes of one never wait
+	// on the locks of another.
+	var applied int64
+	for _, tenantID := range tenants {
+		n, err := s.repo.ApplyDue(tenant.WithContext(ctx, tenantID), now)
+		applied += n
+		if err != nil {
+			return applied, err
+		}
+	}
+	return applied, nil
+}
+
+func (s *service) Valuation(ctx context.Context, warehouseID int, at time.Time, currency string) (domain.StockValuation, error) {
+	ctx, span := tracer.Start(ctx, "price.Service.Valuation")
+	defer span.End()
+
+	w, err := s.warehouses.Get(ctx, warehouseID)
+	if err != nil {
+		return domain.StockValuation{}, err
+	}
+	if w.ID == 0 {
+		return domain.StockValuation{}, ErrWarehouseNotFound
+	}
+
+	target := s.rates.Base()
+	if currency != "" {
+		if target, err = exchange.ParseCurrency(currency); err != nil {
+			return domain.StockValuation{}, err
+		}
+	}
+	rates, err := s.rates.Rates(ctx)
+	if err != nil {
+		return domain.StockValuation{}, err
+	}
+
+	products, err := s.repo.Valuation(ctx, warehouseID, at)
+	if err != nil {
+		return domain.StockValuation{}, err
+	}
+
+	v := domain.StockValuation{WarehouseID: warehouseID, At: at, Currency: target, Products: []domain.ProductValuation{}}
+	for _, p := range products {
+		// The value is converted as a whole rather than rebuilt from the
+		// rounded unit price.
+		value := p.Price.Mul(decimal.NewFromInt(int64(p.Quantity)))
+		if p.Value, err = rates.Convert(value, p.Currency, target); err != nil {
+			return domain.StockValuation{}, err
+		}
+		if p.Price, err = rates.Convert(p.Price, p.Currency, target); err != nil {
+			return domain.StockValuation{}, err
+		}
+		p.Currency = target
+		v.Units += p.Quantity
+		v.Value = v.Value.Add(p.Value)
+		v.Products = append(v.Products, p)
+	}
+	return v, nil
+}
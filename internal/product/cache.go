@@ -0,0 +1,191 @@
+package product
+
+import (
+	"context"
+	"time"
+
+	"repository_class/internal/domain"
+	"repository_class/internal/lot"
+	"repository_class/internal/price"
+	"repository_class/internal/reservation"
+	"repository_class/internal/warehouse"
+	"repository_class/pkg/cache"
+	"repository_class/pkg/tenant"
+	"repository_class/pkg/txn"
+)
+
+// Key returns the cache key of product id of a tenant.
+func Key(tenantID string, id int) string {
+	return cache.Key("product", tenantID, id)
+}
+
+type cachedRepository struct {
+	Repository
+	cache *cache.Cache
+}
+
+// NewCachedRepository wraps repo so Get is served from c. Save, Update and
+// Delete drop the product and the product report of the warehouses it
+// leaves and joins. The other methods go straight to repo.
+//
+// Lots, reservations and scheduled prices change the quantity and price of
+// a product without going through repo; wrap their repositories with
+// NewCachedLots, NewCachedReservations and NewCachedPrices over the same c.
+// Reads a write is based on should use cache.Bypass.
+func NewCachedRepository(repo Repository, c *cache.Cache) Repository {
+	return &cachedRepository{Repository: repo, cache: c}
+}
+
+func (r *cachedRepository) Get(ctx context.Context, id int) (domain.Product, error) {
+	// A transaction may read its own writes, which must not be cached
+	// before it commits.
+	if txn.Active(ctx) {
+		return r.Repository.Get(ctx, id)
+	}
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return domain.Product{}, err
+	}
+	key := Key(tenantID, id)
+
+	var p domain.Product
+	if r.cache.Get(ctx, key, &p) {
+		return p, nil
+	}
+	p, err = r.Repository.Get(ctx, id)
+	if err != nil {
+		return p, err
+	}
+	r.cache.Set(ctx, key, p)
+	return p, nil
+}
+
+func (r *cachedRepository) Save(ctx context.Context, p domain.Product) (int, error) {
+	id, err := r.Repository.Save(ctx, p)
+	if tenantID, ok := tenant.FromContext(ctx); ok {
+		r.invalidate(ctx, warehouse.ReportKey(tenantID, p.IdWarehouse))
+	}
+	return id, err
+}
+
+func (r *cachedRepository) Update(ctx context.Context, p domain.Product) error {
+	keys := r.keys(ctx, p.ID)
+	err := r.Repository.Update(ctx, p)
+	if tenantID, ok := tenant.FromContext(ctx); ok {
+		keys = append(keys, warehouse.ReportKey(tenantID, p.IdWarehouse))
+	}
+	r.invalidate(ctx, keys...)
+	return err
+}
+
+func (r *cachedRepository) Delete(ctx context.Context, id int) error {
+	keys := r.keys(ctx, id)
+	err := r.Repository.Delete(ctx, id)
+	r.invalidate(ctx, keys...)
+	return err
+}
+
+// invalidate drops keys once the write is visible to other readers, so none
+// of them caches the rows from before it.
+func (r *cachedRepository) invalidate(ctx context.Context, keys ...string) {
+	txn.AfterCommit(ctx, func() { r.cache.Delete(ctx, keys...) })
+}
+
+// keys returns the entries a write to product id drops: the product and the
+// report of the warehouse it is stored in before the write. They are
+// dropped whether the write succeeded or not, since a failed write may
+// still have reached the database.
+func (r *cachedRepository) keys(ctx context.Context, id int) []string {
+	tenantID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	keys := []string{Key(tenantID, id)}
+	if p, err := r.Repository.Get(ctx, id); err == nil {
+		keys = append(keys, warehouse.ReportKey(tenantID, p.IdWarehouse))
+	}
+	return keys
+}
+
+type cachedLots struct {
+	lot.Repository
+	products *cachedRepository
+}
+
+// NewCachedLots wraps lots so Receive, Issue and WriteOff drop the product
+// they change from c, as the writes of repo do.
+func NewCachedLots(lots lot.Repository, repo Repository, c *cache.Cache) lot.Repository {
+	return &cachedLots{Repository: lots, products: &cachedRepository{Repository: repo, cache: c}}
+}
+
+func (r *cachedLots) Receive(ctx context.Context, l domain.Lot) (int, int, error) {
+	keys := r.products.keys(ctx, l.ProductID)
+	id, before, err := r.Repository.Receive(ctx, l)
+	r.products.invalidate(ctx, keys...)
+	return id, before, err
+}
+
+func (r *cachedLots) Issue(ctx context.Context, productID, quantity int, now time.Time) ([]domain.LotAllocation, int, error) {
+	keys := r.products.keys(ctx, productID)
+	allocations, before, err := r.Repository.Issue(ctx, productID, quantity, now)
+	r.products.invalidate(ctx, keys...)
+	return allocations, before, err
+}
+
+func (r *cachedLots) WriteOff(ctx context.Context, productID, quantity int) ([]domain.LotAllocation, int, error) {
+	keys := r.products.keys(ctx, productID)
+	allocations, before, err := r.Repository.WriteOff(ctx, productID, quantity)
+	r.products.invalidate(ctx, keys...)
+	return allocations, before, err
+}
+
+type cachedReservations struct {
+	reservation.Repository
+	products *cachedRepository
+}
+
+// NewCachedReservations wraps reservations so Confirm drops the product
+// whose stock it issues from c.
+func NewCachedReservations(reservations reservation.Repository, repo Repository, c *cache.Cache) reservation.Repository {
+	return &cachedReservations{Repository: reservations, products: &cachedRepository{Repository: repo, cache: c}}
+}
+
+func (r *cachedReservations) Confirm(ctx context.Context, id int) (int, error) {
+	var keys []string
+	if res, err := r.Repository.Get(ctx, id); err == nil {
+		keys = r.products.keys(ctx, res.ProductID)
+	}
+	before, err := r.Repository.Confirm(ctx, id)
+	r.products.invalidate(ctx, keys...)
+	return before, err
+}
+
+type cachedPrices struct {
+	price.Repository
+	products *cachedRepository
+}
+
+// NewCachedPrices wraps prices so Schedule and ApplyDue drop the products
+// whose price they change from c.
+func NewCachedPrices(prices price.Repository, repo Repository, c *cache.Cache) price.Repository {
+	return &cachedPrices{Repository: prices, products: &cachedRepository{Repository: repo, cache: c}}
+}
+
+func (r *cachedPrices) Schedule(ctx context.Context, c domain.PriceChange, now time.Time) (int, error) {
+	keys := r.products.keys(ctx, c.ProductID)
+	id, err := r.Repository.Schedule(ctx, c, now)
+	r.products.invalidate(ctx, keys...)
+	return id, err
+}
+
+func (r *cachedPrices) ApplyDue(ctx context.Context, now time.Time) (int64, error) {
+	var keys []string
+	if due, err := r.Repository.DueProducts(ctx, now); err == nil {
+		for _, productID := range due {
+			keys = append(keys, r.products.keys(ctx, productID)...)
+		}
+	}
+	applied, err := r.Repository.ApplyDue(ctx, now)
+	r.products.invalidate(ctx, keys...)
+	return applied, err
+}
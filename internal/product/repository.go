@@ -3,20 +3,55 @@ package product
 import (
 	"context"
 	"database/sql"
-	"log"
+	"strings"
+	"time"
 
 	"repository_class/internal/domain"
+	"repository_class/internal/price"
+	"repository_class/pkg/instrument"
+	"repository_class/pkg/tenant"
+	"repository_class/pkg/txn"
+
+	"github.com/shopspring/decimal"
 )
 
-// Repository encapsulates the storage of a Product.
+// Repository encapsulates the storage of a Product. Every method but
+// Documents only sees the products of the tenant in its context and fails
+// with tenant.ErrMissing when there is none, and joins the transaction of a
+// txn.Run in its context.
 type Repository interface {
 	GetAll(ctx context.Context) ([]domain.Product, error)
+	Find(ctx context.Context, f domain.ProductFilter) ([]domain.Product, error)
 	Get(ctx context.Context, id int) (domain.Product, error)
+	GetByCode(ctx context.Context, codeValue string) (domain.Product, error)
 	GetWithWarehouse(ctx context.Context, id int) (domain.ProductWithWarehouse, error)
-	Exists(ctx context.Context, productCode string) bool
+	// Exists reports whether a product of the tenant has codeValue.
+	Exists(ctx context.Context, codeValue string) (bool, error)
+	// WarehouseExists reports whether id names a warehouse of the tenant.
+	WarehouseExists(ctx context.Context, id int) bool
+	// Save and Update fail with ErrUniqueProduct when another product of
+	// the tenant has the code of p, even one saved concurrently.
 	Save(ctx context.Context, p domain.Product) (int, error)
 	Update(ctx context.Context, p domain.Product) error
 	Delete(ctx context.Context, id int) error
+	// Documents returns the searchable fields of the products of every
+	// tenant, to rebuild the search index.
+	Documents(ctx context.Context) ([]domain.ProductDocument, error)
+}
+
+const repositoryName = "product"
+
+const productColumns = "id, name, quantity, code_value, is_published, expiration, price, currency, id_warehouse, category_id"
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanProduct reads a row selected with productColumns.
+func scanProduct(s scanner) (domain.Product, error) {
+	p := domain.Product{}
+	err := s.Scan(&p.ID, &p.Name, &p.Quantity, &p.CodeValue, &p.IsPublished, &p.Expiration, &p.Price, &p.Currency, &p.IdWarehouse, &p.CategoryID)
+	return p, err
 }
 
 type repository struct {
@@ -29,29 +64,98 @@ func NewRepository(db *sql.DB) Repository {
 	}
 }
 
-func (r *repository) GetAll(ctx context.Context) ([]domain.Product, error) {
-	query := "SELECT * FROM products;"
-	rows, err := r.db.Query(query)
+func (r *repository) GetAll(ctx context.Context) (products []domain.Product, err error) {
+	tenantID, err := tenant.Require(ctx)
 	if err != nil {
 		return nil, err
 	}
+	query := "SELECT " + productColumns + " FROM products WHERE tenant_id=?;"
+	ctx, done := instrument.Query(ctx, repositoryName, "GetAll", query)
+	defer func() { done(err) }()
 
-	var products []domain.Product
+	rows, err := txn.From(ctx, r.db).QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
 	for rows.Next() {
-		p := domain.Product{}
-		_ = rows.Scan(&p.ID, &p.Name, &p.Quantity, &p.CodeValue, &p.IsPublished, &p.Expiration, &p.Price)
+		p, err := scanProduct(rows)
+		if err != nil {
+			return nil, err
+		}
 		products = append(products, p)
 	}
 
-	return products, nil
+	return products, rows.Err()
 }
 
-func (r *repository) Get(ctx context.Context, id int) (domain.Product, error) {
-	query := "SELECT * FROM products WHERE id=?;"
-	row := r.db.QueryRow(query, id)
-	p := domain.Product{}
-	err := row.Scan(&p.ID, &p.Name, &p.Quantity, &p.CodeValue, &p.IsPublished, &p.Expiration, &p.Price)
+func (r *repository) Find(ctx context.Context, f domain.ProductFilter) (products []domain.Product, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	where := []string{"tenant_id=?"}
+	args := []interface{}{tenantID}
+	if len(f.IDs) > 0 {
+		where = append(where, "id IN ("+placeholders(len(f.IDs))+")")
+		for _, id := range f.IDs {
+			args = append(args, id)
+		}
+	}
+	if len(f.WarehouseIDs) > 0 {
+		where = append(where, "id_warehouse IN ("+placeholders(len(f.WarehouseIDs))+")")
+		for _, id := range f.WarehouseIDs {
+			args = append(args, id)
+		}
+	}
+	if len(f.CategoryIDs) > 0 {
+		where = append(where, "category_id IN ("+placeholders(len(f.CategoryIDs))+")")
+		for _, id := range f.CategoryIDs {
+			args = append(args, id)
+		}
+	}
+	if f.Published != nil {
+		where = append(where, "is_published=?")
+		args = append(args, *f.Published)
+	}
+
+	query := "SELECT " + productColumns + " FROM products WHERE " + strings.Join(where, " AND ") + " ORDER BY id"
+	if f.Limit > 0 {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, f.Limit, f.Offset)
+	}
+
+	ctx, done := instrument.Query(ctx, repositoryName, "Find", query)
+	defer func() { done(err) }()
+
+	rows, err := txn.From(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, p)
+	}
+
+	return products, rows.Err()
+}
+
+func (r *repository) Get(ctx context.Context, id int) (p domain.Product, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return domain.Product{}, err
+	}
+	query := "SELECT " + productColumns + " FROM products WHERE id=? AND tenant_id=?;"
+	ctx, done := instrument.Query(ctx, repositoryName, "Get", query)
+	defer func() { done(err) }()
+
+	p, err = scanProduct(txn.From(ctx, r.db).QueryRowContext(ctx, query, id, tenantID))
 	if err != nil {
 		return domain.Product{}, err
 	}
@@ -59,44 +163,94 @@ func (r *repository) Get(ctx context.Context, id int) (domain.Product, error) {
 	return p, nil
 }
 
-func (r *repository) GetWithWarehouse(ctx context.Context, id int) (domain.ProductWithWarehouse, error) {
-	// query := "SELECT * FROM products WHERE id=?;"
-	query := "SELECT p.id , p.name, p.quantity, p.code_value, p.is_published, p.expiration, p.price, w.id AS warehouseId, " +
-		"w.name, w.adress, w.telephone, w.capacity " +
+func (r *repository) GetByCode(ctx context.Context, codeValue string) (p domain.Product, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return domain.Product{}, err
+	}
+	query := "SELECT " + productColumns + " FROM products WHERE code_value=? AND tenant_id=?;"
+	ctx, done := instrument.Query(ctx, repositoryName, "GetByCode", query)
+	defer func() { done(err) }()
+
+	p, err = scanProduct(txn.From(ctx, r.db).QueryRowContext(ctx, query, codeValue, tenantID))
+	if err != nil {
+		return domain.Product{}, err
+	}
+
+	return p, nil
+}
+
+func (r *repository) GetWithWarehouse(ctx context.Context, id int) (p domain.ProductWithWarehouse, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return domain.ProductWithWarehouse{}, err
+	}
+	query := "SELECT p.id , p.name, p.quantity, p.code_value, p.is_published, p.expiration, p.price, p.currency, p.id_warehouse, p.category_id, " +
+		"w.id AS warehouseId, COALESCE(w.warehouse_code, ''), w.name, w.adress, w.telephone, w.capacity " +
 		"FROM products p " +
 		"INNER JOIN warehouses w ON w.id = p.id_warehouse " +
-		"WHERE p.id = ?"
-	row := r.db.QueryRow(query, id)
-	p := domain.ProductWithWarehouse{
-		Product:   domain.Product{},
-		Warehouse: domain.Warehouse{},
-	}
-	err := row.Scan(&p.Product.ID, &p.Product.Name, &p.Product.Quantity, &p.Product.CodeValue, &p.Product.IsPublished,
-		&p.Product.Expiration, &p.Product.Price, &p.Warehouse.ID, &p.Warehouse.Name, &p.Warehouse.Address, &p.Warehouse.Telephone, &p.Warehouse.Capacity,
+		"WHERE p.id = ? AND p.tenant_id = ?"
+	ctx, done := instrument.Query(ctx, repositoryName, "GetWithWarehouse", query)
+	defer func() { done(err) }()
+
+	row := txn.From(ctx, r.db).QueryRowContext(ctx, query, id, tenantID)
+	err = row.Scan(&p.Product.ID, &p.Product.Name, &p.Product.Quantity, &p.Product.CodeValue, &p.Product.IsPublished,
+		&p.Product.Expiration, &p.Product.Price, &p.Product.Currency, &p.Product.IdWarehouse, &p.Product.CategoryID,
+		&p.Warehouse.ID, &p.Warehouse.WarehouseCode, &p.Warehouse.Name, &p.Warehouse.Address, &p.Warehouse.Telephone, &p.Warehouse.Capacity,
 	)
 	if err != nil {
-		log.Fatal(err)
 		return domain.ProductWithWarehouse{}, err
 	}
 
 	return p, nil
 }
 
-func (r *repository) Exists(ctx context.Context, productCode string) bool {
-	query := "SELECT product_code FROM products WHERE product_code=?;"
-	row := r.db.QueryRow(query, productCode)
-	err := row.Scan(&productCode)
+func (r *repository) Exists(ctx context.Context, codeValue string) (exists bool, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return false, err
+	}
+	query := "SELECT EXISTS(SELECT 1 FROM products WHERE code_value=? AND tenant_id=?);"
+	ctx, done := instrument.Query(ctx, repositoryName, "Exists", query)
+	defer func() { done(err) }()
+
+	err = txn.From(ctx, r.db).QueryRowContext(ctx, query, codeValue, tenantID).Scan(&exists)
+	return exists, err
+}
+
+func (r *repository) WarehouseExists(ctx context.Context, id int) bool {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return false
+	}
+	query := "SELECT id FROM warehouses WHERE id=? AND tenant_id=?;"
+	ctx, done := instrument.Query(ctx, repositoryName, "WarehouseExists", query)
+
+	row := txn.From(ctx, r.db).QueryRowContext(ctx, query, id, tenantID)
+	err = row.Scan(&id)
+	done(err)
 	return err == nil
 }
 
-func (r *repository) Save(ctx context.Context, p domain.Product) (int, error) {
-	query := "INSERT INTO products(name,quantity,code_value,is_published,expiration,price,id_warehouse) VALUES (?,?,?,?,?,?,?)"
-	stmt, err := r.db.Prepare(query)
+func (r *repository) Save(ctx context.Context, p domain.Product) (_ int, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return 0, err
+	}
+	query := "INSERT INTO products(tenant_id,name,quantity,code_value,is_published,expiration,price,currency,id_warehouse,category_id) VALUES (?,?,?,?,?,?,?,?,?,?)"
+	ctx, done := instrument.Query(ctx, repositoryName, "Save", query)
+	defer func() { done(err) }()
+
+	tx, err := txn.Begin(ctx, r.db)
 	if err != nil {
 		return 0, err
 	}
+	defer tx.Rollback()
 
-	res, err := stmt.Exec(p.Name, p.Quantity, p.CodeValue, p.IsPublished, p.Expiration, p.Price, p.IdWarehouse)
+	res, err := tx.ExecContext(ctx, query, tenantID, p.Name, p.Quantity, p.CodeValue, p.IsPublished, p.Expiration, p.Price, p.Currency, p.IdWarehouse, p.CategoryID)
+	if txn.IsDuplicate(err) {
+		return 0, ErrUniqueProduct
+	}
 	if err != nil {
 		return 0, err
 	}
@@ -105,38 +259,63 @@ func (r *repository) Save(ctx context.Context, p domain.Product) (int, error) {
 	if err != nil {
 		return 0, err
 	}
+	// The first price opens the price history of the product.
+	if err = price.Record(ctx, tx, int(id), p.Price, p.Currency, time.Now()); err != nil {
+		return 0, err
+	}
 
-	return int(id), nil
+	return int(id), tx.Commit()
 }
 
-func (r *repository) Update(ctx context.Context, p domain.Product) error {
-	query := "UPDATE products SET name=?, quantity=?, code_value=?, is_published=?, expiration=?, price=? WHERE id=?"
-	stmt, err := r.db.Prepare(query)
+func (r *repository) Update(ctx context.Context, p domain.Product) (err error) {
+	tenantID, err := tenant.Require(ctx)
 	if err != nil {
 		return err
 	}
+	lock := "SELECT price, currency FROM products WHERE id=? AND tenant_id=? FOR UPDATE;"
+	query := "UPDATE products SET name=?, quantity=?, code_value=?, is_published=?, expiration=?, price=?, currency=?, id_warehouse=?, category_id=? WHERE id=? AND tenant_id=?"
+	ctx, done := instrument.Query(ctx, repositoryName, "Update", lock+" "+query)
+	defer func() { done(err) }()
 
-	res, err := stmt.Exec(p.Name, p.Quantity, p.CodeValue, p.IsPublished, p.Expiration, p.Price, p.ID)
+	tx, err := txn.Begin(ctx, r.db)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	_, err = res.RowsAffected()
+	var (
+		before         decimal.Decimal
+		beforeCurrency string
+	)
+	if err = tx.QueryRowContext(ctx, lock, p.ID, tenantID).Scan(&before, &beforeCurrency); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, query, p.Name, p.Quantity, p.CodeValue, p.IsPublished, p.Expiration, p.Price, p.Currency, p.IdWarehouse, p.CategoryID, p.ID, tenantID)
+	if txn.IsDuplicate(err) {
+		return ErrUniqueProduct
+	}
 	if err != nil {
 		return err
 	}
+	if !p.Price.Equal(before) || p.Currency != beforeCurrency {
+		if err = price.Record(ctx, tx, p.ID, p.Price, p.Currency, time.Now()); err != nil {
+			return err
+		}
+	}
 
-	return nil
+	return tx.Commit()
 }
 
-func (r *repository) Delete(ctx context.Context, id int) error {
-	query := "DELETE FROM products WHERE id=?"
-	stmt, err := r.db.Prepare(query)
+func (r *repository) Delete(ctx context.Context, id int) (err error) {
+	tenantID, err := tenant.Require(ctx)
 	if err != nil {
 		return err
 	}
+	query := "DELETE FROM products WHERE id=? AND tenant_id=?"
+	ctx, done := instrument.Query(ctx, repositoryName, "Delete", query)
+	defer func() { done(err) }()
 
-	res, err := stmt.Exec(id)
+	res, err := txn.From(ctx, r.db).ExecContext(ctx, query, id, tenantID)
 	if err != nil {
 		return err
 	}
@@ -152,3 +331,30 @@ func (r *repository) Delete(ctx context.Context, id int) error {
 
 	return nil
 }
+
+func (r *repository) Documents(ctx context.Context) (docs []domain.ProductDocument, err error) {
+	query := "SELECT tenant_id, id, name, code_value FROM products;"
+	ctx, done := instrument.Query(ctx, repositoryName, "Documents", query)
+	defer func() { done(err) }()
+
+	rows, err := txn.From(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var d domain.ProductDocument
+		if err := rows.Scan(&d.TenantID, &d.ID, &d.Name, &d.CodeValue); err != nil {
+			return nil, err
+		}
+		docs = append(docs, d)
+	}
+
+	return docs, rows.Err()
+}
+
+// placeholders returns n comma separated bind parameters for an IN clause.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
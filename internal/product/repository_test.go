@@ -0,0 +1,79 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"repository_class/internal/domain"
+	"repository_class/internal/testdb"
+	"repository_class/pkg/tenant"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenantIsolation(t *testing.T) {
+	db := testdb.Open(t)
+	rp := NewRepository(db)
+	acme := tenant.WithContext(context.Background(), "acme")
+	globex := tenant.WithContext(context.Background(), "globex")
+	acmeWarehouse := testdb.Warehouse(t, db, "acme", 10)
+
+	p := domain.Product{
+		Name:        "widget",
+		CodeValue:   "W-1",
+		Expiration:  time.Now().Add(24 * time.Hour),
+		Price:       decimal.NewFromInt(10),
+		Currency:    "USD",
+		IdWarehouse: acmeWarehouse,
+	}
+	id, err := rp.Save(acme, p)
+	assert.NoError(t, err)
+
+	_, err = rp.Get(globex, id)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = rp.GetByCode(globex, "W-1")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = rp.GetWithWarehouse(globex, id)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	exists, err := rp.Exists(globex, "W-1")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+	assert.False(t, rp.WarehouseExists(globex, acmeWarehouse))
+
+	found, err := rp.Find(globex, domain.ProductFilter{WarehouseIDs: []int{acmeWarehouse}})
+	assert.NoError(t, err)
+	assert.Empty(t, found)
+
+	assert.ErrorIs(t, rp.Update(globex, domain.Product{ID: id, Name: "stolen"}), sql.ErrNoRows)
+	assert.ErrorIs(t, rp.Delete(globex, id), ErrNotFound)
+
+	got, err := rp.Get(acme, id)
+	assert.NoError(t, err)
+	assert.Equal(t, "widget", got.Name)
+}
+
+func TestCodeValueUniquePerTenant(t *testing.T) {
+	db := testdb.Open(t)
+	rp := NewRepository(db)
+	acme := tenant.WithContext(context.Background(), "acme")
+	globex := tenant.WithContext(context.Background(), "globex")
+
+	p := domain.Product{Name: "widget", CodeValue: "W-1", Expiration: time.Now(), Currency: "USD"}
+
+	p.IdWarehouse = testdb.Warehouse(t, db, "acme", 10)
+	_, err := rp.Save(acme, p)
+	assert.NoError(t, err)
+
+	p.IdWarehouse = testdb.Warehouse(t, db, "globex", 10)
+	_, err = rp.Save(globex, p)
+	assert.NoError(t, err)
+	exists, err := rp.Exists(globex, "W-1")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	_, err = rp.Save(globex, p)
+	assert.ErrorIs(t, err, ErrUniqueProduct)
+}
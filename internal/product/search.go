@@ -0,0 +1,65 @@
+package product
+
+import (
+	"context"
+
+	"repository_class/internal/domain"
+	"repository_class/internal/search"
+	"repository_class/pkg/logger"
+	"repository_class/pkg/tenant"
+	"repository_class/pkg/txn"
+)
+
+type indexedRepository struct {
+	Repository
+	index search.Index
+}
+
+// NewIndexedRepository wraps repo so Save, Update and Delete are reflected in
+// index once they commit. The database stays the source of truth: a failed
+// index write is logged rather than failing the product write, and is
+// repaired by the next rebuild of the index.
+func NewIndexedRepository(repo Repository, index search.Index) Repository {
+	return &indexedRepository{Repository: repo, index: index}
+}
+
+func (r *indexedRepository) Save(ctx context.Context, p domain.Product) (int, error) {
+	id, err := r.Repository.Save(ctx, p)
+	if err == nil {
+		p.ID = id
+		r.reindex(ctx, p)
+	}
+	return id, err
+}
+
+func (r *indexedRepository) Update(ctx context.Context, p domain.Product) error {
+	err := r.Repository.Update(ctx, p)
+	if err == nil {
+		r.reindex(ctx, p)
+	}
+	return err
+}
+
+func (r *indexedRepository) Delete(ctx context.Context, id int) error {
+	err := r.Repository.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	tenantID, _ := tenant.FromContext(ctx)
+	txn.AfterCommit(ctx, func() {
+		if err := r.index.Delete(ctx, tenantID, id); err != nil {
+			logger.FromContext(ctx).Warn("delete product from search index", "product_id", id, "error", err)
+		}
+	})
+	return nil
+}
+
+func (r *indexedRepository) reindex(ctx context.Context, p domain.Product) {
+	tenantID, _ := tenant.FromContext(ctx)
+	doc := domain.ProductDocument{TenantID: tenantID, ID: p.ID, Name: p.Name, CodeValue: p.CodeValue}
+	txn.AfterCommit(ctx, func() {
+		if err := r.index.Index(ctx, doc); err != nil {
+			logger.FromContext(ctx).Warn("index product", "product_id", p.ID, "error", err)
+		}
+	})
+}
@@ -4,11 +4,19 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
 	"time"
 
+	"repository_class/internal/category"
 	"repository_class/internal/domain"
+	"repository_class/internal/exchange"
+	"repository_class/internal/lot"
+	"repository_class/internal/search"
+	"repository_class/pkg/cache"
+	"repository_class/pkg/txn"
 
 	"github.com/go-playground/validator/v10"
+	"go.opentelemetry.io/otel"
 )
 
 // Errors
@@ -17,19 +25,52 @@ var (
 	ErrUniqueProduct     = errors.New("product code must be unique")
 	ErrProductRegistered = errors.New("section number is already registered")
 	ErrInvalidStruct     = errors.New("invalid input structure for section")
+	ErrCodeMismatch      = errors.New("product code does not match the requested code")
+	ErrCategoryNotFound  = errors.New("product category does not exist")
+	ErrWarehouseNotFound = errors.New("product warehouse does not exist")
+	ErrInvalidCurrency   = errors.New("product currency must be an ISO-4217 code")
+	ErrEmptySearch       = errors.New("search query must not be empty")
+	ErrStockNotReceived  = errors.New("stock must be received as a lot with its unit cost")
 )
 
+// defaultSearchLimit is how many matches a search without a limit returns.
+const defaultSearchLimit = 20
+
 type Service interface {
 	GetAll(ctx context.Context) ([]domain.Product, error)
+	// List returns the products matching f, with their prices converted into
+	// f.Currency when it is set.
+	List(ctx context.Context, f domain.ProductFilter) ([]domain.Product, error)
 	Get(ctx context.Context, id int) (domain.Product, error)
+	GetByCode(ctx context.Context, codeValue string) (domain.Product, error)
 	Delete(ctx context.Context, id int) error
+	// Create and Update store the product and the write-off bringing it
+	// down to its quantity in one transaction. A quantity above the stock
+	// fails with ErrStockNotReceived.
 	Create(ctx context.Context, prod domain.Product) (domain.Product, error)
 	Update(ctx context.Context, prod domain.Product, id int) (domain.Product, error)
 	GetWithWarehouse(ctx context.Context, id int) (domain.ProductWithWarehouse, error)
+	// Upsert creates the product identified by codeValue or replaces it in
+	// place when it already exists, in one transaction. The returned bool
+	// reports whether the product was created. When a concurrent Upsert
+	// creates the product first it fails with ErrUniqueProduct.
+	Upsert(ctx context.Context, codeValue string, prod domain.Product) (domain.Product, bool, error)
+	// Search returns the products matching q, best match first.
+	Search(ctx context.Context, q domain.ProductSearch) ([]domain.ProductMatch, error)
+	// Reindex rebuilds the search index from the stored products of every
+	// tenant and returns how many it indexed.
+	Reindex(ctx context.Context) (int, error)
 }
 
+var tracer = otel.Tracer("repository_class/internal/product")
+
 type service struct {
-	repo Repository
+	db         *sql.DB
+	repo       Repository
+	categories category.Repository
+	lots       lot.Service
+	rates      exchange.Service
+	index      search.Index
 }
 
 func validateUpdateFields(productDB domain.Product, productUpdate domain.Product) domain.Product {
@@ -47,52 +88,149 @@ func validateUpdateFields(productDB domain.Product, productUpdate domain.Product
 	if productUpdate.Expiration != defaultTime {
 		productDB.Expiration = productUpdate.Expiration
 	}
-	if productUpdate.Price != 0 {
+	if !productUpdate.Price.IsZero() {
 		productDB.Price = productUpdate.Price
 	}
+	if productUpdate.Currency != "" {
+		productDB.Currency = productUpdate.Currency
+	}
+	if productUpdate.CategoryID != nil {
+		productDB.CategoryID = productUpdate.CategoryID
+	}
 
 	return productDB
 }
 
 func (s *service) Update(ctx context.Context, prod domain.Product, id int) (domain.Product, error) {
-	product, err := s.repo.Get(ctx, id)
+	ctx, span := tracer.Start(ctx, "product.Service.Update")
+	defer span.End()
+
+	// The stored quantity is written back, so it must not come from a cache.
+	product, err := s.repo.Get(cache.Bypass(ctx), id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return domain.Product{}, ErrNotFound
 		}
 		return domain.Product{}, err
 	}
-	if s.repo.Exists(ctx, prod.CodeValue) {
-		return domain.Product{}, ErrProductRegistered
+	// The code only conflicts when it moves to a value owned by another product.
+	if prod.CodeValue != "" && prod.CodeValue != product.CodeValue {
+		exists, err := s.repo.Exists(ctx, prod.CodeValue)
+		if err != nil {
+			return domain.Product{}, err
+		}
+		if exists {
+			return domain.Product{}, ErrProductRegistered
+		}
+	}
+	if !s.categoryExists(ctx, prod.CategoryID) {
+		return domain.Product{}, ErrCategoryNotFound
+	}
+	if prod.Quantity < 0 || prod.Price.IsNegative() {
+		return domain.Product{}, ErrInvalidStruct
+	}
+	if prod.Currency != "" {
+		if prod.Currency, err = exchange.ParseCurrency(prod.Currency); err != nil {
+			return domain.Product{}, ErrInvalidCurrency
+		}
 	}
 	prod = validateUpdateFields(product, prod)
-	err = s.repo.Update(ctx, prod)
+	if !s.repo.WarehouseExists(ctx, prod.IdWarehouse) {
+		return domain.Product{}, ErrWarehouseNotFound
+	}
+
+	// The quantity is derived from the lots, so a new value is applied as a
+	// stock adjustment rather than written.
+	quantity := prod.Quantity
+	prod.Quantity = product.Quantity
+	err = txn.Run(ctx, s.db, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, prod); err != nil {
+			return err
+		}
+		if err := s.adjustStock(ctx, prod, quantity); err != nil {
+			return err
+		}
+		var err error
+		prod, err = s.repo.Get(ctx, id)
+		return err
+	})
 	if err != nil {
 		return domain.Product{}, err
 	}
 	return prod, nil
 }
 
+// adjustStock brings the lots of prod down to quantity units, writing the
+// missing ones off earliest expiration first. It fails with
+// ErrStockNotReceived for a higher quantity: stock only comes in as a lot
+// received with its purchase cost, which the valuation is built on.
+func (s *service) adjustStock(ctx context.Context, prod domain.Product, quantity int) error {
+	switch delta := quantity - prod.Quantity; {
+	case delta > 0:
+		return ErrStockNotReceived
+	case delta < 0:
+		_, err := s.lots.WriteOff(ctx, prod.ID, -delta)
+		return err
+	}
+	return nil
+}
+
 func (s *service) Create(ctx context.Context, prod domain.Product) (domain.Product, error) {
+	ctx, span := tracer.Start(ctx, "product.Service.Create")
+	defer span.End()
+
 	validator := validator.New()
 	if err := validator.Struct(&prod); err != nil {
 		return domain.Product{}, ErrInvalidStruct
 	}
-	// TO DO:	Validate if product type exists
+	if !s.categoryExists(ctx, prod.CategoryID) {
+		return domain.Product{}, ErrCategoryNotFound
+	}
+	if !s.repo.WarehouseExists(ctx, prod.IdWarehouse) {
+		return domain.Product{}, ErrWarehouseNotFound
+	}
+
+	if prod.Quantity < 0 || prod.Price.IsNegative() {
+		return domain.Product{}, ErrInvalidStruct
+	}
+	currency, err := s.currency(prod.Currency)
+	if err != nil {
+		return domain.Product{}, err
+	}
+	prod.Currency = currency
 
-	// Method Exists return a true if prod exists in db
-	if s.repo.Exists(ctx, prod.CodeValue) {
+	// Save also fails with ErrUniqueProduct, for a product created since.
+	exists, err := s.repo.Exists(ctx, prod.CodeValue)
+	if err != nil {
+		return domain.Product{}, err
+	}
+	if exists {
 		return domain.Product{}, ErrUniqueProduct
 	}
-	idProd, err := s.repo.Save(ctx, prod)
+	quantity := prod.Quantity
+	prod.Quantity = 0
+	err = txn.Run(ctx, s.db, func(ctx context.Context) error {
+		var err error
+		if prod.ID, err = s.repo.Save(ctx, prod); err != nil {
+			return err
+		}
+		// A new product has no stock; it comes in as lots.
+		if err := s.adjustStock(ctx, prod, quantity); err != nil {
+			return err
+		}
+		prod, err = s.repo.Get(ctx, prod.ID)
+		return err
+	})
 	if err != nil {
 		return domain.Product{}, err
 	}
-	prod, _ = s.repo.Get(ctx, idProd)
 	return prod, nil
 }
 
 func (s *service) GetAll(ctx context.Context) ([]domain.Product, error) {
+	ctx, span := tracer.Start(ctx, "product.Service.GetAll")
+	defer span.End()
+
 	products, err := s.repo.GetAll(ctx)
 	if err != nil {
 		return nil, err
@@ -103,7 +241,44 @@ func (s *service) GetAll(ctx context.Context) ([]domain.Product, error) {
 	return products, nil
 }
 
+func (s *service) List(ctx context.Context, f domain.ProductFilter) ([]domain.Product, error) {
+	ctx, span := tracer.Start(ctx, "product.Service.List")
+	defer span.End()
+
+	if f.Currency != "" {
+		code, err := exchange.ParseCurrency(f.Currency)
+		if err != nil {
+			return nil, ErrInvalidCurrency
+		}
+		f.Currency = code
+	}
+
+	products, err := s.repo.Find(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	if products == nil {
+		return []domain.Product{}, nil
+	}
+	if f.Currency != "" {
+		rates, err := s.rates.Rates(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for i, p := range products {
+			if products[i].Price, err = rates.Convert(p.Price, p.Currency, f.Currency); err != nil {
+				return nil, err
+			}
+			products[i].Currency = f.Currency
+		}
+	}
+	return products, nil
+}
+
 func (s *service) Get(ctx context.Context, id int) (domain.Product, error) {
+	ctx, span := tracer.Start(ctx, "product.Service.Get")
+	defer span.End()
+
 	product, err := s.repo.Get(ctx, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -114,7 +289,88 @@ func (s *service) Get(ctx context.Context, id int) (domain.Product, error) {
 	return product, nil
 }
 
+func (s *service) GetByCode(ctx context.Context, codeValue string) (domain.Product, error) {
+	ctx, span := tracer.Start(ctx, "product.Service.GetByCode")
+	defer span.End()
+
+	product, err := s.repo.GetByCode(ctx, codeValue)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Product{}, ErrNotFound
+		}
+		return domain.Product{}, err
+	}
+	return product, nil
+}
+
+func (s *service) Upsert(ctx context.Context, codeValue string, prod domain.Product) (domain.Product, bool, error) {
+	ctx, span := tracer.Start(ctx, "product.Service.Upsert")
+	defer span.End()
+
+	if prod.CodeValue != "" && prod.CodeValue != codeValue {
+		return domain.Product{}, false, ErrCodeMismatch
+	}
+	prod.CodeValue = codeValue
+
+	validator := validator.New()
+	if err := validator.Struct(&prod); err != nil {
+		return domain.Product{}, false, ErrInvalidStruct
+	}
+	if !s.categoryExists(ctx, prod.CategoryID) {
+		return domain.Product{}, false, ErrCategoryNotFound
+	}
+	if !s.repo.WarehouseExists(ctx, prod.IdWarehouse) {
+		return domain.Product{}, false, ErrWarehouseNotFound
+	}
+
+	if prod.Quantity < 0 || prod.Price.IsNegative() {
+		return domain.Product{}, false, ErrInvalidStruct
+	}
+	currency, err := s.currency(prod.Currency)
+	if err != nil {
+		return domain.Product{}, false, err
+	}
+	prod.Currency = currency
+
+	quantity := prod.Quantity
+	var created bool
+	err = txn.Run(ctx, s.db, func(ctx context.Context) error {
+		current, err := s.repo.GetByCode(ctx, codeValue)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			// Two requests can both find the code free; the unique key makes
+			// the Save of the later one fail with ErrUniqueProduct.
+			created = true
+			prod.Quantity = 0
+			if prod.ID, err = s.repo.Save(ctx, prod); err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		default:
+			prod.ID = current.ID
+			prod.Quantity = current.Quantity
+			if err := s.repo.Update(ctx, prod); err != nil {
+				return err
+			}
+		}
+
+		if err := s.adjustStock(ctx, prod, quantity); err != nil {
+			return err
+		}
+		prod, err = s.repo.Get(ctx, prod.ID)
+		return err
+	})
+	if err != nil {
+		return domain.Product{}, false, err
+	}
+	return prod, created, nil
+}
+
 func (s *service) GetWithWarehouse(ctx context.Context, id int) (domain.ProductWithWarehouse, error) {
+	ctx, span := tracer.Start(ctx, "product.Service.GetWithWarehouse")
+	defer span.End()
+
 	product, err := s.repo.GetWithWarehouse(ctx, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -126,6 +382,9 @@ func (s *service) GetWithWarehouse(ctx context.Context, id int) (domain.ProductW
 }
 
 func (s *service) Delete(ctx context.Context, id int) error {
+	ctx, span := tracer.Start(ctx, "product.Service.Delete")
+	defer span.End()
+
 	err := s.repo.Delete(ctx, id)
 	if err != nil {
 		if errors.Is(err, ErrNotFound) {
@@ -136,6 +395,72 @@ func (s *service) Delete(ctx context.Context, id int) error {
 	return nil
 }
 
-func NewService(repo *Repository) Service {
-	return &service{repo: *repo}
+func (s *service) Search(ctx context.Context, q domain.ProductSearch) ([]domain.ProductMatch, error) {
+	ctx, span := tracer.Start(ctx, "product.Service.Search")
+	defer span.End()
+
+	if q.Query = strings.TrimSpace(q.Query); q.Query == "" {
+		return nil, ErrEmptySearch
+	}
+	if q.Limit <= 0 {
+		q.Limit = defaultSearchLimit
+	}
+	hits, err := s.index.Search(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	matches := []domain.ProductMatch{}
+	if len(hits) == 0 {
+		return matches, nil
+	}
+
+	ids := make([]int, len(hits))
+	for i, h := range hits {
+		ids[i] = h.ID
+	}
+	products, err := s.repo.Find(ctx, domain.ProductFilter{IDs: ids})
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]domain.Product, len(products))
+	for _, p := range products {
+		byID[p.ID] = p
+	}
+	// The index may still hold products deleted by another instance.
+	for _, h := range hits {
+		if p, ok := byID[h.ID]; ok {
+			matches = append(matches, domain.ProductMatch{Product: p, Score: h.Score})
+		}
+	}
+	return matches, nil
+}
+
+func (s *service) Reindex(ctx context.Context) (int, error) {
+	ctx, span := tracer.Start(ctx, "product.Service.Reindex")
+	defer span.End()
+
+	return s.index.Rebuild(ctx, s.repo.Documents)
+}
+
+// currency validates the currency of a new product, the base currency when
+// it has none.
+func (s *service) currency(code string) (string, error) {
+	if code == "" {
+		return s.rates.Base(), nil
+	}
+	code, err := exchange.ParseCurrency(code)
+	if err != nil {
+		return "", ErrInvalidCurrency
+	}
+	return code, nil
+}
+
+// categoryExists reports whether id names a stored category. Products
+// without a category are valid.
+func (s *service) categoryExists(ctx context.Context, id *int) bool {
+	return id == nil || s.categories.Exists(ctx, *id)
+}
+
+func NewService(db *sql.DB, repo *Repository, categories category.Repository, lots lot.Service, rates exchange.Service, index search.Index) Service {
+	return &service{db: db, repo: *repo, categories: categories, lots: lots, rates: rates, index: index}
 }
@@ -0,0 +1,26 @@
+package product
+
+import (
+	"context"
+	"testing"
+
+	"repository_class/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequiredFields(t *testing.T) {
+	// Invalid products are rejected before the repository is used.
+	s := NewService(nil, new(Repository), nil, nil, nil, nil)
+	ctx := context.Background()
+
+	_, err := s.Create(ctx, domain.Product{CodeValue: "W-1", IdWarehouse: 1})
+	assert.ErrorIs(t, err, ErrInvalidStruct)
+	_, err = s.Create(ctx, domain.Product{Name: "widget", IdWarehouse: 1})
+	assert.ErrorIs(t, err, ErrInvalidStruct)
+
+	_, _, err = s.Upsert(ctx, "W-1", domain.Product{IdWarehouse: 1})
+	assert.ErrorIs(t, err, ErrInvalidStruct)
+	_, _, err = s.Upsert(ctx, "", domain.Product{Name: "widget", IdWarehouse: 1})
+	assert.ErrorIs(t, err, ErrInvalidStruct)
+}
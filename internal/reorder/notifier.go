@@ -0,0 +1,119 @@
+package reorder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"repository_class/internal/domain"
+	"repository_class/pkg/logger"
+)
+
+// Notifier delivers reorder alerts.
+type Notifier interface {
+	Notify(ctx context.Context, a domain.ReorderAlert) error
+}
+
+// NotifierConfig selects and configures the Notifier built by NewNotifier.
+type NotifierConfig struct {
+	// Kind is one of log, webhook or smtp.
+	Kind       string
+	WebhookURL string
+	// SMTPAddr is the host:port of the mail server. Locally it points at a
+	// capture server such as MailHog rather than a real relay.
+	SMTPAddr string
+	SMTPFrom string
+	SMTPTo   []string
+}
+
+// NewNotifier returns the Notifier described by cfg.
+func NewNotifier(cfg NotifierConfig) (Notifier, error) {
+	switch cfg.Kind {
+	case "", "log":
+		return LogNotifier{}, nil
+	case "webhook":
+		if cfg.WebhookURL == "" {
+			return nil, fmt.Errorf("webhook notifier: missing url")
+		}
+		return &WebhookNotifier{URL: cfg.WebhookURL, Client: &http.Client{Timeout: 5 * time.Second}}, nil
+	case "smtp":
+		if cfg.SMTPAddr == "" || len(cfg.SMTPTo) == 0 {
+			return nil, fmt.Errorf("smtp notifier: missing address or recipients")
+		}
+		return &SMTPNotifier{Addr: cfg.SMTPAddr, From: cfg.SMTPFrom, To: cfg.SMTPTo}, nil
+	default:
+		return nil, fmt.Errorf("unknown notifier %q", cfg.Kind)
+	}
+}
+
+// LogNotifier writes alerts to the logger of the context.
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(ctx context.Context, a domain.ReorderAlert) error {
+	logger.FromContext(ctx).Warn("product below reorder point",
+		"product_id", a.ProductID,
+		"code_value", a.CodeValue,
+		"warehouse_id", a.WarehouseID,
+		"quantity", a.Quantity,
+		"previous_quantity", a.PreviousQuantity,
+		"reorder_point", a.ReorderPoint,
+		"reorder_quantity", a.ReorderQuantity,
+	)
+	return nil
+}
+
+// WebhookNotifier posts each alert as JSON to URL and expects a 2xx reply.
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, a domain.ReorderAlert) error {
+	body, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := n.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("webhook replied %s", res.Status)
+	}
+	return nil
+}
+
+// SMTPNotifier mails each alert to To through the server at Addr, without
+// authentication.
+type SMTPNotifier struct {
+	Addr string
+	From string
+	To   []string
+}
+
+func (n *SMTPNotifier) Notify(_ context.Context, a domain.ReorderAlert) error {
+	subject := fmt.Sprintf("Reorder %s: %d left", a.CodeValue, a.Quantity)
+	body := fmt.Sprintf("Product %d (%s, %s) in warehouse %d went from %d to %d units, "+
+		"at or below its reorder point of %d.\r\nSuggested reorder quantity: %d.\r\n",
+		a.ProductID, a.Name, a.CodeValue, a.WarehouseID, a.PreviousQuantity, a.Quantity, a.ReorderPoint, a.ReorderQuantity)
+
+	msg := "From: " + n.From + "\r\n" +
+		"To: " + strings.Join(n.To, ", ") + "\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"\r\n" + body
+
+	return smtp.SendMail(n.Addr, nil, n.From, n.To, []byte(msg))
+}
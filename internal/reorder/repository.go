@@ -0,0 +1,163 @@
+package reorder
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"repository_class/internal/domain"
+	"repository_class/pkg/instrument"
+	"repository_class/pkg/tenant"
+	"repository_class/pkg/txn"
+)
+
+// Repository encapsulates the storage of reorder policies, kept on the
+// products and warehouses rows. Every method only sees the products and
+// warehouses of the tenant in its context and fails with tenant.ErrMissing
+// when there is none.
+type Repository interface {
+	ProductPolicy(ctx context.Context, productID int) (domain.ReorderPolicy, error)
+	SetProductPolicy(ctx context.Context, productID int, p domain.ReorderPolicy) error
+	WarehousePolicy(ctx context.Context, warehouseID int) (domain.ReorderPolicy, error)
+	SetWarehousePolicy(ctx context.Context, warehouseID int, p domain.ReorderPolicy) error
+	// Level returns the product with its effective policy. It returns
+	// errNoReorderPoint when neither the product nor its warehouse has one.
+	Level(ctx context.Context, productID int) (domain.LowStockItem, error)
+	LowStock(ctx context.Context, warehouseID *int) ([]domain.LowStockItem, error)
+}
+
+const repositoryName = "reorder"
+
+var errNoReorderPoint = errors.New("no reorder point")
+
+// levelQuery selects a product with the policy that applies to it: its own
+// values, else the defaults of its warehouse.
+const levelQuery = "SELECT p.id, p.name, p.code_value, p.id_warehouse, p.quantity, " +
+	"COALESCE(p.reorder_point, w.default_reorder_point), COALESCE(p.reorder_quantity, w.default_reorder_quantity, 0) " +
+	"FROM products p INNER JOIN warehouses w ON w.id = p.id_warehouse"
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) ProductPolicy(ctx context.Context, productID int) (p domain.ReorderPolicy, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return domain.ReorderPolicy{}, err
+	}
+	query := "SELECT reorder_point, reorder_quantity FROM products WHERE id=? AND tenant_id=?;"
+	ctx, done := instrument.Query(ctx, repositoryName, "ProductPolicy", query)
+	defer func() { done(err) }()
+
+	err = txn.From(ctx, r.db).QueryRowContext(ctx, query, productID, tenantID).Scan(&p.ReorderPoint, &p.ReorderQuantity)
+	if err != nil {
+		return domain.ReorderPolicy{}, err
+	}
+
+	return p, nil
+}
+
+func (r *repository) SetProductPolicy(ctx context.Context, productID int, p domain.ReorderPolicy) (err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return err
+	}
+	query := "UPDATE products SET reorder_point=?, reorder_quantity=? WHERE id=? AND tenant_id=?;"
+	ctx, done := instrument.Query(ctx, repositoryName, "SetProductPolicy", query)
+	defer func() { done(err) }()
+
+	_, err = txn.From(ctx, r.db).ExecContext(ctx, query, p.ReorderPoint, p.ReorderQuantity, productID, tenantID)
+	return err
+}
+
+func (r *repository) WarehousePolicy(ctx context.Context, warehouseID int) (p domain.ReorderPolicy, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return domain.ReorderPolicy{}, err
+	}
+	query := "SELECT default_reorder_point, default_reorder_quantity FROM warehouses WHERE id=? AND tenant_id=?;"
+	ctx, done := instrument.Query(ctx, repositoryName, "WarehousePolicy", query)
+	defer func() { done(err) }()
+
+	err = txn.From(ctx, r.db).QueryRowContext(ctx, query, warehouseID, tenantID).Scan(&p.ReorderPoint, &p.ReorderQuantity)
+	if err != nil {
+		return domain.ReorderPolicy{}, err
+	}
+
+	return p, nil
+}
+
+func (r *repository) SetWarehousePolicy(ctx context.Context, warehouseID int, p domain.ReorderPolicy) (err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return err
+	}
+	query := "UPDATE warehouses SET default_reorder_point=?, default_reorder_quantity=? WHERE id=? AND tenant_id=?;"
+	ctx, done := instrument.Query(ctx, repositoryName, "SetWarehousePolicy", query)
+	defer func() { done(err) }()
+
+	_, err = txn.From(ctx, r.db).ExecContext(ctx, query, p.ReorderPoint, p.ReorderQuantity, warehouseID, tenantID)
+	return err
+}
+
+func (r *repository) Level(ctx context.Context, productID int) (item domain.LowStockItem, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return domain.LowStockItem{}, err
+	}
+	query := levelQuery + " WHERE p.id=? AND p.tenant_id=?;"
+	ctx, done := instrument.Query(ctx, repositoryName, "Level", query)
+	defer func() { done(err) }()
+
+	var point *int
+	err = txn.From(ctx, r.db).QueryRowContext(ctx, query, productID, tenantID).Scan(&item.ProductID, &item.Name, &item.CodeValue,
+		&item.WarehouseID, &item.Quantity, &point, &item.ReorderQuantity)
+	if err != nil {
+		return domain.LowStockItem{}, err
+	}
+	if point == nil {
+		return domain.LowStockItem{}, errNoReorderPoint
+	}
+	item.ReorderPoint = *point
+
+	return item, nil
+}
+
+func (r *repository) LowStock(ctx context.Context, warehouseID *int) (items []domain.LowStockItem, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	args := []interface{}{tenantID}
+	query := levelQuery + " WHERE p.tenant_id=? AND p.quantity <= COALESCE(p.reorder_point, w.default_reorder_point)"
+	if warehouseID != nil {
+		query += " AND p.id_warehouse=?"
+		args = append(args, *warehouseID)
+	}
+	query += " ORDER BY p.id_warehouse, p.id;"
+	ctx, done := instrument.Query(ctx, repositoryName, "LowStock", query)
+	defer func() { done(err) }()
+
+	rows, err := txn.From(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item := domain.LowStockItem{}
+		if err := rows.Scan(&item.ProductID, &item.Name, &item.CodeValue, &item.WarehouseID,
+			&item.Quantity, &item.ReorderPoint, &item.ReorderQuantity); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
@@ -0,0 +1,75 @@
+package reorder
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"repository_class/internal/domain"
+	"repository_class/internal/testdb"
+	"repository_class/pkg/tenant"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevelFallsBackToWarehouse(t *testing.T) {
+	db := testdb.Open(t)
+	rp := NewRepository(db)
+	ctx := tenant.WithContext(context.Background(), "acme")
+	warehouseID := testdb.Warehouse(t, db, "acme", 0)
+	own := testdb.Product(t, db, "acme", warehouseID, "W-1")
+	inherited := testdb.Product(t, db, "acme", warehouseID, "W-2")
+
+	_, err := rp.Level(ctx, inherited)
+	assert.ErrorIs(t, err, errNoReorderPoint)
+
+	point, quantity, defaultPoint := 0, 10, 5
+	assert.NoError(t, rp.SetProductPolicy(ctx, own, domain.ReorderPolicy{ReorderPoint: &point, ReorderQuantity: &quantity}))
+	assert.NoError(t, rp.SetWarehousePolicy(ctx, warehouseID, domain.ReorderPolicy{ReorderPoint: &defaultPoint}))
+
+	item, err := rp.Level(ctx, inherited)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, item.ReorderPoint)
+	assert.Zero(t, item.ReorderQuantity)
+
+	// Both products are empty, so each is at or below the point that applies
+	// to it.
+	items, err := rp.LowStock(ctx, nil)
+	assert.NoError(t, err)
+	if assert.Len(t, items, 2) {
+		assert.Equal(t, own, items[0].ProductID)
+		assert.Equal(t, 10, items[0].ReorderQuantity)
+	}
+}
+
+func TestTenantIsolation(t *testing.T) {
+	db := testdb.Open(t)
+	rp := NewRepository(db)
+	acme := tenant.WithContext(context.Background(), "acme")
+	globex := tenant.WithContext(context.Background(), "globex")
+	warehouseID := testdb.Warehouse(t, db, "acme", 0)
+	productID := testdb.Product(t, db, "acme", warehouseID, "W-1")
+	point, quantity := 5, 10
+	assert.NoError(t, rp.SetProductPolicy(acme, productID, domain.ReorderPolicy{ReorderPoint: &point, ReorderQuantity: &quantity}))
+
+	_, err := rp.ProductPolicy(globex, productID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = rp.WarehousePolicy(globex, warehouseID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = rp.Level(globex, productID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	items, err := rp.LowStock(globex, &warehouseID)
+	assert.NoError(t, err)
+	assert.Empty(t, items)
+
+	stolen := 0
+	assert.NoError(t, rp.SetProductPolicy(globex, productID, domain.ReorderPolicy{ReorderPoint: &stolen}))
+	assert.NoError(t, rp.SetWarehousePolicy(globex, warehouseID, domain.ReorderPolicy{ReorderPoint: &stolen}))
+
+	item, err := rp.Level(acme, productID)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, item.ReorderPoint)
+	items, err = rp.LowStock(acme, &warehouseID)
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+}
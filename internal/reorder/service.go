@@ -0,0 +1,151 @@
+package reorder
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"repository_class/internal/domain"
+	"repository_class/pkg/logger"
+	"repository_class/pkg/txn"
+
+	"go.opentelemetry.io/otel"
+)
+
+// Errors
+var (
+	ErrProductNotFound   = errors.New("product not found")
+	ErrWarehouseNotFound = errors.New("warehouse not found")
+	ErrInvalidPolicy     = errors.New("reorder point and quantity cannot be negative")
+)
+
+type Service interface {
+	// LowStock lists the products at or below their reorder point, in every
+	// warehouse when warehouseID is nil.
+	LowStock(ctx context.Context, warehouseID *int) ([]domain.LowStockItem, error)
+	ProductPolicy(ctx context.Context, productID int) (domain.ReorderPolicy, error)
+	SetProductPolicy(ctx context.Context, productID int, p domain.ReorderPolicy) (domain.ReorderPolicy, error)
+	WarehousePolicy(ctx context.Context, warehouseID int) (domain.ReorderPolicy, error)
+	SetWarehousePolicy(ctx context.Context, warehouseID int, p domain.ReorderPolicy) (domain.ReorderPolicy, error)
+	// QuantityChanged is called after the quantity of a product changed from
+	// before. It sends an alert when the change crossed the reorder point.
+	// Failures are logged: the change itself has already been stored. Within
+	// a transaction the alert waits for it to commit.
+	QuantityChanged(ctx context.Context, productID, before int)
+}
+
+var tracer = otel.Tracer("repository_class/internal/reorder")
+
+type service struct {
+	repo     Repository
+	notifier Notifier
+}
+
+func NewService(repo *Repository, notifier Notifier) Service {
+	return &service{repo: *repo, notifier: notifier}
+}
+
+func validPolicy(p domain.ReorderPolicy) bool {
+	return (p.ReorderPoint == nil || *p.ReorderPoint >= 0) &&
+		(p.ReorderQuantity == nil || *p.ReorderQuantity >= 0)
+}
+
+func (s *service) LowStock(ctx context.Context, warehouseID *int) ([]domain.LowStockItem, error) {
+	ctx, span := tracer.Start(ctx, "reorder.Service.LowStock")
+	defer span.End()
+
+	items, err := s.repo.LowStock(ctx, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		return []domain.LowStockItem{}, nil
+	}
+	return items, nil
+}
+
+func (s *service) ProductPolicy(ctx context.Context, productID int) (domain.ReorderPolicy, error) {
+	ctx, span := tracer.Start(ctx, "reorder.Service.ProductPolicy")
+	defer span.End()
+
+	p, err := s.repo.ProductPolicy(ctx, productID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ReorderPolicy{}, ErrProductNotFound
+		}
+		return domain.ReorderPolicy{}, err
+	}
+	return p, nil
+}
+
+func (s *service) SetProductPolicy(ctx context.Context, productID int, p domain.ReorderPolicy) (domain.ReorderPolicy, error) {
+	ctx, span := tracer.Start(ctx, "reorder.Service.SetProductPolicy")
+	defer span.End()
+
+	if !validPolicy(p) {
+		return domain.ReorderPolicy{}, ErrInvalidPolicy
+	}
+	if _, err := s.ProductPolicy(ctx, productID); err != nil {
+		return domain.ReorderPolicy{}, err
+	}
+	if err := s.repo.SetProductPolicy(ctx, productID, p); err != nil {
+		return domain.ReorderPolicy{}, err
+	}
+	return p, nil
+}
+
+func (s *service) WarehousePolicy(ctx context.Context, warehouseID int) (domain.ReorderPolicy, error) {
+	ctx, span := tracer.Start(ctx, "reorder.Service.WarehousePolicy")
+	defer span.End()
+
+	p, err := s.repo.WarehousePolicy(ctx, warehouseID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ReorderPolicy{}, ErrWarehouseNotFound
+		}
+		return domain.ReorderPolicy{}, err
+	}
+	return p, nil
+}
+
+func (s *service) SetWarehousePolicy(ctx context.Context, warehouseID int, p domain.ReorderPolicy) (domain.ReorderPolicy, error) {
+	ctx, span := tracer.Start(ctx, "reorder.Service.SetWarehousePolicy")
+	defer span.End()
+
+	if !validPolicy(p) {
+		return domain.ReorderPolicy{}, ErrInvalidPolicy
+	}
+	if _, err := s.WarehousePolicy(ctx, warehouseID); err != nil {
+		return domain.ReorderPolicy{}, err
+	}
+	if err := s.repo.SetWarehousePolicy(ctx, warehouseID, p); err != nil {
+		return domain.ReorderPolicy{}, err
+	}
+	return p, nil
+}
+
+func (s *service) QuantityChanged(ctx context.Context, productID, before int) {
+	ctx, span := tracer.Start(ctx, "reorder.Service.QuantityChanged")
+	defer span.End()
+
+	log := logger.FromContext(ctx)
+
+	item, err := s.repo.Level(ctx, productID)
+	if err != nil {
+		if !errors.Is(err, errNoReorderPoint) {
+			log.Error("read reorder level", "product_id", productID, "error", err)
+		}
+		return
+	}
+	if before <= item.ReorderPoint || item.Quantity > item.ReorderPoint {
+		return
+	}
+
+	alert := domain.ReorderAlert{LowStockItem: item, PreviousQuantity: before, At: time.Now()}
+	txn.AfterCommit(ctx, func() {
+		if err := s.notifier.Notify(ctx, alert); err != nil {
+			log.Error("send reorder alert", "product_id", productID, "error", err)
+		}
+	})
+}
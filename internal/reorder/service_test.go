@@ -0,0 +1,74 @@
+package reorder
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"repository_class/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type levelRepository struct {
+	Repository
+	item domain.LowStockItem
+}
+
+func (r levelRepository) Level(context.Context, int) (domain.LowStockItem, error) {
+	return r.item, nil
+}
+
+type recordingNotifier struct {
+	alerts []domain.ReorderAlert
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, a domain.ReorderAlert) error {
+	n.alerts = append(n.alerts, a)
+	return nil
+}
+
+func TestQuantityChangedAlertsOnlyWhenCrossing(t *testing.T) {
+	cases := []struct {
+		name     string
+		before   int
+		after    int
+		expected int
+	}{
+		{"crosses", 12, 10, 1},
+		{"already below", 8, 5, 0},
+		{"stays above", 20, 15, 0},
+		{"restocked", 5, 30, 0},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var repo Repository = levelRepository{item: domain.LowStockItem{ProductID: 1, Quantity: tc.after, ReorderPoint: 10}}
+			notifier := &recordingNotifier{}
+
+			NewService(&repo, notifier).QuantityChanged(context.Background(), 1, tc.before)
+
+			assert.Len(t, notifier.alerts, tc.expected)
+		})
+	}
+}
+
+func TestWebhookNotifierPostsAlert(t *testing.T) {
+	var got domain.ReorderAlert
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	n, err := NewNotifier(NotifierConfig{Kind: "webhook", WebhookURL: srv.URL})
+	assert.NoError(t, err)
+
+	alert := domain.ReorderAlert{LowStockItem: domain.LowStockItem{ProductID: 7, Quantity: 2, ReorderPoint: 5}, PreviousQuantity: 6}
+	assert.NoError(t, n.Notify(context.Background(), alert))
+	assert.Equal(t, 7, got.ProductID)
+	assert.Equal(t, 6, got.PreviousQuantity)
+}
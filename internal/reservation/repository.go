@@ -0,0 +1,263 @@
+package reservation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"repository_class/internal/domain"
+	"repository_class/internal/lot"
+	"repository_class/pkg/instrument"
+	"repository_class/pkg/tenant"
+)
+
+// Repository encapsulates the storage of a Reservation. Reserve and Confirm
+// lock the product row, so concurrent calls for the same product run one
+// after the other and can never hold more than its unexpired stock. Every
+// method but ExpireDue only sees the reservations of the products of the
+// tenant in its context and fails with tenant.ErrMissing when there is none.
+type Repository interface {
+	Get(ctx context.Context, id int) (domain.Reservation, error)
+	ListByProduct(ctx context.Context, productID int) ([]domain.Reservation, error)
+	Availability(ctx context.Context, productID int) (domain.StockAvailability, error)
+	Reserve(ctx context.Context, r domain.Reservation) (int, error)
+	// Confirm issues the stock of an active reservation and returns the
+	// product quantity before it.
+	Confirm(ctx context.Context, id int) (int, error)
+	Release(ctx context.Context, id int) error
+	// ExpireDue marks the reservations of every tenant that are past their
+	// expiry as expired.
+	ExpireDue(ctx context.Context, now time.Time) (int64, error)
+}
+
+const repositoryName = "reservation"
+
+const reservationColumns = "r.id, r.product_id, r.quantity, r.status, r.expires_at, r.created_at"
+
+// reservationsOfTenant selects the reservations r of the products p of a
+// tenant, bound first.
+const reservationsOfTenant = " FROM reservations r INNER JOIN products p ON p.id = r.product_id WHERE p.tenant_id=?"
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanReservation(s scanner) (domain.Reservation, error) {
+	r := domain.Reservation{}
+	err := s.Scan(&r.ID, &r.ProductID, &r.Quantity, &r.Status, &r.ExpiresAt, &r.CreatedAt)
+	return r, err
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) Get(ctx context.Context, id int) (res domain.Reservation, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return domain.Reservation{}, err
+	}
+	query := "SELECT " + reservationColumns + reservationsOfTenant + " AND r.id=?;"
+	ctx, done := instrument.Query(ctx, repositoryName, "Get", query)
+	defer func() { done(err) }()
+
+	res, err = scanReservation(r.db.QueryRowContext(ctx, query, tenantID, id))
+	if err != nil {
+		return domain.Reservation{}, err
+	}
+
+	return res, nil
+}
+
+func (r *repository) ListByProduct(ctx context.Context, productID int) (reservations []domain.Reservation, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := "SELECT " + reservationColumns + reservationsOfTenant + " AND r.product_id=? ORDER BY r.id;"
+	ctx, done := instrument.Query(ctx, repositoryName, "ListByProduct", query)
+	defer func() { done(err) }()
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		res, err := scanReservation(rows)
+		if err != nil {
+			return nil, err
+		}
+		reservations = append(reservations, res)
+	}
+
+	return reservations, rows.Err()
+}
+
+func (r *repository) Availability(ctx context.Context, productID int) (a domain.StockAvailability, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return domain.StockAvailability{}, err
+	}
+	query := "SELECT (SELECT COALESCE(SUM(l.quantity), 0) FROM lots l WHERE l.product_id = p.id AND l.expiration>?), " +
+		"(SELECT COALESCE(SUM(r.quantity), 0) FROM reservations r WHERE r.product_id = p.id AND r.status='active' AND r.expires_at>?) " +
+		"FROM products p WHERE p.id=? AND p.tenant_id=?;"
+	ctx, done := instrument.Query(ctx, repositoryName, "Availability", query)
+	defer func() { done(err) }()
+
+	now := time.Now()
+	row := r.db.QueryRowContext(ctx, query, now, now, productID, tenantID)
+	if err = row.Scan(&a.Quantity, &a.Reserved); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.StockAvailability{}, ErrProductNotFound
+		}
+		return domain.StockAvailability{}, err
+	}
+	a.ProductID = productID
+	a.Available = a.Quantity - a.Reserved
+
+	return a, nil
+}
+
+func (r *repository) Reserve(ctx context.Context, res domain.Reservation) (_ int, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return 0, err
+	}
+	lock := "SELECT id FROM products WHERE id=? AND tenant_id=? FOR UPDATE;"
+	insert := "INSERT INTO reservations (product_id, quantity, status, expires_at) VALUES (?, ?, 'active', ?);"
+	ctx, done := instrument.Query(ctx, repositoryName, "Reserve", lock+" "+lot.AvailableQuery+" "+insert)
+	defer func() { done(err) }()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var productID int
+	if err = tx.QueryRowContext(ctx, lock, res.ProductID, tenantID).Scan(&productID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrProductNotFound
+		}
+		return 0, err
+	}
+	available, err := lot.Available(ctx, tx, res.ProductID, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	if available < res.Quantity {
+		return 0, ErrInsufficientStock
+	}
+
+	result, err := tx.ExecContext(ctx, insert, res.ProductID, res.Quantity, res.ExpiresAt)
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), tx.Commit()
+}
+
+func (r *repository) Confirm(ctx context.Context, id int) (_ int, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return 0, err
+	}
+	owner := "SELECT r.product_id" + reservationsOfTenant + " AND r.id=?;"
+	lockProduct := "SELECT quantity FROM products WHERE id=? AND tenant_id=? FOR UPDATE;"
+	lock := "SELECT quantity FROM reservations WHERE id=? AND status='active' AND expires_at>? FOR UPDATE;"
+	confirm := "UPDATE reservations SET status='confirmed' WHERE id=?;"
+	ctx, done := instrument.Query(ctx, repositoryName, "Confirm", owner+" "+lockProduct+" "+lock+" "+confirm)
+	defer func() { done(err) }()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// The product row is locked before the reservation, in the same order
+	// as Reserve, so the two cannot deadlock.
+	var productID, quantity, before int
+	if err = tx.QueryRowContext(ctx, owner, tenantID, id).Scan(&productID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrNotFound
+		}
+		return 0, err
+	}
+	if err = tx.QueryRowContext(ctx, lockProduct, productID, tenantID).Scan(&before); err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	if err = tx.QueryRowContext(ctx, lock, id, now).Scan(&quantity); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrNotActive
+		}
+		return 0, err
+	}
+
+	// The stock leaves first-expired-first-out from the unexpired lots.
+	if _, err = lot.Issue(ctx, tx, productID, quantity, now); err != nil {
+		if errors.Is(err, lot.ErrInsufficientStock) {
+			return 0, ErrInsufficientStock
+		}
+		return 0, err
+	}
+	if _, err = tx.ExecContext(ctx, confirm, id); err != nil {
+		return 0, err
+	}
+
+	return before, tx.Commit()
+}
+
+func (r *repository) Release(ctx context.Context, id int) (err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return err
+	}
+	query := "UPDATE reservations r INNER JOIN products p ON p.id = r.product_id SET r.status='released' " +
+		"WHERE r.id=? AND r.status='active' AND p.tenant_id=?;"
+	ctx, done := instrument.Query(ctx, repositoryName, "Release", query)
+	defer func() { done(err) }()
+
+	res, err := r.db.ExecContext(ctx, query, id, tenantID)
+	if err != nil {
+		return err
+	}
+
+	affect, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affect < 1 {
+		return ErrNotActive
+	}
+
+	return nil
+}
+
+func (r *repository) ExpireDue(ctx context.Context, now time.Time) (_ int64, err error) {
+	query := "UPDATE reservations SET status='expired' WHERE status='active' AND expires_at<=?;"
+	ctx, done := instrument.Query(ctx, repositoryName, "ExpireDue", query)
+	defer func() { done(err) }()
+
+	res, err := r.db.ExecContext(ctx, query, now)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,151 @@
+package reservation
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	"repository_class/internal/domain"
+	"repository_class/internal/lot"
+	"repository_class/internal/testdb"
+	"repository_class/pkg/tenant"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenantIsolation(t *testing.T) {
+	db := testdb.Open(t)
+	rp := NewRepository(db)
+	acme := tenant.WithContext(context.Background(), "acme")
+	globex := tenant.WithContext(context.Background(), "globex")
+	productID := testdb.Product(t, db, "acme", testdb.Warehouse(t, db, "acme", 0), "W-1")
+	testdb.Lot(t, db, domain.Lot{ProductID: productID, LotCode: "L-1", Quantity: 5, Expiration: time.Now().Add(time.Hour)})
+
+	id, err := rp.Reserve(acme, domain.Reservation{ProductID: productID, Quantity: 2, ExpiresAt: time.Now().Add(time.Minute)})
+	assert.NoError(t, err)
+
+	_, err = rp.Get(globex, id)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	reservations, err := rp.ListByProduct(globex, productID)
+	assert.NoError(t, err)
+	assert.Empty(t, reservations)
+	_, err = rp.Availability(globex, productID)
+	assert.ErrorIs(t, err, ErrProductNotFound)
+
+	_, err = rp.Reserve(globex, domain.Reservation{ProductID: productID, Quantity: 1, ExpiresAt: time.Now().Add(time.Minute)})
+	assert.ErrorIs(t, err, ErrProductNotFound)
+	_, err = rp.Confirm(globex, id)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, rp.Release(globex, id), ErrNotActive)
+
+	res, err := rp.Get(acme, id)
+	assert.NoError(t, err)
+	assert.Equal(t, "active", res.Status)
+}
+
+func TestIssueKeepsReservedStock(t *testing.T) {
+	db := testdb.Open(t)
+	rp := NewRepository(db)
+	lots := lot.NewRepository(db)
+	ctx := tenant.WithContext(context.Background(), "acme")
+	productID := testdb.Product(t, db, "acme", testdb.Warehouse(t, db, "acme", 0), "W-1")
+	testdb.Lot(t, db, domain.Lot{ProductID: productID, LotCode: "L-1", Quantity: 5, Expiration: time.Now().Add(time.Hour)})
+
+	id, err := rp.Reserve(ctx, domain.Reservation{ProductID: productID, Quantity: 3, ExpiresAt: time.Now().Add(time.Minute)})
+	assert.NoError(t, err)
+
+	// Only the 2 units nobody holds can be issued or written off.
+	_, _, err = lots.Issue(ctx, productID, 3, time.Now())
+	assert.ErrorIs(t, err, lot.ErrInsufficientStock)
+	_, _, err = lots.WriteOff(ctx, productID, 3)
+	assert.ErrorIs(t, err, lot.ErrInsufficientStock)
+	allocations, _, err := lots.Issue(ctx, productID, 2, time.Now())
+	assert.NoError(t, err)
+	assert.Len(t, allocations, 1)
+
+	// The held units are still there for the reservation.
+	_, err = rp.Confirm(ctx, id)
+	assert.NoError(t, err)
+	a, err := rp.Availability(ctx, productID)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, a.Quantity)
+	assert.Equal(t, 0, a.Reserved)
+}
+
+func TestConcurrentReservesNeverOversell(t *testing.T) {
+	// Reserve must wait for the lock of the product, which connections
+	// sharing the transaction of testdb.Open never do.
+	db := testdb.OpenCommitted(t)
+	rp := NewRepository(db)
+	ctx := tenant.WithContext(context.Background(), "reserve-race")
+	warehouseID := testdb.Warehouse(t, db, "reserve-race", 0)
+	productID := testdb.Product(t, db, "reserve-race", warehouseID, "W-1")
+	t.Cleanup(func() {
+		db.Exec("DELETE FROM products WHERE id=?;", productID)
+		db.Exec("DELETE FROM warehouses WHERE id=?;", warehouseID)
+	})
+	testdb.Lot(t, db, domain.Lot{ProductID: productID, LotCode: "L-1", Quantity: 10, Expiration: time.Now().Add(time.Hour)})
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		reserved int
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := rp.Reserve(ctx, domain.Reservation{ProductID: productID, Quantity: 3, ExpiresAt: time.Now().Add(time.Minute)})
+			if err != nil {
+				assert.ErrorIs(t, err, ErrInsufficientStock)
+				return
+			}
+			mu.Lock()
+			reserved += 3
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 9, reserved)
+	a, err := rp.Availability(ctx, productID)
+	assert.NoError(t, err)
+	assert.Equal(t, reserved, a.Reserved)
+	assert.Equal(t, 1, a.Available)
+}
+
+func TestExpireDueFreesHeldStock(t *testing.T) {
+	db := testdb.Open(t)
+	rp := NewRepository(db)
+	ctx := tenant.WithContext(context.Background(), "acme")
+	productID := testdb.Product(t, db, "acme", testdb.Warehouse(t, db, "acme", 0), "W-1")
+	testdb.Lot(t, db, domain.Lot{ProductID: productID, LotCode: "L-1", Quantity: 5, Expiration: time.Now().Add(3 * time.Hour)})
+
+	id, err := rp.Reserve(ctx, domain.Reservation{ProductID: productID, Quantity: 3, ExpiresAt: time.Now().Add(time.Hour)})
+	assert.NoError(t, err)
+	_, err = rp.Reserve(ctx, domain.Reservation{ProductID: productID, Quantity: 5, ExpiresAt: time.Now().Add(time.Hour)})
+	assert.ErrorIs(t, err, ErrInsufficientStock)
+
+	// The reservation is not due yet.
+	n, err := rp.ExpireDue(context.Background(), time.Now())
+	assert.NoError(t, err)
+	assert.Zero(t, n)
+
+	n, err = rp.ExpireDue(context.Background(), time.Now().Add(2*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	res, err := rp.Get(ctx, id)
+	assert.NoError(t, err)
+	assert.Equal(t, "expired", res.Status)
+
+	a, err := rp.Availability(ctx, productID)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, a.Reserved)
+	assert.Equal(t, 5, a.Available)
+	_, err = rp.Confirm(ctx, id)
+	assert.ErrorIs(t, err, ErrNotActive)
+	_, err = rp.Reserve(ctx, domain.Reservation{ProductID: productID, Quantity: 5, ExpiresAt: time.Now().Add(time.Hour)})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,153 @@
+package reservation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"repository_class/internal/domain"
+	"repository_class/internal/reorder"
+
+	"go.opentelemetry.io/otel"
+)
+
+// Errors
+var (
+	ErrNotFound          = errors.New("reservation not found")
+	ErrProductNotFound   = errors.New("product not found")
+	ErrInsufficientStock = errors.New("not enough stock available")
+	ErrNotActive         = errors.New("reservation is no longer active")
+	ErrInvalidQuantity   = errors.New("reservation quantity must be positive")
+	ErrInvalidTTL        = errors.New("reservation ttl out of range")
+)
+
+const (
+	// DefaultTTL is how long a reservation holds stock when no TTL is given.
+	DefaultTTL = 15 * time.Minute
+	// MaxTTL bounds the TTL a caller may ask for.
+	MaxTTL = 24 * time.Hour
+)
+
+type Service interface {
+	Get(ctx context.Context, id int) (domain.Reservation, error)
+	ListByProduct(ctx context.Context, productID int) ([]domain.Reservation, error)
+	Availability(ctx context.Context, productID int) (domain.StockAvailability, error)
+	// Create holds quantity units of the product for ttl, DefaultTTL when
+	// ttl is zero.
+	Create(ctx context.Context, productID, quantity int, ttl time.Duration) (domain.Reservation, error)
+	// Confirm issues the reserved stock, decreasing the product quantity.
+	Confirm(ctx context.Context, id int) (domain.Reservation, error)
+	// Release gives the reserved stock back without issuing it.
+	Release(ctx context.Context, id int) (domain.Reservation, error)
+	// ExpireDue marks the active reservations past their expiry as expired
+	// and returns how many it changed.
+	ExpireDue(ctx context.Context) (int64, error)
+}
+
+var tracer = otel.Tracer("repository_class/internal/reservation")
+
+type service struct {
+	repo  Repository
+	stock reorder.Service
+}
+
+func NewService(repo *Repository, stock reorder.Service) Service {
+	return &service{repo: *repo, stock: stock}
+}
+
+func (s *service) Get(ctx context.Context, id int) (domain.Reservation, error) {
+	ctx, span := tracer.Start(ctx, "reservation.Service.Get")
+	defer span.End()
+
+	res, err := s.repo.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Reservation{}, ErrNotFound
+		}
+		return domain.Reservation{}, err
+	}
+	return res, nil
+}
+
+func (s *service) ListByProduct(ctx context.Context, productID int) ([]domain.Reservation, error) {
+	ctx, span := tracer.Start(ctx, "reservation.Service.ListByProduct")
+	defer span.End()
+
+	reservations, err := s.repo.ListByProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if reservations == nil {
+		return []domain.Reservation{}, nil
+	}
+	return reservations, nil
+}
+
+func (s *service) Availability(ctx context.Context, productID int) (domain.StockAvailability, error) {
+	ctx, span := tracer.Start(ctx, "reservation.Service.Availability")
+	defer span.End()
+
+	return s.repo.Availability(ctx, productID)
+}
+
+func (s *service) Create(ctx context.Context, productID, quantity int, ttl time.Duration) (domain.Reservation, error) {
+	ctx, span := tracer.Start(ctx, "reservation.Service.Create")
+	defer span.End()
+
+	if quantity <= 0 {
+		return domain.Reservation{}, ErrInvalidQuantity
+	}
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+	if ttl < 0 || ttl > MaxTTL {
+		return domain.Reservation{}, ErrInvalidTTL
+	}
+
+	id, err := s.repo.Reserve(ctx, domain.Reservation{
+		ProductID: productID,
+		Quantity:  quantity,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return domain.Reservation{}, err
+	}
+	return s.Get(ctx, id)
+}
+
+func (s *service) Confirm(ctx context.Context, id int) (domain.Reservation, error) {
+	ctx, span := tracer.Start(ctx, "reservation.Service.Confirm")
+	defer span.End()
+
+	res, err := s.Get(ctx, id)
+	if err != nil {
+		return domain.Reservation{}, err
+	}
+	before, err := s.repo.Confirm(ctx, id)
+	if err != nil {
+		return domain.Reservation{}, err
+	}
+	s.stock.QuantityChanged(ctx, res.ProductID, before)
+	return s.Get(ctx, id)
+}
+
+func (s *service) Release(ctx context.Context, id int) (domain.Reservation, error) {
+	ctx, span := tracer.Start(ctx, "reservation.Service.Release")
+	defer span.End()
+
+	if _, err := s.Get(ctx, id); err != nil {
+		return domain.Reservation{}, err
+	}
+	if err := s.repo.Release(ctx, id); err != nil {
+		return domain.Reservation{}, err
+	}
+	return s.Get(ctx, id)
+}
+
+func (s *service) ExpireDue(ctx context.Context) (int64, error) {
+	ctx, span := tracer.Start(ctx, "reservation.Service.ExpireDue")
+	defer span.End()
+
+	return s.repo.ExpireDue(ctx, time.Now())
+}
@@ -0,0 +1,237 @@
+package search
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"repository_class/internal/domain"
+	"repository_class/pkg/tenant"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/custom"
+	"github.com/blevesearch/bleve/v2/analysis/token/lowercase"
+	"github.com/blevesearch/bleve/v2/analysis/tokenizer/single"
+	unicodetokenizer "github.com/blevesearch/bleve/v2/analysis/tokenizer/unicode"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/search/query"
+)
+
+// Analyzers of the indexed fields: names are split into lower case words,
+// codes are kept whole in lower case.
+const (
+	nameAnalyzer = "product_name"
+	codeAnalyzer = "product_code"
+)
+
+// rebuildBatchSize is how many documents Rebuild indexes per batch.
+const rebuildBatchSize = 1000
+
+// BleveIndex keeps the products in memory in a bleve index. It does not
+// survive restarts and does not see the writes of other instances, so it is
+// rebuilt from the database at start up and then periodically.
+//
+// Words of the query match the words of a name exactly, with typos (one
+// edit from 4 runes, two from 8) and, for the last word, as a prefix. The
+// whole query also matches a code exactly or as its prefix. Exact matches
+// rank above prefixes, prefixes above typos.
+type BleveIndex struct {
+	mu    sync.RWMutex
+	index bleve.Index
+}
+
+func NewBleveIndex() (*BleveIndex, error) {
+	index, err := newBleveIndex()
+	if err != nil {
+		return nil, err
+	}
+	return &BleveIndex{index: index}, nil
+}
+
+func newBleveIndex() (bleve.Index, error) {
+	m, err := indexMapping()
+	if err != nil {
+		return nil, err
+	}
+	return bleve.NewMemOnly(m)
+}
+
+func indexMapping() (mapping.IndexMapping, error) {
+	m := bleve.NewIndexMapping()
+	if err := m.AddCustomAnalyzer(nameAnalyzer, map[string]interface{}{
+		"type":          custom.Name,
+		"tokenizer":     unicodetokenizer.Name,
+		"token_filters": []string{lowercase.Name},
+	}); err != nil {
+		return nil, err
+	}
+	if err := m.AddCustomAnalyzer(codeAnalyzer, map[string]interface{}{
+		"type":          custom.Name,
+		"tokenizer":     single.Name,
+		"token_filters": []string{lowercase.Name},
+	}); err != nil {
+		return nil, err
+	}
+
+	name := bleve.NewTextFieldMapping()
+	name.Analyzer = nameAnalyzer
+	code := bleve.NewTextFieldMapping()
+	code.Analyzer = codeAnalyzer
+
+	doc := bleve.NewDocumentStaticMapping()
+	doc.AddFieldMappingsAt("tenant_id", bleve.NewKeywordFieldMapping())
+	doc.AddFieldMappingsAt("name", name)
+	doc.AddFieldMappingsAt("code", code)
+	m.DefaultMapping = doc
+	return m, nil
+}
+
+// docID identifies a product across tenants; tenant IDs hold no '/'.
+func docID(tenantID string, id int) string {
+	return tenantID + "/" + strconv.Itoa(id)
+}
+
+func fields(doc domain.ProductDocument) map[string]interface{} {
+	return map[string]interface{}{
+		"tenant_id": doc.TenantID,
+		"name":      doc.Name,
+		"code":      doc.CodeValue,
+	}
+}
+
+func (ix *BleveIndex) Index(_ context.Context, doc domain.ProductDocument) error {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return ix.index.Index(docID(doc.TenantID, doc.ID), fields(doc))
+}
+
+func (ix *BleveIndex) Delete(_ context.Context, tenantID string, id int) error {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return ix.index.Delete(docID(tenantID, id))
+}
+
+func (ix *BleveIndex) Search(ctx context.Context, q domain.ProductSearch) ([]domain.SearchHit, error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req := bleve.NewSearchRequestOptions(productQuery(tenantID, q.Query), q.Limit, 0, false)
+
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	res, err := ix.index.SearchInContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]domain.SearchHit, 0, len(res.Hits))
+	for _, h := range res.Hits {
+		id, err := strconv.Atoi(h.ID[strings.LastIndexByte(h.ID, '/')+1:])
+		if err != nil {
+			return nil, err
+		}
+		hits = append(hits, domain.SearchHit{ID: id, Score: h.Score})
+	}
+	return hits, nil
+}
+
+// Rebuild fills a new index and swaps it in, so searches keep being served
+// meanwhile. Writes made while it runs may be missing from the new index
+// until the next Rebuild.
+func (ix *BleveIndex) Rebuild(ctx context.Context, load func(ctx context.Context) ([]domain.ProductDocument, error)) (int, error) {
+	docs, err := load(ctx)
+	if err != nil {
+		return 0, err
+	}
+	index, err := newBleveIndex()
+	if err != nil {
+		return 0, err
+	}
+	for start := 0; start < len(docs); start += rebuildBatchSize {
+		b := index.NewBatch()
+		for _, doc := range docs[start:min(start+rebuildBatchSize, len(docs))] {
+			if err := b.Index(docID(doc.TenantID, doc.ID), fields(doc)); err != nil {
+				index.Close()
+				return 0, err
+			}
+		}
+		if err := index.Batch(b); err != nil {
+			index.Close()
+			return 0, err
+		}
+	}
+
+	ix.mu.Lock()
+	old := ix.index
+	ix.index = index
+	ix.mu.Unlock()
+	return len(docs), old.Close()
+}
+
+func (ix *BleveIndex) Close() error {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	return ix.index.Close()
+}
+
+type fieldQuery interface {
+	query.FieldableQuery
+	SetBoost(b float64)
+}
+
+func on(field string, boost float64, q fieldQuery) query.Query {
+	q.SetField(field)
+	q.SetBoost(boost)
+	return q
+}
+
+// productQuery matches the products of a tenant whose name holds every word
+// of text, or whose code is text.
+func productQuery(tenantID, text string) query.Query {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var name []query.Query
+	for i, w := range words {
+		alternatives := []query.Query{on("name", 3, bleve.NewTermQuery(w))}
+		if i == len(words)-1 {
+			alternatives = append(alternatives, on("name", 2, bleve.NewPrefixQuery(w)))
+		}
+		if n := fuzziness(w); n > 0 {
+			fuzzy := bleve.NewFuzzyQuery(w)
+			fuzzy.SetFuzziness(n)
+			alternatives = append(alternatives, on("name", 1, fuzzy))
+		}
+		name = append(name, bleve.NewDisjunctionQuery(alternatives...))
+	}
+
+	code := strings.ToLower(strings.TrimSpace(text))
+	matches := []query.Query{
+		on("code", 5, bleve.NewTermQuery(code)),
+		on("code", 3, bleve.NewPrefixQuery(code)),
+	}
+	if len(name) > 0 {
+		matches = append(matches, bleve.NewConjunctionQuery(name...))
+	}
+
+	owner := bleve.NewTermQuery(tenantID)
+	owner.SetField("tenant_id")
+	return bleve.NewConjunctionQuery(owner, bleve.NewDisjunctionQuery(matches...))
+}
+
+// fuzziness is the number of typos tolerated in word: none in short words,
+// where one edit already matches too much.
+func fuzziness(word string) int {
+	switch n := utf8.RuneCountInString(word); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
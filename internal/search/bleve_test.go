@@ -0,0 +1,71 @@
+package search
+
+import (
+	"context"
+	"testing"
+
+	"repository_class/internal/domain"
+	"repository_class/pkg/tenant"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func ids(hits []domain.SearchHit) []int {
+	out := make([]int, len(hits))
+	for i, h := range hits {
+		out[i] = h.ID
+	}
+	return out
+}
+
+func TestBleveIndex(t *testing.T) {
+	ix, err := NewBleveIndex()
+	assert.NoError(t, err)
+	defer ix.Close()
+
+	ctx := context.Background()
+	acme := tenant.WithContext(ctx, "acme")
+	docs := []domain.ProductDocument{
+		{TenantID: "acme", ID: 1, Name: "Stainless Steel Bolt", CodeValue: "BLT-100"},
+		{TenantID: "acme", ID: 2, Name: "Steel Washer", CodeValue: "WSH-200"},
+		{TenantID: "acme", ID: 3, Name: "Copper Wire", CodeValue: "WIR-300"},
+		{TenantID: "globex", ID: 4, Name: "Steel Bolt", CodeValue: "BLT-100"},
+	}
+	for _, d := range docs {
+		assert.NoError(t, ix.Index(ctx, d))
+	}
+
+	search := func(q string) []int {
+		hits, err := ix.Search(acme, domain.ProductSearch{Query: q, Limit: 10})
+		assert.NoError(t, err)
+		return ids(hits)
+	}
+
+	// Only the products of the tenant are found.
+	assert.ElementsMatch(t, []int{1, 2}, search("steel"))
+	// The last word matches as a prefix, the others whole.
+	assert.Equal(t, []int{3}, search("copper wi"))
+	// Typos are tolerated in longer words.
+	assert.Equal(t, []int{3}, search("coper"))
+	assert.Equal(t, []int{1}, search("stainles bolt"))
+	// Codes match whole or by prefix, in any case.
+	assert.Equal(t, []int{2}, search("wsh-200"))
+	assert.Equal(t, []int{1}, search("BLT"))
+	// An exact word ranks above a typo.
+	assert.NoError(t, ix.Index(ctx, domain.ProductDocument{TenantID: "acme", ID: 5, Name: "Wine Rack", CodeValue: "RCK-1"}))
+	assert.Equal(t, []int{3, 5}, search("wire"))
+
+	_, err = ix.Search(ctx, domain.ProductSearch{Query: "steel", Limit: 10})
+	assert.ErrorIs(t, err, tenant.ErrMissing)
+
+	assert.NoError(t, ix.Delete(ctx, "acme", 2))
+	assert.Equal(t, []int{1}, search("steel"))
+
+	n, err := ix.Rebuild(ctx, func(context.Context) ([]domain.ProductDocument, error) {
+		return docs[2:], nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Empty(t, search("steel"))
+	assert.Equal(t, []int{3}, search("copper"))
+}
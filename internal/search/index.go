@@ -0,0 +1,47 @@
+// Package search finds products by full text over their name and
+// code_value. The products are kept in an Index, which the product
+// repository updates on every write.
+package search
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"repository_class/internal/domain"
+)
+
+// Index is a full-text index of the products of every tenant.
+type Index interface {
+	// Index adds doc to the index, or replaces the product it already holds.
+	Index(ctx context.Context, doc domain.ProductDocument) error
+	// Delete drops product id of a tenant.
+	Delete(ctx context.Context, tenantID string, id int) error
+	// Search returns the products of the tenant in ctx matching q, best
+	// match first, and fails with tenant.ErrMissing when there is none.
+	Search(ctx context.Context, q domain.ProductSearch) ([]domain.SearchHit, error)
+	// Rebuild replaces the content of the index with the documents load
+	// returns, and returns how many it indexed. Indexes the database keeps
+	// up to date do not call load.
+	Rebuild(ctx context.Context, load func(ctx context.Context) ([]domain.ProductDocument, error)) (int, error)
+	Close() error
+}
+
+// Config selects the Index built by NewIndex.
+type Config struct {
+	// Kind is one of bleve or mysql.
+	Kind string
+}
+
+// NewIndex returns the Index described by cfg. A mysql index searches the
+// products table of db.
+func NewIndex(cfg Config, db *sql.DB) (Index, error) {
+	switch cfg.Kind {
+	case "", "bleve":
+		return NewBleveIndex()
+	case "mysql":
+		return NewMySQLIndex(db), nil
+	default:
+		return nil, fmt.Errorf("unknown search index %q", cfg.Kind)
+	}
+}
@@ -0,0 +1,83 @@
+package search
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"repository_class/internal/domain"
+	"repository_class/pkg/instrument"
+	"repository_class/pkg/tenant"
+)
+
+const repositoryName = "search"
+
+// MySQLIndex searches the FULLTEXT index MySQL keeps on the name and
+// code_value of products, split in 2 character n-grams. MySQL updates it
+// with every write, so Index, Delete and Rebuild do nothing.
+//
+// Any query word, or part of a word, ranks the products holding its n-grams.
+// A misspelt word still shares most of its n-grams with the right one, which
+// tolerates typos less well than the bleve index does. Codes equal to the
+// query, then starting with it, come first.
+type MySQLIndex struct {
+	db *sql.DB
+}
+
+func NewMySQLIndex(db *sql.DB) *MySQLIndex {
+	return &MySQLIndex{db: db}
+}
+
+func (*MySQLIndex) Index(context.Context, domain.ProductDocument) error {
+	return nil
+}
+
+func (*MySQLIndex) Delete(context.Context, string, int) error {
+	return nil
+}
+
+func (*MySQLIndex) Rebuild(context.Context, func(ctx context.Context) ([]domain.ProductDocument, error)) (int, error) {
+	return 0, nil
+}
+
+func (*MySQLIndex) Close() error {
+	return nil
+}
+
+func (ix *MySQLIndex) Search(ctx context.Context, q domain.ProductSearch) (hits []domain.SearchHit, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := "SELECT id, MATCH(name, code_value) AGAINST (? IN NATURAL LANGUAGE MODE) AS score " +
+		"FROM products " +
+		"WHERE tenant_id=? AND (code_value LIKE ? OR MATCH(name, code_value) AGAINST (? IN NATURAL LANGUAGE MODE)) " +
+		"ORDER BY code_value=? DESC, code_value LIKE ? DESC, score DESC, id " +
+		"LIMIT ?;"
+	ctx, done := instrument.Query(ctx, repositoryName, "Search", query)
+	defer func() { done(err) }()
+
+	prefix := escapeLike(q.Query) + "%"
+	rows, err := ix.db.QueryContext(ctx, query, q.Query, tenantID, prefix, q.Query, q.Query, prefix, q.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var h domain.SearchHit
+		if err := rows.Scan(&h.ID, &h.Score); err != nil {
+			return nil, err
+		}
+		hits = append(hits, h)
+	}
+
+	return hits, rows.Err()
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// escapeLike makes s match itself in a LIKE pattern.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
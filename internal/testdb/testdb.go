@@ -0,0 +1,85 @@
+// Package testdb opens the test database and stores the rows repository
+// tests start from. Unless opened with OpenCommitted, every connection runs
+// in a transaction that is rolled back when the test ends, so tests never see
+// each other's rows.
+package testdb
+
+import (
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	"repository_class/internal/domain"
+
+	"github.com/DATA-DOG/go-txdb"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/require"
+)
+
+// DSN is the database the tests run against.
+const DSN = "root@/my_db?allowNativePasswords=false&checkConnLiveness=false&parseTime=true&maxAllowedPacket=0"
+
+var register sync.Once
+
+// Open returns a connection to the test database whose writes are rolled
+// back when t ends.
+func Open(t *testing.T) *sql.DB {
+	t.Helper()
+	register.Do(func() {
+		txdb.Register("txdb", "mysql", DSN)
+	})
+	db, err := sql.Open("txdb", t.Name())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// OpenCommitted returns connections to the test database that, unlike those
+// of Open, each run their own transactions and commit them. Tests of locking
+// need it: the connections of Open share one transaction, so they never wait
+// for each other. Such tests delete the rows they store.
+func OpenCommitted(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("mysql", DSN)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// Warehouse stores a warehouse of the tenant holding up to capacity units,
+// none when it is 0, and returns its id.
+func Warehouse(t *testing.T, db *sql.DB, tenantID string, capacity int) int {
+	t.Helper()
+	res, err := db.Exec("INSERT INTO warehouses (tenant_id, name, adress, telephone, capacity) VALUES (?, 'x', 'x', 'x', ?);", tenantID, capacity)
+	require.NoError(t, err)
+	id, err := res.LastInsertId()
+	require.NoError(t, err)
+	return int(id)
+}
+
+// Product stores an empty product of the tenant in the warehouse and returns
+// its id.
+func Product(t *testing.T, db *sql.DB, tenantID string, warehouseID int, code string) int {
+	t.Helper()
+	res, err := db.Exec("INSERT INTO products (tenant_id, name, code_value, expiration, id_warehouse) VALUES (?, 'widget', ?, ?, ?);",
+		tenantID, code, time.Now().Add(24*time.Hour), warehouseID)
+	require.NoError(t, err)
+	id, err := res.LastInsertId()
+	require.NoError(t, err)
+	return int(id)
+}
+
+// Lot stores the lot with its receipt in the stock ledger, and returns its
+// id. The quantity of the product row is left as it is.
+func Lot(t *testing.T, db *sql.DB, l domain.Lot) int {
+	t.Helper()
+	res, err := db.Exec("INSERT INTO lots (product_id, lot_code, quantity, unit_cost, expiration) VALUES (?, ?, ?, ?, ?);",
+		l.ProductID, l.LotCode, l.Quantity, l.UnitCost, l.Expiration)
+	require.NoError(t, err)
+	id, err := res.LastInsertId()
+	require.NoError(t, err)
+	_, err = db.Exec("INSERT INTO stock_movements (product_id, lot_id, quantity) VALUES (?, ?, ?);", l.ProductID, id, l.Quantity)
+	require.NoError(t, err)
+	return int(id)
+}
@@ -0,0 +1,92 @@
+package warehouse
+
+import (
+	"context"
+
+	"repository_class/internal/domain"
+	"repository_class/pkg/cache"
+	"repository_class/pkg/tenant"
+)
+
+// Key returns the cache key of warehouse id of a tenant.
+func Key(tenantID string, id int) string {
+	return cache.Key("warehouse", tenantID, id)
+}
+
+// ReportKey returns the cache key of the product report of warehouse id of
+// a tenant. Writes to products drop it, since they change the count.
+func ReportKey(tenantID string, id int) string {
+	return cache.Key("warehouse-report", tenantID, id)
+}
+
+type cachedRepository struct {
+	Repository
+	cache *cache.Cache
+}
+
+// NewCachedRepository wraps repo so Get and ReportProducts are served from
+// c, dropping the entries of a warehouse when it is updated or deleted.
+// The other methods go straight to repo.
+func NewCachedRepository(repo Repository, c *cache.Cache) Repository {
+	return &cachedRepository{Repository: repo, cache: c}
+}
+
+func (r *cachedRepository) Get(ctx context.Context, id int) (domain.Warehouse, error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return domain.Warehouse{}, err
+	}
+	key := Key(tenantID, id)
+
+	var w domain.Warehouse
+	if r.cache.Get(ctx, key, &w) {
+		return w, nil
+	}
+	w, err = r.Repository.Get(ctx, id)
+	// A missing warehouse reads as the zero value; it is not kept so the
+	// warehouse is seen as soon as it is created.
+	if err != nil || w.ID == 0 {
+		return w, err
+	}
+	r.cache.Set(ctx, key, w)
+	return w, nil
+}
+
+func (r *cachedRepository) ReportProducts(ctx context.Context, id int) (domain.WarehouseReport, error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return domain.WarehouseReport{}, err
+	}
+	key := ReportKey(tenantID, id)
+
+	var report domain.WarehouseReport
+	if r.cache.Get(ctx, key, &report) {
+		return report, nil
+	}
+	report, err = r.Repository.ReportProducts(ctx, id)
+	if err != nil {
+		return report, err
+	}
+	r.cache.Set(ctx, key, report)
+	return report, nil
+}
+
+func (r *cachedRepository) Update(ctx context.Context, w domain.Warehouse) error {
+	err := r.Repository.Update(ctx, w)
+	r.invalidate(ctx, w.ID)
+	return err
+}
+
+func (r *cachedRepository) Delete(ctx context.Context, id int) error {
+	err := r.Repository.Delete(ctx, id)
+	r.invalidate(ctx, id)
+	return err
+}
+
+// invalidate drops the entries of warehouse id whether the write succeeded
+// or not, since a failed write may still have reached the database.
+func (r *cachedRepository) invalidate(ctx context.Context, id int) {
+	if tenantID, ok := tenant.FromContext(ctx); ok {
+		r.cache.Delete(ctx, Key(tenantID, id), ReportKey(tenantID, id))
+	}
+}
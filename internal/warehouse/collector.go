@@ -0,0 +1,76 @@
+package warehouse
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const collectTimeout = 5 * time.Second
+
+var (
+	productsDesc = prometheus.NewDesc(
+		"inventory_products",
+		"Number of products stored across all warehouses.",
+		nil, nil,
+	)
+	warehouseProductsDesc = prometheus.NewDesc(
+		"inventory_warehouse_products",
+		"Number of products stored in a warehouse.",
+		[]string{"tenant", "warehouse_id", "warehouse"}, nil,
+	)
+	warehouseUnitsDesc = prometheus.NewDesc(
+		"inventory_warehouse_units",
+		"Total units stored in a warehouse.",
+		[]string{"tenant", "warehouse_id", "warehouse"}, nil,
+	)
+	warehouseUtilisationDesc = prometheus.NewDesc(
+		"inventory_warehouse_capacity_utilisation_ratio",
+		"Units stored in a warehouse divided by its capacity.",
+		[]string{"tenant", "warehouse_id", "warehouse"}, nil,
+	)
+)
+
+type collector struct {
+	repo Repository
+}
+
+// NewCollector returns a Prometheus collector exposing stock gauges read
+// from repo on every scrape.
+func NewCollector(repo Repository) prometheus.Collector {
+	return &collector{repo: repo}
+}
+
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- productsDesc
+	ch <- warehouseProductsDesc
+	ch <- warehouseUnitsDesc
+	ch <- warehouseUtilisationDesc
+}
+
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	stock, err := c.repo.StockLevels(ctx)
+	if err != nil {
+		slog.Default().Error("collect warehouse stock levels", "error", err)
+		return
+	}
+
+	var products int
+	for _, s := range stock {
+		id := strconv.Itoa(s.WarehouseID)
+		products += s.ProductCount
+
+		ch <- prometheus.MustNewConstMetric(warehouseProductsDesc, prometheus.GaugeValue, float64(s.ProductCount), s.TenantID, id, s.WarehouseName)
+		ch <- prometheus.MustNewConstMetric(warehouseUnitsDesc, prometheus.GaugeValue, float64(s.Units), s.TenantID, id, s.WarehouseName)
+		if s.Capacity > 0 {
+			ch <- prometheus.MustNewConstMetric(warehouseUtilisationDesc, prometheus.GaugeValue, float64(s.Units)/float64(s.Capacity), s.TenantID, id, s.WarehouseName)
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(productsDesc, prometheus.GaugeValue, float64(products))
+}
@@ -3,20 +3,54 @@ package warehouse
 import (
 	"context"
 	"database/sql"
-	"log"
+	"strings"
+	"time"
 
 	"repository_class/internal/domain"
+	"repository_class/pkg/instrument"
+	"repository_class/pkg/tenant"
+	"repository_class/pkg/txn"
+
+	"github.com/shopspring/decimal"
 )
 
-// Repository encapsulates the storage of a warehouse.
+// Repository encapsulates the storage of a warehouse. Every method but
+// StockLevels only sees the warehouses and products of the tenant in its
+// context and fails with tenant.ErrMissing when there is none. Save and
+// Update fail with ErrWarehouseRegistered when the tenant already has a
+// warehouse with the code.
 type Repository interface {
 	GetAll(ctx context.Context) ([]domain.Warehouse, error)
+	Find(ctx context.Context, f domain.WarehouseFilter) ([]domain.Warehouse, error)
 	Get(ctx context.Context, id int) (domain.Warehouse, error)
-	Exists(ctx context.Context, warehouseCode string) bool
+	Exists(ctx context.Context, warehouseCode string) (bool, error)
 	Save(ctx context.Context, w domain.Warehouse) (int, error)
 	Update(ctx context.Context, w domain.Warehouse) error
 	Delete(ctx context.Context, id int) error
 	ReportProducts(ctx context.Context, id int) (domain.WarehouseReport, error)
+	// StockLevels summarises the warehouses of every tenant, for metrics.
+	StockLevels(ctx context.Context) ([]domain.WarehouseStock, error)
+	Products(ctx context.Context, id int, f domain.WarehouseProductsFilter) ([]domain.Product, error)
+	ProductTotals(ctx context.Context, id int, f domain.WarehouseProductsFilter) (domain.ProductTotals, error)
+	// StockValues sums quantity times price of the matching products per
+	// currency, since amounts in different currencies cannot be added in SQL.
+	StockValues(ctx context.Context, id int, f domain.WarehouseProductsFilter) (map[string]decimal.Decimal, error)
+}
+
+const repositoryName = "warehouse"
+
+// warehouseColumns reads a missing code as an empty one.
+const warehouseColumns = "id, COALESCE(warehouse_code, ''), name, adress, telephone, capacity"
+
+// productSortColumns maps the sort keys accepted in
+// WarehouseProductsFilter.Sort to columns.
+var productSortColumns = map[string]string{
+	"id":         "id",
+	"name":       "name",
+	"quantity":   "quantity",
+	"code_value": "code_value",
+	"expiration": "expiration",
+	"price":      "price",
 }
 
 type repository struct {
@@ -29,16 +63,20 @@ func NewRepository(db *sql.DB) Repository {
 	}
 }
 
-func (r *repository) ReportProducts(ctx context.Context, id int) (domain.WarehouseReport, error) {
-	query := "SELECT w.name AS warehouseName, count(p.id_warehouse) AS totalProducts FROM warehouses w " +
-		"INNER JOIN products p ON p.id_warehouse = w.id " +
-		"WHERE w.id = ? GROUP BY w.name;"
-
-	row := r.db.QueryRow(query, id)
+func (r *repository) ReportProducts(ctx context.Context, id int) (w domain.WarehouseReport, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return domain.WarehouseReport{}, err
+	}
+	query := "SELECT w.name AS warehouseName, count(p.id) AS totalProducts FROM warehouses w " +
+		"LEFT JOIN products p ON p.id_warehouse = w.id " +
+		"WHERE w.id = ? AND w.tenant_id = ? GROUP BY w.name;"
+	ctx, done := instrument.Query(ctx, repositoryName, "ReportProducts", query)
+	defer func() { done(err) }()
 
-	w := domain.WarehouseReport{}
+	row := r.db.QueryRowContext(ctx, query, id, tenantID)
 
-	err := row.Scan(&w.WarehouseName, &w.ProductCount)
+	err = row.Scan(&w.WarehouseName, &w.ProductCount)
 	if err != nil {
 		return domain.WarehouseReport{}, err
 	}
@@ -46,60 +84,271 @@ func (r *repository) ReportProducts(ctx context.Context, id int) (domain.Warehou
 	return w, nil
 }
 
-func (r *repository) GetAll(ctx context.Context) ([]domain.Warehouse, error) {
-	query := "SELECT * FROM warehouses"
-	rows, err := r.db.Query(query)
+func (r *repository) StockLevels(ctx context.Context) (stock []domain.WarehouseStock, err error) {
+	query := "SELECT w.tenant_id, w.id, w.name, w.capacity, count(p.id), COALESCE(SUM(p.quantity), 0) FROM warehouses w " +
+		"LEFT JOIN products p ON p.id_warehouse = w.id " +
+		"GROUP BY w.tenant_id, w.id, w.name, w.capacity;"
+	ctx, done := instrument.Query(ctx, repositoryName, "StockLevels", query)
+	defer func() { done(err) }()
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		s := domain.WarehouseStock{}
+		if err := rows.Scan(&s.TenantID, &s.WarehouseID, &s.WarehouseName, &s.Capacity, &s.ProductCount, &s.Units); err != nil {
+			return nil, err
+		}
+		stock = append(stock, s)
+	}
+
+	return stock, rows.Err()
+}
+
+// productsWhere builds the condition shared by Products, ProductTotals and
+// StockValues.
+func productsWhere(tenantID string, id int, f domain.WarehouseProductsFilter, now time.Time) (string, []interface{}) {
+	where := []string{"tenant_id=?", "id_warehouse=?"}
+	args := []interface{}{tenantID, id}
+
+	if f.Published != nil {
+		where = append(where, "is_published=?")
+		args = append(args, *f.Published)
+	}
+	if f.Expired != nil {
+		if *f.Expired {
+			where = append(where, "expiration<?")
+		} else {
+			where = append(where, "expiration>=?")
+		}
+		args = append(args, now)
+	}
+
+	return strings.Join(where, " AND "), args
+}
+
+func (r *repository) Products(ctx context.Context, id int, f domain.WarehouseProductsFilter) (products []domain.Product, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	where, args := productsWhere(tenantID, id, f, time.Now())
+
+	column, ok := productSortColumns[f.Sort]
+	if !ok {
+		return nil, ErrInvalidSort
+	}
+	order := "ASC"
+	if f.Desc {
+		order = "DESC"
+	}
+
+	query := "SELECT id, name, quantity, code_value, is_published, expiration, price, currency, id_warehouse, category_id FROM products " +
+		"WHERE " + where + " ORDER BY " + column + " " + order + ", id LIMIT ? OFFSET ?;"
+	args = append(args, f.Limit, f.Offset)
+
+	ctx, done := instrument.Query(ctx, repositoryName, "Products", query)
+	defer func() { done(err) }()
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		p := domain.Product{}
+		if err := rows.Scan(&p.ID, &p.Name, &p.Quantity, &p.CodeValue, &p.IsPublished, &p.Expiration, &p.Price, &p.Currency, &p.IdWarehouse, &p.CategoryID); err != nil {
+			return nil, err
+		}
+		products = append(products, p)
+	}
+
+	return products, rows.Err()
+}
+
+func (r *repository) ProductTotals(ctx context.Context, id int, f domain.WarehouseProductsFilter) (t domain.ProductTotals, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return domain.ProductTotals{}, err
+	}
+	now := time.Now()
+	where, args := productsWhere(tenantID, id, f, now)
+
+	// A product's expiration is the earliest of its lots in stock, so
+	// expiration<now marks the products holding an expired lot; the units
+	// of those lots are summed from the lots themselves.
+	query := "SELECT count(*), COALESCE(SUM(quantity), 0), COALESCE(SUM(is_published), 0), " +
+		"COALESCE(SUM(expiration<?), 0), " +
+		"(SELECT COALESCE(SUM(l.quantity), 0) FROM lots l WHERE l.expiration<? AND l.product_id IN (SELECT id FROM products WHERE " + where + ")) " +
+		"FROM products WHERE " + where + ";"
+	args = append(append([]interface{}{now, now}, args...), args...)
+
+	ctx, done := instrument.Query(ctx, repositoryName, "ProductTotals", query)
+	defer func() { done(err) }()
+
+	row := r.db.QueryRowContext(ctx, query, args...)
+	err = row.Scan(&t.Products, &t.Units, &t.Published, &t.Expired, &t.ExpiredUnits)
+	if err != nil {
+		return domain.ProductTotals{}, err
+	}
+
+	return t, nil
+}
+
+func (r *repository) StockValues(ctx context.Context, id int, f domain.WarehouseProductsFilter) (values map[string]decimal.Decimal, err error) {
+	tenantID, err := tenant.Require(ctx)
 	if err != nil {
 		return nil, err
 	}
+	where, args := productsWhere(tenantID, id, f, time.Now())
+	query := "SELECT currency, SUM(quantity*price) FROM products WHERE " + where + " GROUP BY currency;"
+	ctx, done := instrument.Query(ctx, repositoryName, "StockValues", query)
+	defer func() { done(err) }()
 
-	var warehouses []domain.Warehouse
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values = make(map[string]decimal.Decimal)
+	for rows.Next() {
+		var (
+			currency string
+			value    decimal.Decimal
+		)
+		if err := rows.Scan(&currency, &value); err != nil {
+			return nil, err
+		}
+		values[currency] = value
+	}
+
+	return values, rows.Err()
+}
+
+func (r *repository) GetAll(ctx context.Context) (warehouses []domain.Warehouse, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := "SELECT " + warehouseColumns + " FROM warehouses WHERE tenant_id=?"
+	ctx, done := instrument.Query(ctx, repositoryName, "GetAll", query)
+	defer func() { done(err) }()
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		w := domain.Warehouse{}
+		if err := rows.Scan(&w.ID, &w.WarehouseCode, &w.Name, &w.Address, &w.Telephone, &w.Capacity); err != nil {
+			return nil, err
+		}
+		warehouses = append(warehouses, w)
+	}
+
+	return warehouses, rows.Err()
+}
+
+func (r *repository) Find(ctx context.Context, f domain.WarehouseFilter) (warehouses []domain.Warehouse, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	args := []interface{}{tenantID}
+
+	query := "SELECT " + warehouseColumns + " FROM warehouses WHERE tenant_id=?"
+	if len(f.IDs) > 0 {
+		query += " AND id IN (" + strings.TrimSuffix(strings.Repeat("?,", len(f.IDs)), ",") + ")"
+		for _, id := range f.IDs {
+			args = append(args, id)
+		}
+	}
+	query += " ORDER BY id"
+	if f.Limit > 0 {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, f.Limit, f.Offset)
+	}
+
+	ctx, done := instrument.Query(ctx, repositoryName, "Find", query)
+	defer func() { done(err) }()
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
 	for rows.Next() {
 		w := domain.Warehouse{}
-		_ = rows.Scan(&w.ID, &w.Name, &w.Address, &w.Telephone, &w.Capacity)
+		if err := rows.Scan(&w.ID, &w.WarehouseCode, &w.Name, &w.Address, &w.Telephone, &w.Capacity); err != nil {
+			return nil, err
+		}
 		warehouses = append(warehouses, w)
 	}
 
-	return warehouses, nil
+	return warehouses, rows.Err()
 }
 
-func (r *repository) Get(ctx context.Context, id int) (domain.Warehouse, error) {
-	query := "SELECT * FROM warehouses WHERE id=?;"
+func (r *repository) Get(ctx context.Context, id int) (w domain.Warehouse, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return domain.Warehouse{}, err
+	}
+	query := "SELECT " + warehouseColumns + " FROM warehouses WHERE id=? AND tenant_id=?;"
 	//query := "SELECT SLEEP(30) FROM warehouses WHERE 0 < ?;" //query Timeout
-	row, err := r.db.QueryContext(ctx, query, id)
+	ctx, done := instrument.Query(ctx, repositoryName, "Get", query)
+	defer func() { done(err) }()
+
+	row, err := r.db.QueryContext(ctx, query, id, tenantID)
 	if err != nil {
-		log.Fatal(err)
 		return domain.Warehouse{}, err
 	}
-	w := domain.Warehouse{}
+	defer row.Close()
 
 	for row.Next() {
-		if err := row.Scan(&w.ID, &w.Name, &w.Address, &w.Telephone, &w.Capacity); err != nil {
-			log.Fatal(err)
+		if err := row.Scan(&w.ID, &w.WarehouseCode, &w.Name, &w.Address, &w.Telephone, &w.Capacity); err != nil {
 			return domain.Warehouse{}, err
 		}
 	}
 
-	return w, nil
+	return w, row.Err()
 }
 
-func (r *repository) Exists(ctx context.Context, warehouseCode string) bool {
-	query := "SELECT warehouse_code FROM warehouses WHERE warehouse_code=?;"
-	row := r.db.QueryRow(query, warehouseCode)
-	err := row.Scan(&warehouseCode)
-	return err == nil
+func (r *repository) Exists(ctx context.Context, warehouseCode string) (exists bool, err error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return false, err
+	}
+	query := "SELECT EXISTS(SELECT 1 FROM warehouses WHERE warehouse_code=? AND tenant_id=?);"
+	ctx, done := instrument.Query(ctx, repositoryName, "Exists", query)
+	defer func() { done(err) }()
+
+	err = r.db.QueryRowContext(ctx, query, warehouseCode, tenantID).Scan(&exists)
+	return exists, err
 }
 
-func (r *repository) Save(ctx context.Context, w domain.Warehouse) (int, error) {
-	query := "INSERT INTO warehouses (name, adress, telephone, capacity) VALUES (?, ?, ?, ?)"
-	stmt, err := r.db.Prepare(query)
+func (r *repository) Save(ctx context.Context, w domain.Warehouse) (_ int, err error) {
+	tenantID, err := tenant.Require(ctx)
 	if err != nil {
 		return 0, err
 	}
+	query := "INSERT INTO warehouses (tenant_id, warehouse_code, name, adress, telephone, capacity) VALUES (?, NULLIF(?, ''), ?, ?, ?, ?)"
+	ctx, done := instrument.Query(ctx, repositoryName, "Save", query)
+	defer func() { done(err) }()
 
-	res, err := stmt.Exec(&w.Name, &w.Address, &w.Telephone, &w.Capacity)
+	res, err := r.db.ExecContext(ctx, query, tenantID, &w.WarehouseCode, &w.Name, &w.Address, &w.Telephone, &w.Capacity)
 	if err != nil {
+		// A concurrent save of the same code passes the service check too.
+		if txn.IsDuplicate(err) {
+			return 0, ErrWarehouseRegistered
+		}
 		return 0, err
 	}
 
@@ -111,34 +360,32 @@ func (r *repository) Save(ctx context.Context, w domain.Warehouse) (int, error)
 	return int(id), nil
 }
 
-func (r *repository) Update(ctx context.Context, w domain.Warehouse) error {
-	query := "UPDATE warehouses SET name=?, adress=?, telephone=?, capacity=? WHERE id=?"
-	stmt, err := r.db.Prepare(query)
-	if err != nil {
-		return err
-	}
-
-	res, err := stmt.Exec(&w.Name, &w.Address, &w.Telephone, &w.Capacity, &w.ID)
+func (r *repository) Update(ctx context.Context, w domain.Warehouse) (err error) {
+	tenantID, err := tenant.Require(ctx)
 	if err != nil {
 		return err
 	}
+	query := "UPDATE warehouses SET warehouse_code=NULLIF(?, ''), name=?, adress=?, telephone=?, capacity=? WHERE id=? AND tenant_id=?"
+	ctx, done := instrument.Query(ctx, repositoryName, "Update", query)
+	defer func() { done(err) }()
 
-	_, err = res.RowsAffected()
-	if err != nil {
-		return err
+	_, err = r.db.ExecContext(ctx, query, &w.WarehouseCode, &w.Name, &w.Address, &w.Telephone, &w.Capacity, &w.ID, tenantID)
+	if txn.IsDuplicate(err) {
+		return ErrWarehouseRegistered
 	}
-
-	return nil
+	return err
 }
 
-func (r *repository) Delete(ctx context.Context, id int) error {
-	query := "DELETE FROM warehouses WHERE id=?"
-	stmt, err := r.db.Prepare(query)
+func (r *repository) Delete(ctx context.Context, id int) (err error) {
+	tenantID, err := tenant.Require(ctx)
 	if err != nil {
 		return err
 	}
+	query := "DELETE FROM warehouses WHERE id=? AND tenant_id=?"
+	ctx, done := instrument.Query(ctx, repositoryName, "Delete", query)
+	defer func() { done(err) }()
 
-	res, err := stmt.Exec(id)
+	res, err := r.db.ExecContext(ctx, query, id, tenantID)
 	if err != nil {
 		return err
 	}
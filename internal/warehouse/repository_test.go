@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"repository_class/internal/domain"
+	"repository_class/pkg/tenant"
 	"testing"
 	"time"
 
@@ -23,7 +24,7 @@ func TestGet(t *testing.T) {
 
 	rp := NewRepository(db)
 
-	w, err := rp.GetAll(context.Background())
+	w, err := rp.GetAll(tenant.WithContext(context.Background(), "default"))
 
 	assert.NoError(t, err)
 	assert.NotEmpty(t, w)
@@ -44,7 +45,7 @@ func TestGetOne(t *testing.T) {
 
 	rp := NewRepository(db)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(tenant.WithContext(context.Background(), "default"), 5*time.Second)
 	defer cancel()
 
 	w, err := rp.Get(ctx, id)
@@ -69,7 +70,7 @@ func TestGetOneTimeOut(t *testing.T) {
 
 	rp := NewRepository(db)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(tenant.WithContext(context.Background(), "default"), 5*time.Second)
 	defer cancel()
 
 	w, err := rp.Get(ctx, id)
@@ -78,3 +79,84 @@ func TestGetOneTimeOut(t *testing.T) {
 	assert.NotEmpty(t, w)
 	assert.Equal(t, wr.Name, w.Name)
 }
+
+func TestTenantIsolation(t *testing.T) {
+	db, err := sql.Open("txdb", "identifier")
+	assert.NoError(t, err)
+	defer db.Close()
+
+	rp := NewRepository(db)
+	acme := tenant.WithContext(context.Background(), "acme")
+	globex := tenant.WithContext(context.Background(), "globex")
+
+	id, err := rp.Save(acme, domain.Warehouse{Name: "acme", Address: "x", Telephone: "x", Capacity: 10})
+	assert.NoError(t, err)
+
+	w, err := rp.Get(globex, id)
+	assert.NoError(t, err)
+	assert.Empty(t, w)
+
+	all, err := rp.GetAll(globex)
+	assert.NoError(t, err)
+	for _, w := range all {
+		assert.NotEqual(t, id, w.ID)
+	}
+
+	found, err := rp.Find(globex, domain.WarehouseFilter{IDs: []int{id}})
+	assert.NoError(t, err)
+	assert.Empty(t, found)
+
+	assert.NoError(t, rp.Update(globex, domain.Warehouse{ID: id, Name: "globex"}))
+	assert.ErrorIs(t, rp.Delete(globex, id), ErrNotFound)
+
+	w, err = rp.Get(acme, id)
+	assert.NoError(t, err)
+	assert.Equal(t, "acme", w.Name)
+}
+
+func TestWithoutTenant(t *testing.T) {
+	db, err := sql.Open("txdb", "identifier")
+	assert.NoError(t, err)
+	defer db.Close()
+
+	rp := NewRepository(db)
+
+	_, err = rp.Get(context.Background(), 2)
+
+	assert.ErrorIs(t, err, tenant.ErrMissing)
+}
+
+func TestWarehouseCodeUniquePerTenant(t *testing.T) {
+	db, err := sql.Open("txdb", "identifier")
+	assert.NoError(t, err)
+	defer db.Close()
+
+	rp := NewRepository(db)
+	acme := tenant.WithContext(context.Background(), "acme")
+	globex := tenant.WithContext(context.Background(), "globex")
+
+	w := domain.Warehouse{WarehouseCode: "WH-1", Name: "x", Address: "x", Telephone: "x", Capacity: 10}
+	_, err = rp.Save(acme, w)
+	assert.NoError(t, err)
+	id, err := rp.Save(globex, w)
+	assert.NoError(t, err)
+	exists, err := rp.Exists(globex, "WH-1")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	_, err = rp.Save(globex, w)
+	assert.ErrorIs(t, err, ErrWarehouseRegistered)
+
+	// Warehouses without a code never collide.
+	w.WarehouseCode = ""
+	_, err = rp.Save(globex, w)
+	assert.NoError(t, err)
+	other, err := rp.Save(globex, w)
+	assert.NoError(t, err)
+	w.ID, w.WarehouseCode = other, "WH-1"
+	assert.ErrorIs(t, rp.Update(globex, w), ErrWarehouseRegistered)
+
+	got, err := rp.Get(globex, id)
+	assert.NoError(t, err)
+	assert.Equal(t, "WH-1", got.WarehouseCode)
+}
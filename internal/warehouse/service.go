@@ -2,9 +2,13 @@ package warehouse
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 
 	"repository_class/internal/domain"
+	"repository_class/internal/exchange"
+
+	"go.opentelemetry.io/otel"
 )
 
 // Errors
@@ -13,35 +17,115 @@ var (
 	ErrWarehouseRegistered = errors.New("warehouse number is already registered")
 	ErrInvalidStruct       = errors.New("invalid input structure for section")
 	ErrInvalidId           = errors.New("invalid id")
+	ErrInvalidSort         = errors.New("invalid sort field")
 )
 
 type Service interface {
 	//read
 	GetAll(ctx context.Context) ([]domain.Warehouse, error)
+	List(ctx context.Context, f domain.WarehouseFilter) ([]domain.Warehouse, error)
 	Get(ctx context.Context, id int) (domain.Warehouse, error)
 	Create(ctx context.Context, w domain.Warehouse) (domain.Warehouse, error)
 	Update(ctx context.Context, w domain.Warehouse, id int) (domain.Warehouse, error)
 	Delete(ctx context.Context, id int) error
 	ReportProducts(ctx context.Context, id int) (domain.WarehouseReport, error)
+	// Products returns one page of the products stored in the warehouse and
+	// totals over every product matching the filter. Amounts in a currency
+	// other than the one asked for are converted with the current rates.
+	Products(ctx context.Context, id int, f domain.WarehouseProductsFilter) (domain.WarehouseProducts, error)
 }
 
+var tracer = otel.Tracer("repository_class/internal/warehouse")
+
 type service struct {
-	repo Repository
+	repo  Repository
+	rates exchange.Service
 }
 
 func (s *service) ReportProducts(ctx context.Context, id int) (domain.WarehouseReport, error) {
+	ctx, span := tracer.Start(ctx, "warehouse.Service.ReportProducts")
+	defer span.End()
+
 	warehouse, err := s.repo.ReportProducts(ctx, id)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.WarehouseReport{}, ErrNotFound
+		}
 		return domain.WarehouseReport{}, err
 	}
-	if warehouse == (domain.WarehouseReport{}) {
-		warehouse = domain.WarehouseReport{}
-	}
 	return warehouse, nil
 }
 
-func NewService(repo *Repository) Service {
-	return &service{repo: *repo}
+func (s *service) Products(ctx context.Context, id int, f domain.WarehouseProductsFilter) (domain.WarehouseProducts, error) {
+	ctx, span := tracer.Start(ctx, "warehouse.Service.Products")
+	defer span.End()
+
+	w, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return domain.WarehouseProducts{}, err
+	}
+	if w == (domain.Warehouse{}) {
+		return domain.WarehouseProducts{}, ErrNotFound
+	}
+
+	if f.Sort == "" {
+		f.Sort = "id"
+	}
+	target := s.rates.Base()
+	if f.Currency != "" {
+		if target, err = exchange.ParseCurrency(f.Currency); err != nil {
+			return domain.WarehouseProducts{}, err
+		}
+	}
+	rates, err := s.rates.Rates(ctx)
+	if err != nil {
+		return domain.WarehouseProducts{}, err
+	}
+
+	products, err := s.repo.Products(ctx, id, f)
+	if err != nil {
+		return domain.WarehouseProducts{}, err
+	}
+	if products == nil {
+		products = []domain.Product{}
+	}
+	if f.Currency != "" {
+		for i, p := range products {
+			if products[i].Price, err = rates.Convert(p.Price, p.Currency, target); err != nil {
+				return domain.WarehouseProducts{}, err
+			}
+			products[i].Currency = target
+		}
+	}
+
+	totals, err := s.repo.ProductTotals(ctx, id, f)
+	if err != nil {
+		return domain.WarehouseProducts{}, err
+	}
+	values, err := s.repo.StockValues(ctx, id, f)
+	if err != nil {
+		return domain.WarehouseProducts{}, err
+	}
+	totals.Currency = target
+	for currency, value := range values {
+		converted, err := rates.Convert(value, currency, target)
+		if err != nil {
+			return domain.WarehouseProducts{}, err
+		}
+		totals.StockValue = totals.StockValue.Add(converted)
+	}
+
+	return domain.WarehouseProducts{
+		WarehouseID: id,
+		Products:    products,
+		Totals:      totals,
+		Limit:       f.Limit,
+		Offset:      f.Offset,
+	}, nil
+}
+
+func NewService(repo *Repository, rates exchange.Service) Service {
+	return &service{repo: *repo, rates: rates}
 }
 
 /*
@@ -59,6 +143,9 @@ func NewService(repo *Repository) Service {
 	}
 */
 func (s *service) GetAll(ctx context.Context) ([]domain.Warehouse, error) {
+	ctx, span := tracer.Start(ctx, "warehouse.Service.GetAll")
+	defer span.End()
+
 	warehouse, err := s.repo.GetAll(ctx)
 	if err != nil {
 		return nil, err
@@ -69,7 +156,24 @@ func (s *service) GetAll(ctx context.Context) ([]domain.Warehouse, error) {
 	return warehouse, nil
 }
 
+func (s *service) List(ctx context.Context, f domain.WarehouseFilter) ([]domain.Warehouse, error) {
+	ctx, span := tracer.Start(ctx, "warehouse.Service.List")
+	defer span.End()
+
+	warehouses, err := s.repo.Find(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	if warehouses == nil {
+		warehouses = []domain.Warehouse{}
+	}
+	return warehouses, nil
+}
+
 func (s *service) Get(ctx context.Context, id int) (domain.Warehouse, error) {
+	ctx, span := tracer.Start(ctx, "warehouse.Service.Get")
+	defer span.End()
+
 	warehouse, err := s.repo.Get(ctx, id)
 	if err != nil {
 		return domain.Warehouse{}, err
@@ -81,10 +185,18 @@ func (s *service) Get(ctx context.Context, id int) (domain.Warehouse, error) {
 }
 
 func (s *service) Create(ctx context.Context, w domain.Warehouse) (domain.Warehouse, error) {
-	// exist := s.repo.Exists(ctx, w.WarehouseCode)
-	// if exist {
-	// 	return domain.Warehouse{}, ErrWarehouseRegistered
-	// }
+	ctx, span := tracer.Start(ctx, "warehouse.Service.Create")
+	defer span.End()
+
+	if w.WarehouseCode != "" {
+		exists, err := s.repo.Exists(ctx, w.WarehouseCode)
+		if err != nil {
+			return domain.Warehouse{}, err
+		}
+		if exists {
+			return domain.Warehouse{}, ErrWarehouseRegistered
+		}
+	}
 	id, err := s.repo.Save(ctx, w)
 
 	if err != nil {
@@ -98,21 +210,33 @@ func (s *service) Create(ctx context.Context, w domain.Warehouse) (domain.Wareho
 }
 
 func (s *service) Update(ctx context.Context, w domain.Warehouse, id int) (domain.Warehouse, error) {
-	_, err := s.repo.Get(ctx, id)
+	ctx, span := tracer.Start(ctx, "warehouse.Service.Update")
+	defer span.End()
+
+	current, err := s.repo.Get(ctx, id)
 	if err != nil {
 		return domain.Warehouse{}, err
 	}
-	// if s.repo.Exists(ctx, w.WarehouseCode) {
-	// 	return domain.Warehouse{}, ErrWarehouseRegistered
-	// }
-	errs := s.repo.Update(ctx, w)
-	if errs != nil {
+	// Keeping its own code is not a conflict.
+	if w.WarehouseCode != "" && w.WarehouseCode != current.WarehouseCode {
+		exists, err := s.repo.Exists(ctx, w.WarehouseCode)
+		if err != nil {
+			return domain.Warehouse{}, err
+		}
+		if exists {
+			return domain.Warehouse{}, ErrWarehouseRegistered
+		}
+	}
+	if err := s.repo.Update(ctx, w); err != nil {
 		return domain.Warehouse{}, err
 	}
 	return w, nil
 }
 
 func (s *service) Delete(ctx context.Context, id int) error {
+	ctx, span := tracer.Start(ctx, "warehouse.Service.Delete")
+	defer span.End()
+
 	err := s.repo.Delete(ctx, id)
 	if err != nil {
 		return err
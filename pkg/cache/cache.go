@@ -0,0 +1,130 @@
+// Package cache keeps the results of repository reads for a while so
+// repeated reads of the same record skip the database. Values are stored as
+// JSON in a Backend, an in-process LRU by default.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"repository_class/pkg/logger"
+	"repository_class/pkg/metrics"
+)
+
+// Backend stores encoded values by key. Implementations must be safe for
+// concurrent use; a shared store such as Redis can stand in for the LRU.
+type Backend interface {
+	// Get returns the value stored under key, and false when there is none
+	// or it has expired.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value under key for ttl.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes keys, ignoring the ones that are not stored.
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// Stats counts the lookups of a Cache since it was created.
+type Stats struct {
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+}
+
+// Cache encodes values into a Backend and counts hits and misses. Backend
+// errors are logged and treated as misses, so a failing backend only makes
+// reads slower.
+type Cache struct {
+	name    string
+	backend Backend
+	ttl     time.Duration
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// New returns a Cache keeping values in backend for ttl. name labels its
+// metrics.
+func New(name string, backend Backend, ttl time.Duration) *Cache {
+	return &Cache{name: name, backend: backend, ttl: ttl}
+}
+
+type ctxKey struct{}
+
+// Bypass returns a copy of ctx whose reads skip the cache. The values read
+// are still stored, so a bypassing read also refreshes the cache. It is
+// meant for reads a write is based on, and for clients sending
+// Cache-Control: no-cache.
+func Bypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ctxKey{}, true)
+}
+
+func bypassed(ctx context.Context) bool {
+	b, _ := ctx.Value(ctxKey{}).(bool)
+	return b
+}
+
+// Get decodes the value stored under key into dst and reports whether there
+// was one.
+func (c *Cache) Get(ctx context.Context, key string, dst interface{}) bool {
+	if bypassed(ctx) {
+		c.count("bypass")
+		return false
+	}
+	b, ok, err := c.backend.Get(ctx, key)
+	if err == nil && ok {
+		err = json.Unmarshal(b, dst)
+	}
+	if err != nil {
+		logger.FromContext(ctx).Warn("cache get", "cache", c.name, "key", key, "error", err)
+		ok = false
+	}
+	if !ok {
+		c.misses.Add(1)
+		c.count("miss")
+		return false
+	}
+	c.hits.Add(1)
+	c.count("hit")
+	return true
+}
+
+// Set stores value under key for the ttl of the cache.
+func (c *Cache) Set(ctx context.Context, key string, value interface{}) {
+	b, err := json.Marshal(value)
+	if err == nil {
+		err = c.backend.Set(ctx, key, b, c.ttl)
+	}
+	if err != nil {
+		logger.FromContext(ctx).Warn("cache set", "cache", c.name, "key", key, "error", err)
+	}
+}
+
+// Delete drops keys, so the next read of each goes to the database.
+func (c *Cache) Delete(ctx context.Context, keys ...string) {
+	if err := c.backend.Delete(ctx, keys...); err != nil {
+		logger.FromContext(ctx).Warn("cache delete", "cache", c.name, "keys", keys, "error", err)
+	}
+}
+
+// Stats returns the hits and misses counted so far. Bypassed reads are
+// neither.
+func (c *Cache) Stats() Stats {
+	return Stats{Hits: c.hits.Load(), Misses: c.misses.Load()}
+}
+
+func (c *Cache) count(result string) {
+	metrics.CacheRequests.WithLabelValues(c.name, result).Inc()
+}
+
+// Key joins parts with colons, as in Key("product", "acme", 7) =
+// "product:acme:7".
+func Key(parts ...interface{}) string {
+	s := make([]string, len(parts))
+	for i, p := range parts {
+		s[i] = fmt.Sprint(p)
+	}
+	return strings.Join(s, ":")
+}
@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// LRU is an in-process Backend holding at most size entries. Once full it
+// evicts the least recently used one; expired entries are dropped when
+// they are read.
+type LRU struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+	now     func() time.Time
+}
+
+type lruEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewLRU returns an empty LRU holding at most size entries.
+func NewLRU(size int) *LRU {
+	if size < 1 {
+		size = 1
+	}
+	return &LRU{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+		now:     time.Now,
+	}
+}
+
+func (l *LRU) Get(_ context.Context, key string) ([]byte, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	el, ok := l.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	e := el.Value.(*lruEntry)
+	if !l.now().Before(e.expires) {
+		l.remove(el)
+		return nil, false, nil
+	}
+	l.order.MoveToFront(el)
+	return e.value, true, nil
+}
+
+func (l *LRU) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	expires := l.now().Add(ttl)
+	if el, ok := l.entries[key]; ok {
+		e := el.Value.(*lruEntry)
+		e.value, e.expires = value, expires
+		l.order.MoveToFront(el)
+		return nil
+	}
+	l.entries[key] = l.order.PushFront(&lruEntry{key: key, value: value, expires: expires})
+	if l.order.Len() > l.size {
+		l.remove(l.order.Back())
+	}
+	return nil
+}
+
+func (l *LRU) Delete(_ context.Context, keys ...string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, key := range keys {
+		if el, ok := l.entries[key]; ok {
+			l.remove(el)
+		}
+	}
+	return nil
+}
+
+// Len returns the number of entries held, expired ones included.
+func (l *LRU) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.order.Len()
+}
+
+func (l *LRU) remove(el *list.Element) {
+	l.order.Remove(el)
+	delete(l.entries, el.Value.(*lruEntry).key)
+}
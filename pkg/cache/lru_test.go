@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	l := NewLRU(2)
+
+	_ = l.Set(ctx, "a", []byte("1"), time.Minute)
+	_ = l.Set(ctx, "b", []byte("2"), time.Minute)
+	_, _, _ = l.Get(ctx, "a")
+	_ = l.Set(ctx, "c", []byte("3"), time.Minute)
+
+	_, ok, _ := l.Get(ctx, "b")
+	assert.False(t, ok)
+	v, ok, _ := l.Get(ctx, "a")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("1"), v)
+	assert.Equal(t, 2, l.Len())
+}
+
+func TestLRUExpires(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	l := NewLRU(10)
+	l.now = func() time.Time { return now }
+
+	_ = l.Set(ctx, "a", []byte("1"), time.Second)
+	_, ok, _ := l.Get(ctx, "a")
+	assert.True(t, ok)
+
+	now = now.Add(time.Second)
+	_, ok, _ = l.Get(ctx, "a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, l.Len())
+}
+
+func TestCacheStats(t *testing.T) {
+	ctx := context.Background()
+	c := New("test", NewLRU(10), time.Minute)
+
+	var v int
+	assert.False(t, c.Get(ctx, Key("n", 1), &v))
+	c.Set(ctx, Key("n", 1), 42)
+	assert.True(t, c.Get(ctx, Key("n", 1), &v))
+	assert.Equal(t, 42, v)
+	assert.False(t, c.Get(Bypass(ctx), Key("n", 1), &v))
+
+	c.Delete(ctx, Key("n", 1))
+	assert.False(t, c.Get(ctx, Key("n", 1), &v))
+
+	assert.Equal(t, Stats{Hits: 1, Misses: 2}, c.Stats())
+}
@@ -0,0 +1,80 @@
+package instrument
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"repository_class/pkg/logger"
+	"repository_class/pkg/metrics"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("repository_class/pkg/instrument")
+
+var slowQueryThreshold atomic.Int64
+
+func init() {
+	SetSlowQueryThreshold(200 * time.Millisecond)
+}
+
+// SetSlowQueryThreshold sets the duration above which a query is logged as slow.
+func SetSlowQueryThreshold(d time.Duration) {
+	slowQueryThreshold.Store(int64(d))
+}
+
+// Query starts observing a repository call. It opens a span carrying the SQL
+// statement; the returned context must be used for the query. The returned
+// function must be called with the error of the call once it finishes; it
+// ends the span, records the duration and logs it, at warn level when the
+// query is slower than the threshold. sql.ErrNoRows is not treated as a
+// failure.
+func Query(ctx context.Context, repo, method, query string) (context.Context, func(error)) {
+	start := time.Now()
+	ctx, span := tracer.Start(ctx, repo+".Repository."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemMySQL,
+			semconv.DBQueryText(query),
+			semconv.DBOperationName(method),
+		),
+	)
+
+	return ctx, func(err error) {
+		defer span.End()
+
+		elapsed := time.Since(start)
+		failed := err != nil && !errors.Is(err, sql.ErrNoRows)
+
+		outcome := "ok"
+		if failed {
+			outcome = "error"
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		metrics.QueryDuration.WithLabelValues(repo, method, outcome).Observe(elapsed.Seconds())
+
+		attrs := []slog.Attr{
+			slog.String("repository", repo),
+			slog.String("method", method),
+			slog.Duration("duration", elapsed),
+		}
+
+		l := logger.FromContext(ctx)
+		switch {
+		case failed:
+			l.LogAttrs(ctx, slog.LevelError, "query failed", append(attrs, slog.String("query", query), slog.String("error", err.Error()))...)
+		case elapsed > time.Duration(slowQueryThreshold.Load()):
+			l.LogAttrs(ctx, slog.LevelWarn, "slow query", append(attrs, slog.String("query", query))...)
+		default:
+			l.LogAttrs(ctx, slog.LevelDebug, "query", attrs...)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "inventory"
+
+// Registry holds every collector exposed on /metrics.
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequests counts served requests by method, route and status.
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Number of HTTP requests served.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPDuration observes request latency by method, route and status.
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of HTTP requests.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// QueryDuration observes repository calls by repository, method and outcome.
+	QueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "repository",
+		Name:      "query_duration_seconds",
+		Help:      "Duration of repository queries.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"repository", "method", "outcome"})
+
+	// CacheRequests counts cache lookups by cache and result: hit, miss or
+	// bypass.
+	CacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "requests_total",
+		Help:      "Number of cache lookups.",
+	}, []string{"cache", "result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPDuration,
+		QueryDuration,
+		CacheRequests,
+	)
+}
+
+// Handler serves the metrics in Registry in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
@@ -0,0 +1,166 @@
+// Package ratelimit meters requests with token buckets: a bucket holds up to
+// Limit.Requests tokens, refills at Limit.Requests per Limit.Per, and every
+// request takes one.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Errors
+var (
+	ErrInvalidLimit = errors.New("rate limit must look like 100/1m")
+)
+
+// Limit lets Requests requests through per Per, all at once at most.
+// A zero Limit lets every request through.
+type Limit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// ParseLimit reads a limit written as requests/period, such as 100/1m. "0"
+// and "off" disable limiting.
+func ParseLimit(s string) (Limit, error) {
+	s = strings.TrimSpace(s)
+	if s == "0" || s == "off" {
+		return Limit{}, nil
+	}
+	requests, per, ok := strings.Cut(s, "/")
+	if !ok {
+		return Limit{}, ErrInvalidLimit
+	}
+	n, err := strconv.Atoi(requests)
+	if err != nil || n < 1 {
+		return Limit{}, ErrInvalidLimit
+	}
+	d, err := time.ParseDuration(per)
+	if err != nil || d <= 0 {
+		return Limit{}, ErrInvalidLimit
+	}
+	return Limit{Requests: n, Per: d}, nil
+}
+
+// Unlimited reports whether l lets every request through.
+func (l Limit) Unlimited() bool {
+	return l.Requests <= 0 || l.Per <= 0
+}
+
+func (l Limit) String() string {
+	if l.Unlimited() {
+		return "off"
+	}
+	return fmt.Sprintf("%d/%s", l.Requests, l.Per)
+}
+
+// rate is the number of tokens added per second.
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Per.Seconds()
+}
+
+// Result is the state of a bucket after a request took from it.
+type Result struct {
+	Allowed bool
+	// Remaining is the number of whole tokens left.
+	Remaining int
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is how long until a denied request would be let through.
+	RetryAfter time.Duration
+}
+
+// Store keeps the buckets. The in-memory store only meters the requests of
+// one instance; a store shared between instances, such as Redis, meters
+// them all.
+type Store interface {
+	// Take takes a token from the bucket under key, refilled at l, and
+	// reports whether there was one.
+	Take(ctx context.Context, key string, l Limit) (Result, error)
+}
+
+// sweepEvery is how often the memory store drops buckets that refilled.
+const sweepEvery = time.Minute
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	limit  Limit
+}
+
+// fill adds the tokens earned since the last request, up to the capacity
+// of the bucket.
+func (b *bucket) fill(now time.Time) {
+	b.tokens = math.Min(float64(b.limit.Requests), b.tokens+now.Sub(b.last).Seconds()*b.limit.rate())
+	b.last = now
+}
+
+// MemoryStore keeps the buckets in process.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	swept   time.Time
+	now     func() time.Time
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, l Limit) (Result, error) {
+	if l.Unlimited() {
+		return Result{Allowed: true}, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok || b.limit != l {
+		b = &bucket{tokens: float64(l.Requests), last: now, limit: l}
+		s.buckets[key] = b
+	}
+	b.fill(now)
+
+	res := Result{Allowed: b.tokens >= 1}
+	if res.Allowed {
+		b.tokens--
+	} else {
+		res.RetryAfter = seconds((1 - b.tokens) / l.rate())
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = seconds((float64(l.Requests) - b.tokens) / l.rate())
+	return res, nil
+}
+
+// sweep drops the buckets that are full again, since a new bucket starts
+// full too, so idle clients do not hold memory.
+func (s *MemoryStore) sweep(now time.Time) {
+	if s.swept.IsZero() {
+		s.swept = now
+	}
+	if now.Sub(s.swept) < sweepEvery {
+		return
+	}
+	s.swept = now
+	for key, b := range s.buckets {
+		b.fill(now)
+		if b.tokens >= float64(b.limit.Requests) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,67 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLimit(t *testing.T) {
+	l, err := ParseLimit("100/1m")
+	assert.NoError(t, err)
+	assert.Equal(t, Limit{Requests: 100, Per: time.Minute}, l)
+
+	l, err = ParseLimit("off")
+	assert.NoError(t, err)
+	assert.True(t, l.Unlimited())
+
+	for _, s := range []string{"", "100", "0/1m", "100/x", "100/-1s"} {
+		_, err = ParseLimit(s)
+		assert.ErrorIs(t, err, ErrInvalidLimit, s)
+	}
+}
+
+func TestMemoryStoreTake(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+	l := Limit{Requests: 2, Per: time.Second}
+
+	res, _ := s.Take(ctx, "a", l)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 1, res.Remaining)
+	res, _ = s.Take(ctx, "a", l)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+	assert.Equal(t, time.Second, res.Reset)
+
+	res, _ = s.Take(ctx, "a", l)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 500*time.Millisecond, res.RetryAfter)
+
+	// Other keys have their own bucket.
+	res, _ = s.Take(ctx, "b", l)
+	assert.True(t, res.Allowed)
+
+	now = now.Add(500 * time.Millisecond)
+	res, _ = s.Take(ctx, "a", l)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+}
+
+func TestMemoryStoreSweepsFullBuckets(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+	l := Limit{Requests: 2, Per: time.Second}
+
+	_, _ = s.Take(ctx, "a", l)
+	now = now.Add(sweepEvery)
+	_, _ = s.Take(ctx, "b", l)
+
+	assert.Len(t, s.buckets, 1)
+}
@@ -0,0 +1,55 @@
+// Package tenant carries the business unit a request acts for. Products and
+// warehouses are stored per tenant and their repositories only see the rows
+// of the tenant in the context.
+package tenant
+
+import (
+	"context"
+	"errors"
+)
+
+// Errors
+var (
+	ErrMissing = errors.New("tenant is required")
+	ErrInvalid = errors.New("tenant must be 1 to 64 letters, digits, '-' or '_'")
+)
+
+const maxLength = 64
+
+type ctxKey struct{}
+
+// WithContext returns a copy of ctx acting for tenant id.
+func WithContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// FromContext returns the tenant stored in ctx.
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(ctxKey{}).(string)
+	return id, ok && id != ""
+}
+
+// Require returns the tenant stored in ctx, or ErrMissing when there is
+// none, so a query is never run unscoped.
+func Require(ctx context.Context) (string, error) {
+	id, ok := FromContext(ctx)
+	if !ok {
+		return "", ErrMissing
+	}
+	return id, nil
+}
+
+// Valid reports whether id can name a tenant.
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
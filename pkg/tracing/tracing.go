@@ -0,0 +1,77 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Exporters accepted by Setup.
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Config selects where traces are sent.
+type Config struct {
+	ServiceName string
+	Exporter    string
+	// OTLPEndpoint is the host:port of the OTLP gRPC collector.
+	OTLPEndpoint string
+}
+
+// Setup installs the global tracer provider and propagator. The returned
+// function flushes pending spans and must be called on shutdown. With
+// ExporterNone spans are still created, so context propagates, but nothing
+// is exported.
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	// The attributes are left schemaless: merging them with the SDK's own
+	// resource fails whenever its semconv version differs from ours.
+	res, err := resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(semconv.ServiceName(cfg.ServiceName)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+
+	switch cfg.Exporter {
+	case ExporterNone, "":
+	case ExporterStdout:
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, sdktrace.WithBatcher(exp))
+	case ExporterOTLP:
+		exp, err := otlptracegrpc.New(ctx,
+			otlptracegrpc.WithEndpoint(cfg.OTLPEndpoint),
+			otlptracegrpc.WithInsecure(),
+		)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, sdktrace.WithBatcher(exp))
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", cfg.Exporter)
+	}
+
+	tp := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(tp)
+
+	return tp.Shutdown, nil
+}
@@ -0,0 +1,23 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+)
+
+func TestSetup(t *testing.T) {
+	ctx := context.Background()
+
+	shutdown, err := Setup(ctx, Config{ServiceName: "inventory", Exporter: ExporterNone})
+	assert.NoError(t, err)
+	_, span := otel.Tracer("test").Start(ctx, "span")
+	assert.True(t, span.SpanContext().IsValid())
+	span.End()
+	assert.NoError(t, shutdown(ctx))
+
+	_, err = Setup(ctx, Config{ServiceName: "inventory", Exporter: "zipkin"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,136 @@
+// Package txn lets the writes of several repository calls share one database
+// transaction, carried in the context. Repositories run their statements
+// through From and begin their transactions with Begin, so they join the
+// transaction of a Run instead of starting their own.
+package txn
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// errDuplicateKey is the MySQL error number of a write that breaks a unique
+// key.
+const errDuplicateKey = 1062
+
+// errRowReferenced is the MySQL error number of a delete that a foreign key
+// of another row still points at.
+const errRowReferenced = 1451
+
+// Executor runs statements. *sql.DB and *sql.Tx are both one.
+type Executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Tx is a transaction returned by Begin.
+type Tx interface {
+	Executor
+	Commit() error
+	Rollback() error
+}
+
+type ctxKey struct{}
+
+// state is the transaction a Run carries in its context.
+type state struct {
+	tx    *sql.Tx
+	after []func()
+}
+
+func fromContext(ctx context.Context) (*state, bool) {
+	st, ok := ctx.Value(ctxKey{}).(*state)
+	return st, ok
+}
+
+// Run begins a transaction on db and calls fn with a context carrying it. The
+// transaction is committed when fn returns nil and rolled back otherwise.
+// Within the transaction of another Run, fn joins it and its outcome is left
+// to that Run.
+func Run(ctx context.Context, db *sql.DB, fn func(ctx context.Context) error) error {
+	if Active(ctx) {
+		return fn(ctx)
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	st := &state{tx: tx}
+	if err := fn(context.WithValue(ctx, ctxKey{}, st)); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	for _, f := range st.after {
+		f()
+	}
+	return nil
+}
+
+// AfterCommit calls f once the transaction ctx carries commits, and never
+// when it rolls back. Without a transaction f is called right away.
+func AfterCommit(ctx context.Context, f func()) {
+	if st, ok := fromContext(ctx); ok {
+		st.after = append(st.after, f)
+		return
+	}
+	f()
+}
+
+// Begin begins a transaction on db, or joins the one ctx carries. Ending a
+// joined transaction is left to the Run that began it, so its Commit and
+// Rollback do nothing.
+func Begin(ctx context.Context, db *sql.DB) (Tx, error) {
+	if st, ok := fromContext(ctx); ok {
+		return joined{st.tx}, nil
+	}
+	return db.BeginTx(ctx, nil)
+}
+
+// From returns the transaction ctx carries, or db when there is none.
+func From(ctx context.Context, db *sql.DB) Executor {
+	if st, ok := fromContext(ctx); ok {
+		return st.tx
+	}
+	return db
+}
+
+// Active reports whether ctx carries a transaction.
+func Active(ctx context.Context) bool {
+	_, ok := fromContext(ctx)
+	return ok
+}
+
+type joined struct {
+	*sql.Tx
+}
+
+func (joined) Commit() error {
+	return nil
+}
+
+func (joined) Rollback() error {
+	return nil
+}
+
+// IsDuplicate reports whether err is a write rejected by a unique key, such
+// as one that lost a race with a concurrent transaction writing the same
+// key.
+func IsDuplicate(err error) bool {
+	var me *mysql.MySQLError
+	return errors.As(err, &me) && me.Number == errDuplicateKey
+}
+
+// IsReferenced reports whether err is a delete rejected by a foreign key,
+// such as one of a row still used by rows the caller cannot see.
+func IsReferenced(err error) bool {
+	var me *mysql.MySQLError
+	return errors.As(err, &me) && me.Number == errRowReferenced
+}
@@ -13,8 +13,15 @@ type response struct {
 }
 
 type errorResponse struct {
-	Status  int    `json:"-"`
-	Code    string `json:"code"`
+	Status  int           `json:"-"`
+	Code    string        `json:"code"`
+	Message string        `json:"message"`
+	Details []ErrorDetail `json:"details,omitempty"`
+}
+
+// ErrorDetail points at one invalid part of a request.
+type ErrorDetail struct {
+	Field   string `json:"field,omitempty"`
 	Message string `json:"message"`
 }
 
@@ -23,16 +30,37 @@ func Response(c *gin.Context, status int, data interface{}) {
 }
 
 func Success(c *gin.Context, status int, data interface{}) {
-	Response(c, status, response{Data: data})
+	Response(c, status, SuccessBody(data))
 }
 
 // NewErrorf creates a new error with the given status code and the message
 // formatted according to args and format.
 func Error(c *gin.Context, status int, format string, args ...interface{}) {
+	Response(c, status, ErrorBody(status, format, args...))
+}
+
+// SuccessBody and ErrorBody return the bodies Success and Error send, for
+// responses nested in another one, such as the results of a batch.
+func SuccessBody(data interface{}) interface{} {
+	return response{Data: data}
+}
+
+func ErrorBody(status int, format string, args ...interface{}) interface{} {
+	return errorResponse{
+		Code:    strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_"),
+		Message: fmt.Sprintf(format, args...),
+		Status:  status,
+	}
+}
+
+// ErrorWithDetails is like Error and also lists the individual problems,
+// such as the fields that failed validation.
+func ErrorWithDetails(c *gin.Context, status int, details []ErrorDetail, format string, args ...interface{}) {
 	err := errorResponse{
 		Code:    strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_"),
 		Message: fmt.Sprintf(format, args...),
 		Status:  status,
+		Details: details,
 	}
 
 	Response(c, status, err)
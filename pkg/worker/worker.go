@@ -0,0 +1,65 @@
+// Package worker runs background jobs alongside the servers and stops them
+// with the rest of the process.
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"repository_class/pkg/logger"
+)
+
+// ErrInvalidInterval is returned by Every for an interval that is not
+// positive, which would make the ticker panic.
+var ErrInvalidInterval = errors.New("worker interval must be positive")
+
+// Job is one run of a periodic job. An error is logged and the job runs
+// again on the next tick.
+type Job func(ctx context.Context) error
+
+// Group runs periodic jobs until the context given to Every is cancelled.
+// The zero value is ready to use.
+type Group struct {
+	wg sync.WaitGroup
+}
+
+// Every starts job in its own goroutine and runs it once per interval until
+// ctx is done. Runs never overlap: a run that takes longer than interval
+// delays the next one. A job is not started with a non-positive interval.
+func (g *Group) Every(ctx context.Context, name string, interval time.Duration, job Job) error {
+	if interval <= 0 {
+		return fmt.Errorf("%s: %w, got %s", name, ErrInvalidInterval, interval)
+	}
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+
+		log := logger.FromContext(ctx).With("worker", name)
+		log.Info("worker started", "interval", interval)
+		defer log.Info("worker stopped")
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := job(logger.WithContext(ctx, log)); err != nil && ctx.Err() == nil {
+					log.Error("worker run failed", "error", err)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// Wait blocks until every job started with Every has returned.
+func (g *Group) Wait() {
+	g.wg.Wait()
+}
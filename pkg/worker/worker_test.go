@@ -0,0 +1,38 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEveryRunsUntilCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var runs atomic.Int32
+	var g Group
+	err := g.Every(ctx, "test", time.Millisecond, func(ctx context.Context) error {
+		if runs.Add(1) == 3 {
+			cancel()
+		}
+		return errors.New("keeps running after errors")
+	})
+	assert.NoError(t, err)
+	g.Wait()
+
+	assert.GreaterOrEqual(t, runs.Load(), int32(3))
+}
+
+func TestEveryRejectsNonPositiveInterval(t *testing.T) {
+	var g Group
+	for _, interval := range []time.Duration{0, -time.Second} {
+		err := g.Every(context.Background(), "test", interval, func(context.Context) error { return nil })
+		assert.ErrorIs(t, err, ErrInvalidInterval)
+	}
+	// Nothing was started, so there is nothing to wait for.
+	g.Wait()
+}